@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"math"
 	"strings"
 	"time"
 
 	"github.com/andrejsstepanovs/andai/internal/exec"
+	"github.com/andrejsstepanovs/andai/internal/monitor"
+	"github.com/andrejsstepanovs/andai/internal/redact"
 	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/andrejsstepanovs/andai/internal/truncate"
 	"github.com/teilomillet/gollm"
 	"github.com/teilomillet/gollm/config"
 	"github.com/teilomillet/gollm/llm"
@@ -80,6 +82,7 @@ func NewAI(config settings.LlmModel) (*AI, error) {
 				model,
 				&temp,
 				extraHeaders,
+				config.Vision,
 			)
 		})
 		conn, err = llm.NewLLM(cfg, utils.NewLogger(cfg.LogLevel), registry)
@@ -87,6 +90,9 @@ func NewAI(config settings.LlmModel) (*AI, error) {
 			return nil, fmt.Errorf("failed to create custom %q LLM err: %v", config.Provider, err)
 		}
 	} else {
+		if config.Vision {
+			log.Printf("Images are not supported for %q provider, model %q will get image names only", config.Provider, config.Model)
+		}
 		//log.Printf("Using provider %q", config.Provider)
 		opts := []gollm.ConfigOption{
 			gollm.SetProvider(cfg.Provider),
@@ -116,6 +122,7 @@ func NewAI(config settings.LlmModel) (*AI, error) {
 		provider: config.Provider,
 		model:    config.Model,
 		config:   cfg,
+		vision:   config.Vision && ok,
 	}, nil
 }
 
@@ -124,6 +131,7 @@ type AI struct {
 	provider string
 	model    string
 	config   *config.Config
+	vision   bool
 }
 
 func (a *AI) Multi(question string, prompts []map[string]string) (exec.Output, error) {
@@ -144,7 +152,8 @@ func (a *AI) Multi(question string, prompts []map[string]string) (exec.Output, e
 }
 
 func (a *AI) Simple(prompt string) (exec.Output, error) {
-	resp, err := a.client.Generate(context.Background(), &llm.Prompt{Input: prompt})
+	monitor.Default.CountLLMCall()
+	resp, err := a.client.Generate(context.Background(), a.outgoing(&llm.Prompt{Input: prompt}))
 	if err != nil {
 		return exec.Output{}, err
 	}
@@ -160,7 +169,8 @@ func (a *AI) Generate(ctx context.Context, prompt *llm.Prompt, opts ...llm.Gener
 		return exec.Output{}, ErrTooManyTokens
 	}
 
-	resp, err := a.client.Generate(ctx, prompt, opts...)
+	monitor.Default.CountLLMCall()
+	resp, err := a.client.Generate(ctx, a.outgoing(prompt), opts...)
 	if err != nil {
 		return exec.Output{}, err
 	}
@@ -199,12 +209,40 @@ func (a *AI) GenerateJSON(ctx context.Context, prompt *llm.Prompt, element any)
 }
 
 func (a *AI) estimateTokens(text string) int {
-	// Character-based estimation
-	charEstimate := int(math.Ceil(float64(len(text)) / 4))
+	return truncate.EstimateTokens(text)
+}
 
-	// Word-based estimation
-	words := strings.Fields(text)
-	wordEstimate := int(math.Ceil(float64(len(words)) * 1.33))
+// outgoing returns copy of prompt with secrets removed from all text parts.
+// Image tags are replaced with notes if model has no vision.
+func (a *AI) outgoing(prompt *llm.Prompt) *llm.Prompt {
+	clean := func(text string) string {
+		text = redact.Text(text)
+		if !a.vision {
+			text = withoutImages(text)
+		}
+		return text
+	}
+	cleanAll := func(texts []string) []string {
+		if texts == nil {
+			return nil
+		}
+		cleaned := make([]string, len(texts))
+		for n, text := range texts {
+			cleaned[n] = clean(text)
+		}
+		return cleaned
+	}
 
-	return max(charEstimate, wordEstimate)
+	out := *prompt
+	out.Input = clean(prompt.Input)
+	out.Context = clean(prompt.Context)
+	out.SystemPrompt = clean(prompt.SystemPrompt)
+	out.Directives = cleanAll(prompt.Directives)
+	out.Examples = cleanAll(prompt.Examples)
+	out.Messages = make([]llm.PromptMessage, len(prompt.Messages))
+	for n, message := range prompt.Messages {
+		message.Content = clean(message.Content)
+		out.Messages[n] = message
+	}
+	return &out
 }
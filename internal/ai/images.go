@@ -0,0 +1,75 @@
+package ai
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// imageRe matches image tags that are sent as image parts to vision models.
+var imageRe = regexp.MustCompile(`<image name="([^"]*)" path="([^"]*)"\s*/>`)
+
+// ImageTag returns placeholder for local image file. Vision models get the image itself,
+// other models get only a note with image name.
+func ImageTag(name, path string) string {
+	return fmt.Sprintf(`<image name="%s" path="%s" />`, strings.ReplaceAll(name, `"`, "'"), path)
+}
+
+// withoutImages replaces image tags with text note.
+func withoutImages(text string) string {
+	return imageRe.ReplaceAllString(text, "[image $1 is not shown, model has no vision]")
+}
+
+// contentParts splits prompt into OpenAI message content parts with images inlined as data URLs.
+// Returns false if prompt has no images.
+func contentParts(prompt string) ([]map[string]interface{}, bool) {
+	matches := imageRe.FindAllStringSubmatchIndex(prompt, -1)
+	if len(matches) == 0 {
+		return nil, false
+	}
+
+	parts := make([]map[string]interface{}, 0, len(matches)*2+1)
+	addText := func(text string) {
+		if strings.TrimSpace(text) != "" {
+			parts = append(parts, map[string]interface{}{"type": "text", "text": text})
+		}
+	}
+
+	last := 0
+	for _, match := range matches {
+		addText(prompt[last:match[0]])
+		last = match[1]
+
+		name, path := prompt[match[2]:match[3]], prompt[match[4]:match[5]]
+		dataURL, err := imageDataURL(path)
+		if err != nil {
+			log.Printf("Failed to attach image %q: %v", name, err)
+			addText(fmt.Sprintf("[image %s could not be attached]", name))
+			continue
+		}
+		parts = append(parts,
+			map[string]interface{}{"type": "text", "text": fmt.Sprintf("Image %s:", name)},
+			map[string]interface{}{"type": "image_url", "image_url": map[string]interface{}{"url": dataURL}},
+		)
+	}
+	addText(prompt[last:])
+	return parts, true
+}
+
+func imageDataURL(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	mimeType := mime.TypeByExtension(strings.ToLower(filepath.Ext(path)))
+	if !strings.HasPrefix(mimeType, "image/") {
+		mimeType = http.DetectContentType(content)
+	}
+	return fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(content)), nil
+}
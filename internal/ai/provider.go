@@ -19,9 +19,10 @@ type CustomOpenAIProvider struct {
 	extraHeaders map[string]string
 	options      map[string]interface{}
 	logger       utils.Logger
+	vision       bool // send image tags as image parts
 }
 
-func NewCustomOpenAIProvider(name, endpoint, apiKey, model string, temperature *float64, extraHeaders map[string]string) providers.Provider {
+func NewCustomOpenAIProvider(name, endpoint, apiKey, model string, temperature *float64, extraHeaders map[string]string, vision bool) providers.Provider {
 	if extraHeaders == nil {
 		extraHeaders = make(map[string]string)
 	}
@@ -34,6 +35,7 @@ func NewCustomOpenAIProvider(name, endpoint, apiKey, model string, temperature *
 		options:      make(map[string]interface{}),
 		logger:       utils.NewLogger(utils.LogLevelInfo),
 		temperature:  temperature,
+		vision:       vision,
 	}
 }
 
@@ -89,7 +91,7 @@ func (p *CustomOpenAIProvider) PrepareRequest(prompt string, options map[string]
 		"messages": []map[string]interface{}{
 			{
 				"role":    "user",
-				"content": prompt,
+				"content": p.messageContent(prompt),
 			},
 		},
 	}
@@ -131,6 +133,17 @@ func (p *CustomOpenAIProvider) PrepareRequest(prompt string, options map[string]
 	return json.Marshal(request)
 }
 
+// messageContent returns prompt as content parts with images if model has vision, otherwise plain text.
+func (p *CustomOpenAIProvider) messageContent(prompt string) interface{} {
+	if !p.vision {
+		return prompt
+	}
+	if parts, ok := contentParts(prompt); ok {
+		return parts
+	}
+	return prompt
+}
+
 // nolint: unused
 func (p *CustomOpenAIProvider) createBaseRequest(prompt string) map[string]interface{} {
 	var request map[string]interface{}
@@ -233,8 +246,8 @@ func (p *CustomOpenAIProvider) PrepareRequestWithSchema(prompt string, options m
 	p.logger.Debug("Preparing request with schema", "prompt", prompt, "schema", schema)
 	request := map[string]interface{}{
 		"model": p.model,
-		"messages": []map[string]string{
-			{"role": "user", "content": prompt},
+		"messages": []map[string]interface{}{
+			{"role": "user", "content": p.messageContent(prompt)},
 		},
 		"response_format": map[string]interface{}{
 			"type":   "json_schema",
@@ -0,0 +1,129 @@
+// Package attempts keeps structured record of every work session on issue,
+// so next session in same state knows what was tried before and why it was rejected.
+package attempts
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	VerdictSuccess  = "success"
+	VerdictNegative = "negative"
+	VerdictConflict = "conflict"
+	VerdictFailed   = "failed"
+	VerdictSkipped  = "skipped"
+
+	// maxAttempts is how many latest attempts are kept per issue.
+	maxAttempts = 20
+	// maxEncodedBytes keeps encoded history within redmine custom value column (mysql TEXT).
+	maxEncodedBytes = 60000
+)
+
+// Step is single workflow step executed in attempt.
+type Step struct {
+	Command string `json:"command"`
+	Action  string `json:"action,omitempty"`
+	Outcome string `json:"outcome"` // ok, negative, conflict or error message
+}
+
+// Attempt is one work session on issue in one state.
+type Attempt struct {
+	State       string    `json:"state"`
+	Started     time.Time `json:"started"`
+	Finished    time.Time `json:"finished"`
+	Steps       []Step    `json:"steps"`
+	Commits     []string  `json:"commits,omitempty"`   // "<short sha> <subject>", oldest first
+	Verdict     string    `json:"verdict"`             // one of Verdict* values
+	Reasons     string    `json:"reasons,omitempty"`   // evaluate, review or error output that explains verdict
+	Rejection   string    `json:"rejection,omitempty"` // human comments written after attempt finished
+	LastComment int       `json:"last_comment"`        // number of issue comments when attempt finished
+}
+
+// AddStep records executed step with its outcome.
+func (a *Attempt) AddStep(command, action, outcome string) {
+	a.Steps = append(a.Steps, Step{Command: command, Action: action, Outcome: outcome})
+}
+
+// Failed tells if attempt did not move issue forward.
+func (a Attempt) Failed() bool {
+	return a.Verdict != VerdictSuccess
+}
+
+// History is all recorded attempts of issue. Oldest first.
+type History struct {
+	IssueID  int       `json:"issue_id"`
+	Attempts []Attempt `json:"attempts"`
+}
+
+// ForState returns attempts made while issue was in given state. Oldest first.
+func (h History) ForState(state string) []Attempt {
+	found := make([]Attempt, 0)
+	for _, attempt := range h.Attempts {
+		if attempt.State == state {
+			found = append(found, attempt)
+		}
+	}
+	return found
+}
+
+// Last returns latest attempt or nil.
+func (h *History) Last() *Attempt {
+	if len(h.Attempts) == 0 {
+		return nil
+	}
+	return &h.Attempts[len(h.Attempts)-1]
+}
+
+// Add appends attempt and drops oldest ones over the limit.
+func (h *History) Add(attempt Attempt) {
+	h.Attempts = append(h.Attempts, attempt)
+	if len(h.Attempts) > maxAttempts {
+		h.Attempts = h.Attempts[len(h.Attempts)-maxAttempts:]
+	}
+}
+
+// Encode returns history as JSON that fits into maxEncodedBytes.
+// Oldest attempts are dropped first, then reasons and rejection of last one are cut.
+func Encode(history History) (string, error) {
+	for {
+		data, err := json.Marshal(history)
+		if err != nil {
+			return "", err
+		}
+		if len(data) <= maxEncodedBytes {
+			return string(data), nil
+		}
+		if len(history.Attempts) > 1 {
+			history.Attempts = history.Attempts[1:]
+			continue
+		}
+		last := history.Last()
+		if last == nil || (last.Reasons == "" && last.Rejection == "") {
+			return "", fmt.Errorf("attempts of issue %d do not fit into %d bytes", history.IssueID, maxEncodedBytes)
+		}
+		last.Reasons = cut(last.Reasons, len(last.Reasons)/2)
+		last.Rejection = cut(last.Rejection, len(last.Rejection)/2)
+	}
+}
+
+// Decode parses history stored by Encode. Empty history if nothing was recorded yet.
+func Decode(issueID int, value string) (History, error) {
+	history := History{IssueID: issueID}
+	if value == "" {
+		return history, nil
+	}
+	if err := json.Unmarshal([]byte(value), &history); err != nil {
+		return History{IssueID: issueID}, fmt.Errorf("failed to parse attempts of issue %d err: %w", issueID, err)
+	}
+	return history, nil
+}
+
+func cut(text string, size int) string {
+	if len(text) <= size {
+		return text
+	}
+	return strings.ToValidUTF8(text[:size], "")
+}
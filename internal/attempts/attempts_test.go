@@ -0,0 +1,128 @@
+package attempts_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/andrejsstepanovs/andai/internal/attempts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode_Empty(t *testing.T) {
+	history, err := attempts.Decode(7, "")
+	require.NoError(t, err)
+	assert.Equal(t, 7, history.IssueID)
+	assert.Empty(t, history.Attempts)
+	assert.Nil(t, history.Last())
+}
+
+func TestDecode_Broken(t *testing.T) {
+	history, err := attempts.Decode(3, "{")
+	assert.Error(t, err)
+	assert.Equal(t, 3, history.IssueID)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	started := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	attempt := attempts.Attempt{
+		State:       "In Progress",
+		Started:     started,
+		Finished:    started.Add(time.Minute),
+		Commits:     []string{"abc1234 Add parser"},
+		Verdict:     attempts.VerdictNegative,
+		Reasons:     "Tests are missing",
+		LastComment: 4,
+	}
+	attempt.AddStep("aider", "code", "ok")
+	attempt.AddStep("evaluate", "", "negative")
+
+	history := attempts.History{IssueID: 5}
+	history.Add(attempt)
+	value, err := attempts.Encode(history)
+	require.NoError(t, err)
+
+	loaded, err := attempts.Decode(5, value)
+	require.NoError(t, err)
+	require.Len(t, loaded.Attempts, 1)
+	assert.Equal(t, attempt.Steps, loaded.Attempts[0].Steps)
+	assert.Equal(t, attempt.Commits, loaded.Attempts[0].Commits)
+	assert.True(t, loaded.Attempts[0].Started.Equal(started))
+	assert.True(t, loaded.Last().Failed())
+}
+
+func TestEncode_Limit(t *testing.T) {
+	reasons := strings.Repeat("ž", 10000)
+
+	t.Run("oldest attempts dropped", func(t *testing.T) {
+		history := attempts.History{IssueID: 1}
+		for n := 0; n < 5; n++ {
+			history.Add(attempts.Attempt{LastComment: n, Reasons: reasons})
+		}
+		value, err := attempts.Encode(history)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(value), 60000)
+
+		loaded, err := attempts.Decode(1, value)
+		require.NoError(t, err)
+		require.Len(t, loaded.Attempts, 2)
+		assert.Equal(t, 4, loaded.Last().LastComment)
+		assert.Equal(t, reasons, loaded.Last().Reasons)
+	})
+
+	t.Run("long reasons cut", func(t *testing.T) {
+		history := attempts.History{IssueID: 1}
+		history.Add(attempts.Attempt{Reasons: strings.Repeat(reasons, 5), Rejection: "too slow"})
+		value, err := attempts.Encode(history)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(value), 60000)
+
+		loaded, err := attempts.Decode(1, value)
+		require.NoError(t, err)
+		require.Len(t, loaded.Attempts, 1)
+		assert.True(t, strings.HasPrefix(loaded.Last().Reasons, reasons))
+		assert.True(t, utf8.ValidString(loaded.Last().Reasons))
+	})
+}
+
+func TestHistory_Add(t *testing.T) {
+	history := attempts.History{IssueID: 1}
+	for n := 0; n < 25; n++ {
+		history.Add(attempts.Attempt{LastComment: n, Verdict: attempts.VerdictSuccess})
+	}
+	require.Len(t, history.Attempts, 20)
+	assert.Equal(t, 5, history.Attempts[0].LastComment)
+	assert.Equal(t, 24, history.Last().LastComment)
+	assert.False(t, history.Last().Failed())
+}
+
+func TestHistory_ForState(t *testing.T) {
+	history := attempts.History{Attempts: []attempts.Attempt{
+		{State: "In Progress", LastComment: 1},
+		{State: "In Review", LastComment: 2},
+		{State: "In Progress", LastComment: 3},
+	}}
+
+	tests := []struct {
+		name     string
+		state    string
+		expected []int
+	}{
+		{name: "multiple", state: "In Progress", expected: []int{1, 3}},
+		{name: "single", state: "In Review", expected: []int{2}},
+		{name: "none", state: "Done", expected: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found := make([]int, 0)
+			for _, attempt := range history.ForState(tt.state) {
+				found = append(found, attempt.LastComment)
+			}
+			assert.Equal(t, tt.expected, found)
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Package branchname renders issue branch names from project branch_template
+// and checks them against git ref name rules.
+package branchname
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+const (
+	// MaxLength is the longest branch name we produce. Git allows more, but file systems and UIs do not like it.
+	MaxLength = 200
+	// SlugLength is the longest slug. Use trunc for shorter.
+	SlugLength = 50
+)
+
+// Data is available in branch template.
+type Data struct {
+	ID       int
+	ParentID int
+	Tracker  string
+	Project  string
+	Subject  string
+}
+
+// Funcs are helpers available in branch template.
+var Funcs = template.FuncMap{
+	"slug":  Slug,
+	"trunc": Trunc,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// Slug turns text into lowercase ascii words separated by "-" (at most SlugLength long).
+// Example: "Fix login (SSO)" -> "fix-login-sso".
+func Slug(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return Trunc(SlugLength, b.String())
+}
+
+// Trunc cuts s to at most n bytes and removes trailing separators left by the cut.
+// Argument order allows pipelines: {{slug .Subject | trunc 40}}.
+func Trunc(n int, s string) string {
+	if n >= 0 && len(s) > n {
+		s = s[:n]
+	}
+	return strings.TrimRight(s, "-_./")
+}
+
+// Render executes branch template and checks that result is valid branch name.
+func Render(tmpl string, data Data) (string, error) {
+	t, err := template.New("branch").Funcs(Funcs).Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("branch_template is not valid: %w", err)
+	}
+	var buf bytes.Buffer
+	if err = t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("branch_template failed: %w", err)
+	}
+	name := buf.String()
+	if err = Validate(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// Check renders template with unfriendly sample issues and rejects it if any of them gives invalid branch name
+// or if different issues would share same branch.
+func Check(tmpl, project string, trackers []string) error {
+	if len(trackers) == 0 {
+		trackers = []string{"Task"}
+	}
+	subjects := []string{
+		"Fix: login ~bug^ with [brackets]? and *stars* in \\path\\.. @{now}.lock",
+		"...",
+		"",
+		"Ünïcödé ünd 日本語",
+		strings.Repeat("very long subject ", 30),
+	}
+	for _, tracker := range trackers {
+		for _, subject := range subjects {
+			first := Data{ID: 12, ParentID: 7, Tracker: tracker, Project: project, Subject: subject}
+			a, err := Render(tmpl, first)
+			if err != nil {
+				return fmt.Errorf("%w (tracker %q, subject %q)", err, tracker, subject)
+			}
+			second := first
+			second.ID, second.ParentID = 13, 0
+			b, err := Render(tmpl, second)
+			if err != nil {
+				return fmt.Errorf("%w (tracker %q, subject %q, no parent)", err, tracker, subject)
+			}
+			if a == b {
+				return fmt.Errorf("branch_template gives same branch %q for different issues, use {{.ID}}", a)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks name against git check-ref-format rules for branches.
+func Validate(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("branch name is empty")
+	case len(name) > MaxLength:
+		return fmt.Errorf("branch name %q is longer than %d", name, MaxLength)
+	case name == "@":
+		return fmt.Errorf("branch name cannot be %q", name)
+	case strings.HasPrefix(name, "-"):
+		return fmt.Errorf("branch name %q cannot start with \"-\"", name)
+	case strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/"):
+		return fmt.Errorf("branch name %q cannot start or end with \"/\"", name)
+	case strings.HasSuffix(name, "."):
+		return fmt.Errorf("branch name %q cannot end with \".\"", name)
+	}
+	for _, bad := range []string{"..", "@{", "//"} {
+		if strings.Contains(name, bad) {
+			return fmt.Errorf("branch name %q cannot contain %q", name, bad)
+		}
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(" ~^:?*[\\", r) {
+			return fmt.Errorf("branch name %q cannot contain %q", name, r)
+		}
+	}
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") || strings.HasSuffix(part, ".lock") {
+			return fmt.Errorf("branch name %q part %q cannot start with \".\" or end with \".lock\"", name, part)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package branchname_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/branchname"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"Fix login (SSO)":    "fix-login-sso",
+		"  Many   spaces  ":  "many-spaces",
+		"Ünïcödé und 日本語 42": "n-c-d-und-42",
+		"...":                "",
+		"already-slug":       "already-slug",
+	}
+	for in, expected := range tests {
+		assert.Equal(t, expected, branchname.Slug(in), in)
+	}
+}
+
+func TestTrunc(t *testing.T) {
+	assert.Equal(t, "fix-login", branchname.Trunc(10, "fix-login-sso"))
+	assert.Equal(t, "fix", branchname.Trunc(10, "fix"))
+	assert.Equal(t, "", branchname.Trunc(0, "fix"))
+	assert.Len(t, branchname.Slug(strings.Repeat("long subject ", 10)), branchname.SlugLength)
+	assert.Equal(t, "ab", branchname.Slug(strings.Repeat(" ", branchname.SlugLength)+"ab"))
+	assert.Equal(t, "a", branchname.Trunc(2, "a-b"), "trailing dash removed")
+}
+
+func TestRender(t *testing.T) {
+	data := branchname.Data{ID: 42, ParentID: 7, Tracker: "Bug", Project: "shop", Subject: "Fix login (SSO) for admins"}
+
+	tests := []struct {
+		name      string
+		tmpl      string
+		expected  string
+		expectErr bool
+	}{
+		{name: "legacy like", tmpl: "AI-{{.ID}}", expected: "AI-42"},
+		{name: "slug", tmpl: "ai/{{.Tracker}}/{{.ID}}-{{slug .Subject}}", expected: "ai/Bug/42-fix-login-sso-for-admins"},
+		{name: "trunc", tmpl: "ai/{{lower .Tracker}}/{{.ID}}-{{slug .Subject | trunc 9}}", expected: "ai/bug/42-fix-login"},
+		{name: "parent", tmpl: "{{.Project}}/{{.ParentID}}/{{.ID}}", expected: "shop/7/42"},
+		{name: "raw subject", tmpl: "ai/{{.ID}}-{{.Subject}}", expectErr: true},
+		{name: "parse error", tmpl: "ai/{{.ID", expectErr: true},
+		{name: "unknown field", tmpl: "ai/{{.Author}}", expectErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, err := branchname.Render(tt.tmpl, data)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, name)
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	assert.NoError(t, branchname.Check("ai/{{.Tracker}}/{{.ID}}-{{slug .Subject | trunc 40}}", "shop", []string{"Bug", "Task"}))
+	assert.NoError(t, branchname.Check("{{.Project}}/{{.ID}}-{{slug .Subject}}", "shop", nil))
+	assert.Error(t, branchname.Check("ai/{{.Tracker}}/{{.ID}}", "shop", []string{"Bug Fix"}), "tracker with space")
+	assert.Error(t, branchname.Check("ai/{{.ID}}-{{.Subject}}", "shop", nil), "raw subject")
+	assert.Error(t, branchname.Check("ai/{{slug .Subject}}", "shop", nil), "no id")
+	assert.Error(t, branchname.Check("ai/{{.ID}}.lock", "shop", nil))
+}
+
+func TestValidate(t *testing.T) {
+	valid := []string{"AI-1", "ai/bug/12-fix", "feature/x.y", "a@b"}
+	for _, name := range valid {
+		assert.NoError(t, branchname.Validate(name), name)
+	}
+
+	invalid := []string{
+		"", "@", "-x", "/x", "x/", "x.", "a..b", "a@{b", "a//b", "a b", "a~b", "a^b", "a:b", "a?b", "a*b", "a[b", "a\\b",
+		"a/.b", "a.lock", "a.lock/b", "a\tb", strings.Repeat("a", branchname.MaxLength+1),
+	}
+	for _, name := range invalid {
+		assert.Error(t, branchname.Validate(name), name)
+	}
+}
@@ -0,0 +1,57 @@
+package issue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/andrejsstepanovs/andai/internal"
+	"github.com/andrejsstepanovs/andai/internal/runlog"
+	"github.com/spf13/cobra"
+)
+
+func newLogsCommand(deps internal.DependenciesLoader) *cobra.Command {
+	var follow bool
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Print step output log of the issue (active or last run). First param - issue ID. [OPTIONAL...] --follow",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			issueID, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("issue ID %q is not a number", args[0])
+			}
+
+			d := deps()
+			settings, err := d.Config.Load()
+			if err != nil {
+				return err
+			}
+
+			path, active, err := runlog.Latest(settings.Logs.GetDir(), issueID)
+			if errors.Is(err, runlog.ErrNoLogs) {
+				return fmt.Errorf("no logs found for issue %d in %q", issueID, settings.Logs.GetDir())
+			}
+			if err != nil {
+				return err
+			}
+			if active {
+				log.Printf("Issue %d is being worked on right now, log: %s", issueID, path)
+			} else {
+				log.Printf("Last run log of issue %d: %s", issueID, path)
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return runlog.Follow(ctx, path, os.Stdout, follow && active, 500*time.Millisecond)
+		},
+	}
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "Keep printing new output while issue is being worked on")
+	return cmd
+}
@@ -16,6 +16,7 @@ func Cmd(deps internal.DependenciesLoader) *cobra.Command {
 		newCreateCommand(deps),
 		newMoveCommand(deps),
 		newMoveChildrenCommand(deps),
+		newLogsCommand(deps),
 	)
 
 	return cmd
@@ -2,6 +2,9 @@ package cmd
 
 import (
 	"context"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/andrejsstepanovs/andai/internal/cmd/setup"
 	"github.com/andrejsstepanovs/andai/internal/cmd/work"
@@ -11,10 +14,10 @@ import (
 )
 
 func LetsGo(deps internal.DependenciesLoader) *cobra.Command {
-	var project string
+	opts := work.LoopOptions{}
 	cmd := &cobra.Command{
 		Use:   "go",
-		Short: "Setup and Run the workflow loop. [OPTIONAL...] --project <identifier>",
+		Short: "Setup and Run the workflow loop. [OPTIONAL...] --project <identifier> --listen <addr> (same options as work loop)",
 		RunE: func(_ *cobra.Command, _ []string) error {
 			d := deps()
 			settings, err := d.Config.Load()
@@ -26,12 +29,13 @@ func LetsGo(deps internal.DependenciesLoader) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			ctx := context.Background()
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
 
-			return work.Loop(ctx, deps, project)
+			return work.Loop(ctx, deps, opts)
 		},
 	}
-	cmd.Flags().StringVar(&project, "project", "", "Project identifier (optional)")
+	work.BindLoopFlags(cmd, &opts)
 
 	return cmd
 }
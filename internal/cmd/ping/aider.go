@@ -89,7 +89,7 @@ func pingAider(redmine *redmine.Model, projects settings.Projects, aider setting
 	}
 
 	options := exec.AiderCommand(contextFile, step, aider)
-	output, err := exec.Exec(step.Command, time.Minute*5, options)
+	output, err := exec.Run(time.Minute*5, step.Command, options...)
 	if err != nil {
 		log.Printf("Failed to execute command: %v", err)
 		return fmt.Errorf("error executing command: %v", err)
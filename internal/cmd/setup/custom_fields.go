@@ -34,7 +34,7 @@ func setupCustomFields(mod *model.Model) error {
 	createFields := []models.CustomField{
 		{
 			Name:        model.CustomFieldBranch,
-			Description: "Branch name to work in. If not set will be AI-123 (task id) or if main task and not set `final_branch` value will be used.",
+			Description: "Branch name to work in. If not set will be AI-123 (task id) or project `branch_template` (saved here on first use) or if main task and not set `final_branch` value will be used.",
 			Type:        "string",
 			Default:     "",
 			FormatStore: []string{
@@ -88,6 +88,20 @@ func setupCustomFields(mod *model.Model) error {
 			Visible:  0,
 			Editable: 1,
 		},
+		{
+			Name:        model.CustomFieldAttempts,
+			Description: "Attempt history (JSON) used by previous-attempts context. Auto set after every work session.",
+			Type:        "text",
+			Default:     "",
+			FormatStore: []string{
+				"text_formatting: ''",
+				"full_width_layout: '0'",
+				"",
+			},
+			IsFilter: 0,
+			Visible:  0,
+			Editable: 0,
+		},
 	}
 
 	trackerIDs := make([]int64, 0)
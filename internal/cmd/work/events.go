@@ -0,0 +1,136 @@
+package work
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	model "github.com/andrejsstepanovs/andai/internal/redmine"
+	redminemodels "github.com/andrejsstepanovs/andai/internal/redmine/models"
+)
+
+const (
+	// WebhookPath is where redmine webhooks (or plugins) should POST to wake up the work loop.
+	WebhookPath = "/webhook"
+	// WebhookTokenHeader carries webhook token. Token can also be given as "token" query parameter.
+	WebhookTokenHeader = "X-Andai-Token"
+	// DefaultDetectInterval is how often redmine db is checked for changes.
+	DefaultDetectInterval = 2 * time.Second
+)
+
+// wakeup lets event sources interrupt work loop sleep. Multiple events while loop is busy are collapsed into one.
+type wakeup struct {
+	ch chan string
+}
+
+func newWakeup() *wakeup {
+	return &wakeup{ch: make(chan string, 1)}
+}
+
+// Wake signals the loop. Never blocks.
+func (w *wakeup) Wake(reason string) {
+	select {
+	case w.ch <- reason:
+	default:
+	}
+}
+
+// C returns channel that receives wake up reason.
+func (w *wakeup) C() <-chan string {
+	return w.ch
+}
+
+// Drain forgets pending events. Called before checking for work, because that check will see all changes anyway.
+func (w *wakeup) Drain() {
+	select {
+	case <-w.ch:
+	default:
+	}
+}
+
+// webhookHandler accepts POST request as a signal that something changed in redmine.
+// If token is set, request must carry it in header or query, otherwise any POST is accepted.
+func webhookHandler(w *wakeup, token string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			rw.Header().Set("Allow", http.MethodPost)
+			http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !validToken(r, token) {
+			log.Printf("Webhook with invalid token rejected from %s", r.RemoteAddr)
+			http.Error(rw, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		log.Printf("Webhook received from %s", r.RemoteAddr)
+		w.Wake("webhook")
+		rw.WriteHeader(http.StatusAccepted)
+	})
+}
+
+// validToken checks request token. Empty token means no check.
+func validToken(r *http.Request, token string) bool {
+	if token == "" {
+		return true
+	}
+	given := r.Header.Get(WebhookTokenHeader)
+	if given == "" {
+		given = r.URL.Query().Get("token")
+	}
+	return subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+// listenWebhook serves webhook endpoint until ctx is done.
+func listenWebhook(ctx context.Context, addr, token string, w *wakeup) error {
+	mux := http.NewServeMux()
+	mux.Handle(WebhookPath, webhookHandler(w, token))
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx) // nolint:contextcheck
+	}()
+
+	log.Printf("Listening for webhooks on %s%s", addr, WebhookPath)
+	err := server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// detectChanges polls cheap redmine db max ids and wakes the loop when they move.
+func detectChanges(ctx context.Context, model *model.Model, interval time.Duration, w *wakeup) {
+	var last redminemodels.ChangeMarker
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		marker, err := model.DBGetChangeMarker()
+		switch {
+		case err != nil:
+			log.Printf("Change detector failed to check redmine db err: %v", err)
+		case last != redminemodels.ChangeMarker{} && marker != last:
+			log.Printf("Change detected in redmine (journal id=%d, issue id=%d)", marker.LastJournalID, marker.LastIssueID)
+			w.Wake("db-change")
+			last = marker
+		default:
+			last = marker
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
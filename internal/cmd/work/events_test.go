@@ -0,0 +1,131 @@
+package work
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	model "github.com/andrejsstepanovs/andai/internal/redmine"
+	"github.com/andrejsstepanovs/andai/internal/redmine/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWakeup(t *testing.T) {
+	w := newWakeup()
+	w.Wake("first")
+	w.Wake("second")
+
+	select {
+	case reason := <-w.C():
+		assert.Equal(t, "first", reason, "events are collapsed into first one")
+	default:
+		t.Fatal("expected wake up")
+	}
+
+	w.Wake("again")
+	w.Drain()
+	select {
+	case reason := <-w.C():
+		t.Fatalf("expected drained channel, got %q", reason)
+	default:
+	}
+
+	w.Drain() // draining empty channel does not block
+}
+
+func TestWebhookHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		target string
+		header string
+		status int
+		woken  bool
+	}{
+		{name: "get not allowed", method: http.MethodGet, target: WebhookPath, status: http.StatusMethodNotAllowed},
+		{name: "no token configured", method: http.MethodPost, target: WebhookPath, status: http.StatusAccepted, woken: true},
+		{name: "missing token", token: "s3cret", method: http.MethodPost, target: WebhookPath, status: http.StatusUnauthorized},
+		{name: "wrong token", token: "s3cret", method: http.MethodPost, target: WebhookPath, header: "nope", status: http.StatusUnauthorized},
+		{name: "token in header", token: "s3cret", method: http.MethodPost, target: WebhookPath, header: "s3cret", status: http.StatusAccepted, woken: true},
+		{name: "token in query", token: "s3cret", method: http.MethodPost, target: WebhookPath + "?token=s3cret", status: http.StatusAccepted, woken: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newWakeup()
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.header != "" {
+				req.Header.Set(WebhookTokenHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			webhookHandler(w, tt.token).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			select {
+			case reason := <-w.C():
+				assert.True(t, tt.woken)
+				assert.Equal(t, "webhook", reason)
+			default:
+				assert.False(t, tt.woken)
+			}
+		})
+	}
+}
+
+func TestDetectChanges_IgnoresOwnChanges(t *testing.T) {
+	queryAPIUser := regexp.QuoteMeta("SELECT user_id FROM tokens WHERE action = ? AND value = ?")
+	queryMarker := regexp.QuoteMeta("SELECT (SELECT COALESCE(MAX(id), 0) FROM journals WHERE user_id != ?), (SELECT COALESCE(MAX(id), 0) FROM issues WHERE author_id != ?)")
+	markerRows := func(journalID, issueID int) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"journal", "issue"}).AddRow(journalID, issueID)
+	}
+
+	queryOtherUsers := regexp.QuoteMeta("SELECT COUNT(*) FROM users WHERE type = ? AND status = ? AND id != ?")
+
+	tests := []struct {
+		name      string
+		others    int
+		ignoredID int
+	}{
+		{name: "own changes ignored", others: 2, ignoredID: 7},
+		{name: "api user is the only user", others: 0, ignoredID: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, db, err := sqlmock.New()
+			require.NoError(t, err)
+			defer conn.Close()
+
+			db.ExpectQuery(queryAPIUser).WithArgs(model.TokenActionAPI, sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(7))
+			db.ExpectQuery(queryOtherUsers).WithArgs("User", 1, 7).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.others))
+			db.ExpectQuery(queryMarker).WithArgs(tt.ignoredID, tt.ignoredID).WillReturnRows(markerRows(10, 3))
+			db.ExpectQuery(queryMarker).WithArgs(tt.ignoredID, tt.ignoredID).WillReturnRows(markerRows(10, 3))
+			db.ExpectQuery(queryMarker).WithArgs(tt.ignoredID, tt.ignoredID).WillReturnRows(markerRows(11, 3))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			w := newWakeup()
+			done := make(chan struct{})
+			go func() {
+				detectChanges(ctx, model.NewModel(conn, mocks.NewAPIInterface(t)), time.Millisecond, w)
+				close(done)
+			}()
+
+			select {
+			case reason := <-w.C():
+				assert.Equal(t, "db-change", reason)
+			case <-time.After(5 * time.Second):
+				t.Fatal("change was not detected")
+			}
+			cancel()
+			<-done
+		})
+	}
+}
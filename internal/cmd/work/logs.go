@@ -0,0 +1,38 @@
+package work
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/andrejsstepanovs/andai/internal/runlog"
+)
+
+// maxRunLogAttachBytes keeps attached run log below default redmine attachment size limit (5MB).
+const maxRunLogAttachBytes = 4 * 1024 * 1024
+
+// runLogAttacher uploads files to redmine issue.
+type runLogAttacher interface {
+	APIAttachFile(issueID int, fileName, contentType string, content []byte, comment string) error
+}
+
+// attachRunLog uploads finished run log to the issue with a single comment. Empty logs are not attached.
+func attachRunLog(api runLogAttacher, issueID int, path string) {
+	content, err := runlog.Tail(path, maxRunLogAttachBytes)
+	if err != nil {
+		log.Printf("Failed to read run log %q: %v", path, err)
+		return
+	}
+	if len(content) == 0 {
+		return
+	}
+
+	name := fmt.Sprintf("andai-issue-%d-%s", issueID, filepath.Base(path))
+	comment := fmt.Sprintf("Run log: attachment:%q", name)
+	err = api.APIAttachFile(issueID, name, "text/plain", content, comment)
+	if err != nil {
+		log.Printf("Failed to attach run log to issue (%d): %v", issueID, err)
+		return
+	}
+	log.Printf("Run log attached to issue (%d) as %q", issueID, name)
+}
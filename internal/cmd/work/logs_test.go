@@ -0,0 +1,49 @@
+package work
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type attachedFile struct {
+	issueID     int
+	name        string
+	contentType string
+	content     string
+	comment     string
+}
+
+type fakeAttacher struct {
+	files []attachedFile
+}
+
+func (f *fakeAttacher) APIAttachFile(issueID int, fileName, contentType string, content []byte, comment string) error {
+	f.files = append(f.files, attachedFile{issueID, fileName, contentType, string(content), comment})
+	return nil
+}
+
+func TestAttachRunLog(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "20260101-120000.log")
+	require.NoError(t, os.WriteFile(path, []byte("12:00:00 ==> #7 1/1 bash make test\n"), 0o600))
+	empty := filepath.Join(dir, "20260101-130000.log")
+	require.NoError(t, os.WriteFile(empty, nil, 0o600))
+
+	api := &fakeAttacher{}
+	attachRunLog(api, 7, path)
+	attachRunLog(api, 7, empty)
+	attachRunLog(api, 7, filepath.Join(dir, "missing.log"))
+
+	require.Len(t, api.files, 1, "only non empty existing log is attached")
+	assert.Equal(t, attachedFile{
+		issueID:     7,
+		name:        "andai-issue-7-20260101-120000.log",
+		contentType: "text/plain",
+		content:     "12:00:00 ==> #7 1/1 bash make test\n",
+		comment:     `Run log: attachment:"andai-issue-7-20260101-120000.log"`,
+	}, api.files[0])
+}
@@ -15,6 +15,7 @@ func Cmd(deps internal.DependenciesLoader) *cobra.Command {
 	cmd.AddCommand(
 		newNextCommand(deps),
 		newTriggersCommand(deps),
+		newWatchdogCommand(deps),
 		newLoopCommand(deps),
 	)
 
@@ -0,0 +1,222 @@
+package work
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/andrejsstepanovs/andai/internal/monitor"
+)
+
+// statusHandler serves loop status API, dashboard page and controls.
+// Controls must carry controlToken in WebhookTokenHeader header. Browsers do not send custom headers cross-site,
+// so this also protects controls from CSRF.
+func statusHandler(tracker *monitor.Tracker, wake *wakeup, webhookToken, controlToken string) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(WebhookPath, webhookHandler(wake, webhookToken))
+
+	mux.HandleFunc("/", func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(rw, r)
+			return
+		}
+		rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = rw.Write([]byte(dashboardHTML))
+	})
+
+	mux.HandleFunc("/api/status", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			rw.Header().Set("Allow", http.MethodGet)
+			writeJSON(rw, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+		writeJSON(rw, http.StatusOK, tracker.Snapshot())
+	})
+
+	controls := map[string]func(r *http.Request) (int, string){
+		"/api/pause": func(_ *http.Request) (int, string) {
+			tracker.Pause()
+			log.Println("Work loop paused from dashboard")
+			return http.StatusOK, "paused"
+		},
+		"/api/resume": func(_ *http.Request) (int, string) {
+			tracker.Resume()
+			wake.Wake("resume")
+			log.Println("Work loop resumed from dashboard")
+			return http.StatusOK, "resumed"
+		},
+		"/api/skip": func(_ *http.Request) (int, string) {
+			if !tracker.SkipCurrent() {
+				return http.StatusConflict, "nothing to skip"
+			}
+			log.Println("Skip of current issue requested from dashboard")
+			return http.StatusOK, "current issue will be skipped after running step is finished"
+		},
+		"/api/unskip": func(r *http.Request) (int, string) {
+			issueID, err := strconv.Atoi(r.URL.Query().Get("id"))
+			if err != nil {
+				return http.StatusBadRequest, "issue id is required"
+			}
+			if !tracker.Unskip(issueID) {
+				return http.StatusConflict, fmt.Sprintf("issue %d is not skipped", issueID)
+			}
+			wake.Wake("unskip")
+			log.Printf("Issue %d unskipped from dashboard", issueID)
+			return http.StatusOK, fmt.Sprintf("issue %d can be picked up again", issueID)
+		},
+	}
+	for path, control := range controls {
+		mux.HandleFunc(path, func(rw http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				rw.Header().Set("Allow", http.MethodPost)
+				writeJSON(rw, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+				return
+			}
+			if !validControlToken(r, controlToken) {
+				writeJSON(rw, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+				return
+			}
+			code, message := control(r)
+			writeJSON(rw, code, map[string]string{"message": message})
+		})
+	}
+
+	return mux
+}
+
+// validControlToken checks token given in header. Query is not accepted, links must not trigger controls.
+func validControlToken(r *http.Request, token string) bool {
+	given := r.Header.Get(WebhookTokenHeader)
+	return token != "" && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+func writeJSON(rw http.ResponseWriter, code int, v any) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(code)
+	err := json.NewEncoder(rw).Encode(v)
+	if err != nil {
+		log.Printf("Failed to write status response err: %v", err)
+	}
+}
+
+// newControlToken returns random token for dashboard controls.
+func newControlToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// listenStatus serves status API and dashboard until ctx is done.
+// If controlToken is empty, random one is generated and dashboard link with it is logged.
+func listenStatus(ctx context.Context, addr, webhookToken, controlToken string, tracker *monitor.Tracker, wake *wakeup) error {
+	link := "?token=<control token>"
+	if controlToken == "" {
+		var err error
+		if controlToken, err = newControlToken(); err != nil {
+			return fmt.Errorf("failed to generate control token err: %v", err)
+		}
+		link = "?token=" + controlToken
+	}
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           statusHandler(tracker, wake, webhookToken, controlToken),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx) // nolint:contextcheck
+	}()
+
+	log.Printf("Status dashboard on http://%s/%s (API: /api/status)", addr, link)
+	err := server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+const dashboardHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>AndAI</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+td, th { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+.ok { color: #2a7; } .fail { color: #c33; } .muted { color: #888; }
+button { margin-right: .5em; }
+</style>
+</head>
+<body>
+<h1>AndAI work loop</h1>
+<p>
+<button onclick="control('pause')">Pause</button>
+<button onclick="control('resume')">Resume</button>
+<button onclick="control('skip')" title="Running step is not interrupted, issue is skipped when it finishes">Skip current issue</button>
+<span id="message" class="muted"></span>
+</p>
+<div id="status">Loading...</div>
+<script>
+function esc(s) { return String(s === undefined || s === null ? '' : s).replace(/[&<>"]/g, c => ({'&':'&amp;','<':'&lt;','>':'&gt;','"':'&quot;'}[c])); }
+const token = new URLSearchParams(location.search).get('token') || '';
+function control(action) {
+  fetch('/api/' + action, {method: 'POST', headers: {'X-Andai-Token': token}}).then(r => r.json()).then(d => {
+    document.getElementById('message').textContent = d.message || d.error;
+    refresh();
+  });
+}
+function refresh() {
+  fetch('/api/status').then(r => r.json()).then(s => {
+    let h = '<p>Loop: <b>' + (s.paused ? 'paused' : 'running') + '</b> since ' + esc(s.started_at) +
+      ' | LLM calls: ' + s.llm_calls + ' | aider calls: ' + s.aider_calls + '</p>';
+    h += '<h2>Current</h2>';
+    if (s.current) {
+      h += '<p>#' + s.current.id + ' ' + esc(s.current.type) + ' in ' + esc(s.current.state) + ' (' + esc(s.current.project) + '): ' +
+        esc(s.current.subject) + ' - ' + esc(s.elapsed) + '</p>';
+      if (s.current_step) {
+        h += '<p>Step ' + s.current_step.index + '/' + s.current_step.total + ': ' + esc(s.current_step.command) + ' ' +
+          esc(s.current_step.action) + ' - ' + esc(s.step_elapsed) + '</p>';
+      }
+    } else {
+      h += '<p class="muted">idle</p>';
+    }
+    h += '<h2>Queue</h2><table><tr><th>ID</th><th>Project</th><th>Type</th><th>State</th><th>Subject</th><th>Reason</th></tr>';
+    s.queue.forEach(q => {
+      h += '<tr><td>' + q.id + '</td><td>' + esc(q.project) + '</td><td>' + esc(q.type) + '</td><td>' + esc(q.state) + '</td><td>' +
+        esc(q.subject) + '</td><td>' + esc(q.reason) + '</td></tr>';
+    });
+    h += '</table>';
+    if (s.skipped.length) {
+      h += '<h2>Skipped</h2><p>';
+      s.skipped.forEach(id => { h += '#' + id + ' <button onclick="control(\'unskip?id=' + id + '\')">Unskip</button> '; });
+      h += '</p>';
+    }
+    h += '<h2>Recent steps</h2><table><tr><th>Issue</th><th>Step</th><th>Result</th><th>Duration</th><th>Finished</th></tr>';
+    s.recent_results.forEach(r => {
+      h += '<tr><td>' + r.issue_id + '</td><td>' + esc(r.command) + ' ' + esc(r.action) + '</td><td class="' + (r.success ? 'ok">ok' : 'fail">' + esc(r.error)) +
+        '</td><td>' + esc(r.duration) + '</td><td>' + esc(r.finished_at) + '</td></tr>';
+    });
+    h += '</table>';
+    document.getElementById('status').innerHTML = h;
+  }).catch(e => { document.getElementById('status').textContent = 'Failed to load status: ' + e; });
+}
+refresh();
+setInterval(refresh, 3000);
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,94 @@
+package work
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/monitor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func statusRequest(t *testing.T, handler http.Handler, method, target, token string) (int, map[string]any) {
+	t.Helper()
+	req := httptest.NewRequest(method, target, nil)
+	if token != "" {
+		req.Header.Set(WebhookTokenHeader, token)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	body := map[string]any{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return rec.Code, body
+}
+
+func TestStatusHandler_Status(t *testing.T) {
+	tracker := monitor.NewTracker()
+	tracker.StartIssue(monitor.Issue{ID: 7, Subject: "Fix login"})
+	handler := statusHandler(tracker, newWakeup(), "", "s3cret")
+
+	code, body := statusRequest(t, handler, http.MethodGet, "/api/status", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, false, body["paused"])
+	assert.Equal(t, "Fix login", body["current"].(map[string]any)["subject"])
+
+	code, _ = statusRequest(t, handler, http.MethodPost, "/api/status", "s3cret")
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+}
+
+func TestStatusHandler_Controls(t *testing.T) {
+	tracker := monitor.NewTracker()
+	wake := newWakeup()
+	handler := statusHandler(tracker, wake, "", "s3cret")
+
+	t.Run("token required", func(t *testing.T) {
+		for _, token := range []string{"", "nope"} {
+			code, _ := statusRequest(t, handler, http.MethodPost, "/api/pause", token)
+			assert.Equal(t, http.StatusUnauthorized, code)
+		}
+		code, _ := statusRequest(t, handler, http.MethodPost, "/api/pause?token=s3cret", "")
+		assert.Equal(t, http.StatusUnauthorized, code, "token in query is not accepted")
+		assert.False(t, tracker.Paused())
+	})
+
+	t.Run("get not allowed", func(t *testing.T) {
+		code, _ := statusRequest(t, handler, http.MethodGet, "/api/pause", "s3cret")
+		assert.Equal(t, http.StatusMethodNotAllowed, code)
+	})
+
+	t.Run("pause and resume", func(t *testing.T) {
+		code, _ := statusRequest(t, handler, http.MethodPost, "/api/pause", "s3cret")
+		assert.Equal(t, http.StatusOK, code)
+		assert.True(t, tracker.Paused())
+
+		code, _ = statusRequest(t, handler, http.MethodPost, "/api/resume", "s3cret")
+		assert.Equal(t, http.StatusOK, code)
+		assert.False(t, tracker.Paused())
+		assert.Equal(t, "resume", <-wake.C())
+	})
+
+	t.Run("skip and unskip", func(t *testing.T) {
+		code, _ := statusRequest(t, handler, http.MethodPost, "/api/skip", "s3cret")
+		assert.Equal(t, http.StatusConflict, code, "nothing to skip")
+
+		tracker.StartIssue(monitor.Issue{ID: 7})
+		code, _ = statusRequest(t, handler, http.MethodPost, "/api/skip", "s3cret")
+		assert.Equal(t, http.StatusOK, code)
+		assert.True(t, tracker.SkipRequested(7))
+		tracker.FinishIssue()
+
+		code, _ = statusRequest(t, handler, http.MethodPost, "/api/unskip", "s3cret")
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = statusRequest(t, handler, http.MethodPost, "/api/unskip?id=8", "s3cret")
+		assert.Equal(t, http.StatusConflict, code)
+
+		code, body := statusRequest(t, handler, http.MethodPost, "/api/unskip?id=7", "s3cret")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "issue 7 can be picked up again", body["message"])
+		assert.False(t, tracker.IsSkipped(7))
+		assert.Equal(t, "unskip", <-wake.C())
+	})
+}
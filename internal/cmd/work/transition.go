@@ -3,18 +3,24 @@ package work
 import (
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/andrejsstepanovs/andai/internal"
 	model "github.com/andrejsstepanovs/andai/internal/redmine"
+	redminemodels "github.com/andrejsstepanovs/andai/internal/redmine/models"
 	"github.com/andrejsstepanovs/andai/internal/settings"
 	"github.com/mattn/go-redmine"
 	"github.com/spf13/cobra"
 )
 
+// triggerCommentPrefix marks audit comments of trigger transitions.
+// These status changes are cascaded right away, so journal scan must not process them again.
+const triggerCommentPrefix = "**Workflow trigger**"
+
 func newTriggersCommand(deps internal.DependenciesLoader) *cobra.Command {
 	return &cobra.Command{
 		Use:   "triggers",
-		Short: "Checks last issue status change and applies workflow triggers",
+		Short: "Checks issue status changes since last check and applies workflow triggers",
 		RunE: func(_ *cobra.Command, _ []string) error {
 			d := deps()
 			settings, err := d.Config.Load()
@@ -29,160 +35,366 @@ func newTriggersCommand(deps internal.DependenciesLoader) *cobra.Command {
 }
 
 func processTriggers(model *model.Model, workflow settings.Workflow) error {
-	issueID, statusIDFrom, statusIDTo, err := model.DBGetLastStatusChange()
+	lastJournalID, found, err := model.DBGetTriggersCheckpoint()
 	if err != nil {
-		log.Println("Failed to get last status change")
+		log.Println("Failed to get triggers checkpoint")
 		return err
 	}
-	if issueID == 0 {
+
+	if !found {
+		// first run. Do not replay whole redmine history, start watching from now on.
+		lastJournalID, err = model.DBGetLastJournalID()
+		if err != nil {
+			log.Println("Failed to get last journal id")
+			return err
+		}
+		err = model.DBSaveTriggersCheckpoint(lastJournalID)
+		if err != nil {
+			return fmt.Errorf("failed to save triggers checkpoint err: %v", err)
+		}
+		log.Printf("Triggers checkpoint initialized at journal id=%d\n", lastJournalID)
+		return nil
+	}
+
+	changes, err := model.DBGetStatusChangesSince(lastJournalID)
+	if err != nil {
+		log.Println("Failed to get status changes")
+		return err
+	}
+	if len(changes) == 0 {
 		log.Println("No status change found")
 		return nil
 	}
-	issue, err := model.API().Issue(issueID)
+	log.Printf("Found %d status changes since journal id=%d\n", len(changes), lastJournalID)
+
+	for _, change := range changes {
+		if strings.HasPrefix(change.Notes, triggerCommentPrefix) {
+			log.Printf("Skipping status change (journal id=%d) of issue %d, it was made by trigger\n", change.JournalID, change.IssueID)
+		} else {
+			err = processStatusChange(model, workflow, change)
+			if err != nil {
+				return fmt.Errorf("failed to process status change (journal id=%d) err: %v", change.JournalID, err)
+			}
+		}
+
+		// move checkpoint after every processed change so nothing is applied twice
+		err = model.DBSaveTriggersCheckpoint(change.JournalID)
+		if err != nil {
+			return fmt.Errorf("failed to save triggers checkpoint err: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func processStatusChange(model *model.Model, workflow settings.Workflow, change redminemodels.StatusChange) error {
+	issue, err := model.API().Issue(change.IssueID)
 	if err != nil {
-		log.Printf("Failed to get issue: %d", issueID)
+		if err.Error() == "Not Found" {
+			log.Printf("Issue not found: %d", change.IssueID)
+			return nil
+		}
+		log.Printf("Failed to get issue: %d", change.IssueID)
 		return err
 	}
 	if issue == nil {
-		log.Printf("Issue not found: %d", issueID)
+		log.Printf("Issue not found: %d", change.IssueID)
 		return nil
 	}
 	log.Printf("Checking %q id=%d project=%d\n", issue.Tracker.Name, issue.Id, issue.Project.Id)
 
-	statusFrom, err := model.APIGetIssueStatusByID(statusIDFrom)
+	statusFrom, err := model.APIGetIssueStatusByID(change.StatusIDFrom)
 	if err != nil {
-		log.Printf("Failed to get status: %d", statusIDFrom)
+		log.Printf("Failed to get status: %d", change.StatusIDFrom)
 		return err
 	}
-	statusTo, err := model.APIGetIssueStatusByID(statusIDTo)
+	statusTo, err := model.APIGetIssueStatusByID(change.StatusIDTo)
 	if err != nil {
-		log.Printf("Failed to get status: %s", statusTo.Name)
+		log.Printf("Failed to get status: %d", change.StatusIDTo)
 		return err
 	}
 
-	log.Printf("Last status change for %q %d: %q: %d -> %d (%s -> %s)\n", issue.Tracker.Name, issue.Id, issue.Subject, statusIDFrom, statusIDTo, statusFrom.Name, statusTo.Name)
+	log.Printf("Status change for %q %d: %q: %d -> %d (%s -> %s)\n", issue.Tracker.Name, issue.Id, issue.Subject, change.StatusIDFrom, change.StatusIDTo, statusFrom.Name, statusTo.Name)
 
-	triggers := workflow.Triggers.GetTriggers(settings.IssueTypeName(issue.Tracker.Name))
-	if len(triggers) == 0 {
-		log.Println("No triggers found")
-		return nil
+	cascade, err := newTriggerCascade(model, workflow)
+	if err != nil {
+		return err
 	}
-	log.Printf("Triggers for %q found: %d\n", issue.Tracker.Name, len(triggers))
+	return cascade.Run(triggerEvent{issue: *issue, statusFrom: statusFrom, statusTo: statusTo})
+}
 
-	for _, trigger := range triggers {
-		err = processTrigger(workflow, trigger, model, statusFrom, statusTo, issue)
+// triggerEvent is a single status change that may trigger other transitions.
+type triggerEvent struct {
+	issue      redmine.Issue
+	statusFrom redmine.IssueStatus
+	statusTo   redmine.IssueStatus
+	depth      int
+}
+
+// triggerCascade applies triggers for status change and feeds trigger caused transitions back into triggers.
+// Same issue is never moved into the same state twice within one cascade (cycle protection)
+// and cascading stops at workflow trigger_max_depth.
+type triggerCascade struct {
+	model       *model.Model
+	workflow    settings.Workflow
+	maxDepth    int
+	statusNames map[int]settings.StateName
+	visited     map[string]bool
+}
+
+func newTriggerCascade(model *model.Model, workflow settings.Workflow) (*triggerCascade, error) {
+	statuses, err := model.API().IssueStatuses()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue statuses err: %v", err)
+	}
+	statusNames := make(map[int]settings.StateName, len(statuses))
+	for _, status := range statuses {
+		statusNames[status.Id] = settings.StateName(status.Name)
+	}
+
+	return &triggerCascade{
+		model:       model,
+		workflow:    workflow,
+		maxDepth:    workflow.GetTriggerMaxDepth(),
+		statusNames: statusNames,
+		visited:     make(map[string]bool),
+	}, nil
+}
+
+func (c *triggerCascade) Run(root triggerEvent) error {
+	c.visited[triggerVisitKey(root.issue.Id, root.statusTo.Id)] = true
+
+	queue := []triggerEvent{root}
+	for len(queue) > 0 {
+		event := queue[0]
+		queue = queue[1:]
+
+		caused, err := c.apply(event)
 		if err != nil {
-			return fmt.Errorf("failed to process trigger err: %v", err)
+			return err
+		}
+
+		for _, next := range caused {
+			if next.depth >= c.maxDepth {
+				log.Printf("Trigger cascade max depth (%d) reached at issue %d, not checking its triggers\n", c.maxDepth, next.issue.Id)
+				err = c.model.Comment(next.issue, triggerDepthComment(c.maxDepth, next))
+				if err != nil {
+					return fmt.Errorf("failed to comment trigger max depth err: %v", err)
+				}
+				continue
+			}
+			queue = append(queue, next)
 		}
 	}
 
 	return nil
 }
 
-func processTrigger(
-	workflow settings.Workflow,
-	trigger settings.Trigger,
-	model *model.Model,
-	statusFrom redmine.IssueStatus,
-	statusTo redmine.IssueStatus,
-	issue *redmine.Issue,
-) error {
-	action := trigger.GetTriggerIf(settings.StateName(statusTo.Name))
-	if action == nil {
-		//log.Printf("No action found for %q %d %s -> %s\n", issue.Tracker.Name, issue.Id, statusFrom.Name, statusTo.Name)
-		return nil
+func (c *triggerCascade) apply(event triggerEvent) ([]triggerEvent, error) {
+	issue := event.issue
+	triggers := c.workflow.Triggers.GetTriggers(settings.IssueTypeName(issue.Tracker.Name))
+	if len(triggers) == 0 {
+		log.Println("No triggers found")
+		return nil, nil
 	}
-	log.Printf("Trigger Action was found for %q %d %s -> %s\n", issue.Tracker.Name, issue.Id, statusFrom.Name, statusTo.Name)
+	log.Printf("Triggers for %q found: %d\n", issue.Tracker.Name, len(triggers))
 
-	parent, err := model.APIGetParent(*issue)
-	if err != nil {
-		return fmt.Errorf("failed to get redmine parent issue err: %v", err)
-	}
-	if parent != nil {
-		log.Printf("Parent found for %q %d: %q\n", issue.Tracker.Name, issue.Id, parent.Subject)
-	} else {
-		log.Printf("No parent found for %q %d\n", issue.Tracker.Name, issue.Id)
-	}
-	children, err := model.APIGetChildren(*issue)
-	if err != nil {
-		return fmt.Errorf("failed to get redmine children issue err: %v", err)
-	}
-	log.Printf("Children found for %q %d: %d\n", issue.Tracker.Name, issue.Id, len(children))
+	caused := make([]triggerEvent, 0)
+	for _, trigger := range triggers {
+		for _, action := range trigger.GetTriggerIfs(settings.StateName(event.statusTo.Name)) {
+			log.Printf("Trigger Action was found for %q %d %s -> %s\n", issue.Tracker.Name, issue.Id, event.statusFrom.Name, event.statusTo.Name)
 
-	siblings, err := model.APIGetIssueSiblings(*issue)
-	if err != nil {
-		log.Printf("Failed to get siblings for %q %d\n", issue.Tracker.Name, issue.Id)
+			ok, err := c.conditionsMet(action, issue)
+			if err != nil {
+				return nil, fmt.Errorf("failed to process trigger err: %v", err)
+			}
+			if !ok {
+				continue
+			}
+
+			moved, err := c.transitionWho(action, event)
+			if err != nil {
+				return nil, fmt.Errorf("failed to process trigger err: %v", err)
+			}
+			caused = append(caused, moved...)
+		}
 	}
-	log.Printf("Siblings found for %q %d: %d\n", issue.Tracker.Name, issue.Id, len(siblings))
 
-	siblingsStatuses := make([]settings.StateName, 0)
-	for _, sibling := range siblings {
-		status, err := model.APIGetIssueStatusByID(sibling.Status.Id)
+	return caused, nil
+}
+
+func (c *triggerCascade) conditionsMet(action settings.TriggerIf, issue redmine.Issue) (bool, error) {
+	if action.AllSiblingsStatus != "" || action.AnySiblingStatus != "" {
+		siblings, err := c.model.APIGetIssueSiblings(issue)
 		if err != nil {
-			log.Printf("Failed to get status for sibling %q %d\n", sibling.Tracker.Name, sibling.Id)
+			return false, fmt.Errorf("failed to get siblings for %q %d err: %v", issue.Tracker.Name, issue.Id, err)
+		}
+		siblingsStatuses := c.issueStatuses(siblings)
+		log.Printf("Siblings statuses for %q %d: %v\n", issue.Tracker.Name, issue.Id, siblingsStatuses)
+
+		if !action.AllSiblingsCheck(siblingsStatuses) {
+			log.Printf("All siblings status check for %q %d failed\n", issue.Tracker.Name, issue.Id)
+			return false, nil
+		}
+		if !action.AnySiblingCheck(siblingsStatuses) {
+			log.Printf("Any sibling status check for %q %d failed\n", issue.Tracker.Name, issue.Id)
+			return false, nil
 		}
-		siblingsStatuses = append(siblingsStatuses, settings.StateName(status.Name))
 	}
-	log.Printf("Siblings statuses for %q %d: %v\n", issue.Tracker.Name, issue.Id, siblingsStatuses)
-	siblingStatusOK := action.AllSiblingsCheck(siblingsStatuses)
-	log.Printf("Siblings status check for %q %d: %t\n", issue.Tracker.Name, issue.Id, siblingStatusOK)
 
-	if !siblingStatusOK {
-		return nil
+	if action.AllChildrenStatus != "" || action.ChildrenCount != nil {
+		children, err := c.model.DBGetChildren(issue)
+		if err != nil {
+			return false, fmt.Errorf("failed to get children for %q %d err: %v", issue.Tracker.Name, issue.Id, err)
+		}
+		childrenStatuses := c.issueStatuses(children)
+		log.Printf("Children statuses for %q %d: %v\n", issue.Tracker.Name, issue.Id, childrenStatuses)
+
+		if !action.ChildrenCountCheck(len(children)) {
+			log.Printf("Children count check for %q %d failed (%d children)\n", issue.Tracker.Name, issue.Id, len(children))
+			return false, nil
+		}
+		if !action.AllChildrenCheck(childrenStatuses) {
+			log.Printf("All children status check for %q %d failed\n", issue.Tracker.Name, issue.Id)
+			return false, nil
+		}
 	}
 
-	nextIssueStatus, err := model.APIGetIssueStatus(string(action.TriggerTransition.To))
-	if err != nil {
-		return fmt.Errorf("failed to get next issue status err: %v", err)
+	if !action.CustomFieldCheck(customFieldValues(issue)) {
+		log.Printf("Custom field %q check for %q %d failed\n", action.CustomField.Name, issue.Tracker.Name, issue.Id)
+		return false, nil
 	}
 
-	return processTriggerWho(action, children, workflow, model, nextIssueStatus, parent, issue)
+	return true, nil
 }
 
-func processTriggerWho(
-	action *settings.TriggerIf,
-	children []redmine.Issue,
-	workflow settings.Workflow,
-	model *model.Model,
-	nextIssueStatus redmine.IssueStatus,
-	parent *redmine.Issue,
-	issue *redmine.Issue,
-) error {
+func (c *triggerCascade) transitionWho(action settings.TriggerIf, event triggerEvent) ([]triggerEvent, error) {
+	issue := event.issue
+	nextIssueStatus, err := c.model.APIGetIssueStatus(string(action.TriggerTransition.To))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next issue status err: %v", err)
+	}
+
+	targets := make([]redmine.Issue, 0)
 	switch action.TriggerTransition.Who {
 	case settings.TriggerTransitionWhoChildren:
+		children, err := c.model.APIGetChildren(issue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get redmine children issue err: %v", err)
+		}
+		log.Printf("Children found for %q %d: %d\n", issue.Tracker.Name, issue.Id, len(children))
 		if len(children) == 0 {
 			log.Printf("Should transition children to %q but no children to work with (%q %d)\n", nextIssueStatus.Name, issue.Tracker.Name, issue.Id)
-			return nil
-		}
-		for _, child := range children {
-			childState := workflow.States.Get(settings.StateName(child.Status.Name))
-			log.Printf("Transitioning child %q %d - %q -> %q\n", child.Tracker.Name, child.Id, childState.Name, nextIssueStatus.Name)
-		}
-		for _, child := range children {
-			err := model.Transition(child, nextIssueStatus)
-			if err != nil {
-				return fmt.Errorf("failed to transition issue err: %v", err)
-			}
-			log.Printf("Successfully moved %d to: %d - %s\n", child.Id, nextIssueStatus.Id, nextIssueStatus.Name)
-			// todo, check if this transition triggers something else
+			return nil, nil
 		}
+		targets = append(targets, children...)
 	case settings.TriggerTransitionWhoParent:
+		parent, err := c.model.APIGetParent(issue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get redmine parent issue err: %v", err)
+		}
 		if parent == nil {
 			log.Println("Should have transition parent, but no parent was found")
-			return nil
+			return nil, nil
+		}
+		targets = append(targets, *parent)
+	}
+
+	caused := make([]triggerEvent, 0)
+	for _, target := range targets {
+		if !action.TargetIssueTypeCheck(settings.IssueTypeName(target.Tracker.Name)) {
+			log.Printf("Skipping %q %d, trigger moves only %q\n", target.Tracker.Name, target.Id, action.TriggerTransition.IssueType)
+			continue
+		}
+		if target.Status != nil && target.Status.Id == nextIssueStatus.Id {
+			log.Printf("Issue %d is already in %q, skipping\n", target.Id, nextIssueStatus.Name)
+			continue
+		}
+		visitKey := triggerVisitKey(target.Id, nextIssueStatus.Id)
+		if c.visited[visitKey] {
+			log.Printf("Trigger cycle detected, issue %d was already moved to %q in this cascade, skipping\n", target.Id, nextIssueStatus.Name)
+			continue
 		}
-		parentState := workflow.States.Get(settings.StateName(parent.Status.Name))
-		log.Printf(
-			"Transitioning parent %q %d - %q -> %q\n",
-			parent.Tracker.Name,
-			parent.Id,
-			parentState.Name,
-			nextIssueStatus.Name,
-		)
-		err := model.Transition(*parent, nextIssueStatus)
+		c.visited[visitKey] = true
+
+		targetState := c.workflow.States.Get(settings.StateName(target.Status.Name))
+		log.Printf("Transitioning %s %q %d - %q -> %q\n", action.TriggerTransition.Who, target.Tracker.Name, target.Id, targetState.Name, nextIssueStatus.Name)
+
+		comment := triggerAuditComment(action, event, target, nextIssueStatus)
+		err = c.model.TransitionWithComment(target, nextIssueStatus, comment)
 		if err != nil {
-			return fmt.Errorf("failed to transition issue err: %v", err)
+			return nil, fmt.Errorf("failed to transition issue err: %v", err)
+		}
+		log.Printf("Successfully moved %d to: %d - %s\n", target.Id, nextIssueStatus.Id, nextIssueStatus.Name)
+
+		statusFrom := redmine.IssueStatus{}
+		if target.Status != nil {
+			statusFrom = redmine.IssueStatus{Id: target.Status.Id, Name: target.Status.Name}
 		}
+		target.Status = &redmine.IdName{Id: nextIssueStatus.Id, Name: nextIssueStatus.Name}
+		caused = append(caused, triggerEvent{
+			issue:      target,
+			statusFrom: statusFrom,
+			statusTo:   nextIssueStatus,
+			depth:      event.depth + 1,
+		})
 	}
-	return nil
+
+	return caused, nil
+}
+
+func (c *triggerCascade) issueStatuses(issues []redmine.Issue) []settings.StateName {
+	statuses := make([]settings.StateName, 0, len(issues))
+	for _, issue := range issues {
+		if issue.Status == nil {
+			log.Printf("Failed to get status for %d\n", issue.Id)
+			continue
+		}
+		statuses = append(statuses, c.statusNames[issue.Status.Id])
+	}
+	return statuses
+}
+
+func customFieldValues(issue redmine.Issue) map[string]string {
+	values := make(map[string]string)
+	for _, field := range issue.CustomFields {
+		if field == nil || field.Value == nil {
+			continue
+		}
+		values[field.Name] = fmt.Sprint(field.Value)
+	}
+	return values
+}
+
+func triggerVisitKey(issueID, statusID int) string {
+	return fmt.Sprintf("%d:%d", issueID, statusID)
+}
+
+// triggerDepthComment explains why triggers of issue were not applied, trigger journals are not scanned again later.
+func triggerDepthComment(maxDepth int, event triggerEvent) string {
+	return fmt.Sprintf(
+		"%s: cascade stopped, max depth (%d) reached. Triggers for move to %q were not applied. "+
+			"Apply remaining transitions manually or raise `workflow.trigger_max_depth`.",
+		triggerCommentPrefix,
+		maxDepth,
+		event.statusTo.Name,
+	)
+}
+
+func triggerAuditComment(action settings.TriggerIf, event triggerEvent, target redmine.Issue, nextStatus redmine.IssueStatus) string {
+	fromStatus := ""
+	if target.Status != nil {
+		fromStatus = target.Status.Name
+	}
+	return fmt.Sprintf(
+		"%s: moved from %q to %q because %s #%d was moved to %q.\n\nRule: `%s`\nCascade depth: %d",
+		triggerCommentPrefix,
+		fromStatus,
+		nextStatus.Name,
+		event.issue.Tracker.Name,
+		event.issue.Id,
+		event.statusTo.Name,
+		action.String(),
+		event.depth+1,
+	)
 }
@@ -0,0 +1,236 @@
+package work
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	model "github.com/andrejsstepanovs/andai/internal/redmine"
+	"github.com/andrejsstepanovs/andai/internal/redmine/mocks"
+	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/mattn/go-redmine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	querySettings      = regexp.QuoteMeta("SELECT id, name, value FROM settings WHERE name = ?")
+	queryUpdateSetting = regexp.QuoteMeta("UPDATE settings SET value = ?, updated_on = NOW() WHERE name = ?")
+	queryInsertSetting = regexp.QuoteMeta("INSERT INTO settings (name, value, updated_on) VALUES (?, ?, NOW())")
+	queryStatusChanges = regexp.QuoteMeta("INNER JOIN journals B ON A.journal_id = B.id WHERE A.property = ? AND A.prop_key = ? AND B.journalized_type = ? AND B.id > ?")
+	queryLastJournal   = regexp.QuoteMeta("SELECT COALESCE(MAX(id), 0) FROM journals")
+)
+
+func checkpointRows(value string) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "name", "value"}).AddRow(1, model.SettingTriggersCheckpoint, value)
+}
+
+func expectCheckpointSaved(db sqlmock.Sqlmock, value string) {
+	db.ExpectQuery(querySettings).WithArgs(model.SettingTriggersCheckpoint).WillReturnRows(checkpointRows("x"))
+	db.ExpectExec(queryUpdateSetting).WithArgs(value, model.SettingTriggersCheckpoint).WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestProcessTriggers_FirstRun(t *testing.T) {
+	conn, db, err := sqlmock.New()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	db.ExpectQuery(querySettings).WithArgs(model.SettingTriggersCheckpoint).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "value"}))
+	db.ExpectQuery(queryLastJournal).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+	db.ExpectQuery(querySettings).WithArgs(model.SettingTriggersCheckpoint).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "value"}))
+	db.ExpectExec(queryInsertSetting).WithArgs(model.SettingTriggersCheckpoint, "42").WillReturnResult(sqlmock.NewResult(1, 1))
+
+	api := mocks.NewAPIInterface(t)
+	err = processTriggers(model.NewModel(conn, api), settings.Workflow{})
+	require.NoError(t, err)
+	assert.NoError(t, db.ExpectationsWereMet())
+}
+
+func TestProcessTriggers_AllChangesSinceCheckpoint(t *testing.T) {
+	conn, db, err := sqlmock.New()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	db.ExpectQuery(querySettings).WithArgs(model.SettingTriggersCheckpoint).WillReturnRows(checkpointRows("10"))
+	db.ExpectQuery(queryStatusChanges).
+		WithArgs(model.JournalPropertyAttr, model.JournalStatusID, model.JournalIssueType, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "issue", "from", "to", "notes", "ago"}).
+			AddRow(11, 5, 1, 2, triggerCommentPrefix+" moved issue", 30).
+			AddRow(12, 5, 2, 3, "", 20).
+			AddRow(13, 6, 1, 3, "", 10))
+	// every change moves checkpoint, trigger made change is skipped but still passed
+	expectCheckpointSaved(db, "11")
+	expectCheckpointSaved(db, "12")
+	expectCheckpointSaved(db, "13")
+
+	api := mocks.NewAPIInterface(t)
+	api.On("Issue", 5).Return(nil, errors.New("Not Found")).Once()
+	api.On("Issue", 6).Return(nil, errors.New("Not Found")).Once()
+
+	err = processTriggers(model.NewModel(conn, api), settings.Workflow{})
+	require.NoError(t, err)
+	assert.NoError(t, db.ExpectationsWereMet())
+}
+
+func TestProcessTriggers_StopsOnFailure(t *testing.T) {
+	conn, db, err := sqlmock.New()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	db.ExpectQuery(querySettings).WithArgs(model.SettingTriggersCheckpoint).WillReturnRows(checkpointRows("10"))
+	db.ExpectQuery(queryStatusChanges).
+		WithArgs(model.JournalPropertyAttr, model.JournalStatusID, model.JournalIssueType, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "issue", "from", "to", "notes", "ago"}).
+			AddRow(11, 5, 1, 2, "", 20).
+			AddRow(12, 6, 1, 2, "", 10))
+	expectCheckpointSaved(db, "11")
+
+	api := mocks.NewAPIInterface(t)
+	api.On("Issue", 5).Return(nil, errors.New("Not Found")).Once()
+	api.On("Issue", 6).Return(nil, errors.New("timeout")).Once()
+
+	err = processTriggers(model.NewModel(conn, api), settings.Workflow{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "journal id=12")
+	assert.NoError(t, db.ExpectationsWereMet(), "checkpoint stays at last processed change")
+}
+
+func TestProcessTriggers_ZeroCheckpoint(t *testing.T) {
+	conn, db, err := sqlmock.New()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// checkpoint saved while redmine had no journals is not a first run
+	db.ExpectQuery(querySettings).WithArgs(model.SettingTriggersCheckpoint).WillReturnRows(checkpointRows("0"))
+	db.ExpectQuery(queryStatusChanges).
+		WithArgs(model.JournalPropertyAttr, model.JournalStatusID, model.JournalIssueType, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "issue", "from", "to", "notes", "ago"}).
+			AddRow(1, 5, 1, 2, "", 10))
+	expectCheckpointSaved(db, "1")
+
+	api := mocks.NewAPIInterface(t)
+	api.On("Issue", 5).Return(nil, errors.New("Not Found")).Once()
+
+	err = processTriggers(model.NewModel(conn, api), settings.Workflow{})
+	require.NoError(t, err)
+	assert.NoError(t, db.ExpectationsWereMet())
+}
+
+func TestProcessTriggers_ChangeFiresTrigger(t *testing.T) {
+	conn, db, err := sqlmock.New()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	db.ExpectQuery(querySettings).WithArgs(model.SettingTriggersCheckpoint).WillReturnRows(checkpointRows("10"))
+	db.ExpectQuery(queryStatusChanges).
+		WithArgs(model.JournalPropertyAttr, model.JournalStatusID, model.JournalIssueType, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "issue", "from", "to", "notes", "ago"}).
+			AddRow(11, 3, 2, 3, "", 10))
+	expectCheckpointSaved(db, "11")
+
+	api, issues := cascadeFixture(t)
+	subTask, parent := issues[3], issues[2]
+	subTask.Status = &redmine.IdName{Id: 3, Name: "Done"}
+	api.On("Issue", 3).Return(&subTask, nil).Once()
+	api.On("Issue", 2).Return(&parent, nil).Once()
+	expectTriggerMove(api, 2, 3)
+
+	workflow := settings.Workflow{Triggers: settings.Triggers{
+		moveToParent("Sub-Task", "Done", "Done"),
+	}}
+	err = processTriggers(model.NewModel(conn, api), workflow)
+	require.NoError(t, err)
+	assert.NoError(t, db.ExpectationsWereMet())
+}
+
+func cascadeFixture(t *testing.T) (*mocks.APIInterface, map[int]redmine.Issue) {
+	t.Helper()
+	api := mocks.NewAPIInterface(t)
+	api.On("IssueStatuses").Return([]redmine.IssueStatus{{Id: 1, Name: "New"}, {Id: 2, Name: "In Progress"}, {Id: 3, Name: "Done"}}, nil).Maybe()
+
+	project := &redmine.IdName{Id: 1}
+	newStatus := &redmine.IdName{Id: 1, Name: "New"}
+	issues := map[int]redmine.Issue{
+		1: {Id: 1, Project: project, Tracker: &redmine.IdName{Name: "Story"}, Status: newStatus},
+		2: {Id: 2, Project: project, Tracker: &redmine.IdName{Name: "Task"}, Status: newStatus, Parent: &redmine.Id{Id: 1}},
+		3: {Id: 3, Project: project, Tracker: &redmine.IdName{Name: "Sub-Task"}, Status: newStatus, Parent: &redmine.Id{Id: 2}},
+	}
+	return api, issues
+}
+
+func expectTriggerMove(api *mocks.APIInterface, issueID, statusID int) {
+	api.On("UpdateIssue", mock.MatchedBy(func(issue redmine.Issue) bool {
+		return issue.Id == issueID && issue.StatusId == statusID && strings.HasPrefix(issue.Notes, triggerCommentPrefix+": moved")
+	})).Return(nil).Once()
+}
+
+func moveToParent(issueType settings.IssueTypeName, movedTo, to settings.StateName) settings.Trigger {
+	return settings.Trigger{IssueType: issueType, TriggerIf: []settings.TriggerIf{{
+		MovedTo:           movedTo,
+		TriggerTransition: settings.TriggerTransition{Who: settings.TriggerTransitionWhoParent, To: to},
+	}}}
+}
+
+func TestTriggerCascade_TwoLevels(t *testing.T) {
+	api, issues := cascadeFixture(t)
+	parent, grandParent := issues[2], issues[1]
+	api.On("Issue", 2).Return(&parent, nil).Once()
+	api.On("Issue", 1).Return(&grandParent, nil).Once()
+	expectTriggerMove(api, 2, 3)
+	expectTriggerMove(api, 1, 3)
+
+	workflow := settings.Workflow{Triggers: settings.Triggers{
+		moveToParent("Sub-Task", "Done", "Done"),
+		moveToParent("Task", "Done", "Done"),
+	}}
+	cascade, err := newTriggerCascade(model.NewModel(nil, api), workflow)
+	require.NoError(t, err)
+
+	err = cascade.Run(triggerEvent{issue: issues[3], statusFrom: redmine.IssueStatus{Id: 2, Name: "In Progress"}, statusTo: redmine.IssueStatus{Id: 3, Name: "Done"}})
+	require.NoError(t, err)
+}
+
+func TestTriggerCascade_Cycle(t *testing.T) {
+	api, issues := cascadeFixture(t)
+	// api still returns parent in old state, only visited guard stops moving it again
+	parent := issues[2]
+	api.On("IssuesOf", 1).Return([]redmine.Issue{issues[1], issues[2], issues[3]}, nil).Once()
+	api.On("Issue", 2).Return(&parent, nil).Once()
+	expectTriggerMove(api, 3, 2)
+
+	workflow := settings.Workflow{Triggers: settings.Triggers{
+		{IssueType: "Task", TriggerIf: []settings.TriggerIf{{
+			MovedTo:           "In Progress",
+			TriggerTransition: settings.TriggerTransition{Who: settings.TriggerTransitionWhoChildren, To: "In Progress"},
+		}}},
+		moveToParent("Sub-Task", "In Progress", "In Progress"),
+	}}
+	cascade, err := newTriggerCascade(model.NewModel(nil, api), workflow)
+	require.NoError(t, err)
+
+	err = cascade.Run(triggerEvent{issue: issues[2], statusFrom: redmine.IssueStatus{Id: 1, Name: "New"}, statusTo: redmine.IssueStatus{Id: 2, Name: "In Progress"}})
+	require.NoError(t, err)
+}
+
+func TestTriggerCascade_MaxDepth(t *testing.T) {
+	api, issues := cascadeFixture(t)
+	parent := issues[2]
+	api.On("Issue", 2).Return(&parent, nil).Once()
+	expectTriggerMove(api, 2, 3)
+	api.On("UpdateIssue", mock.MatchedBy(func(issue redmine.Issue) bool {
+		return issue.Id == 2 && strings.Contains(issue.Notes, "max depth (1) reached")
+	})).Return(nil).Once()
+
+	workflow := settings.Workflow{TriggerMaxDepth: 1, Triggers: settings.Triggers{
+		moveToParent("Sub-Task", "Done", "Done"),
+		moveToParent("Task", "Done", "Done"),
+	}}
+	cascade, err := newTriggerCascade(model.NewModel(nil, api), workflow)
+	require.NoError(t, err)
+
+	err = cascade.Run(triggerEvent{issue: issues[3], statusFrom: redmine.IssueStatus{Id: 2, Name: "In Progress"}, statusTo: redmine.IssueStatus{Id: 3, Name: "Done"}})
+	require.NoError(t, err)
+}
@@ -0,0 +1,209 @@
+package work
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/andrejsstepanovs/andai/internal"
+	model "github.com/andrejsstepanovs/andai/internal/redmine"
+	redminemodels "github.com/andrejsstepanovs/andai/internal/redmine/models"
+	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/mattn/go-redmine"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// watchdogCommentPrefix marks escalation comments left by watchdog.
+	watchdogCommentPrefix = "**Watchdog**"
+	// watchdogRecentComments is how many latest issue comments are quoted in escalation comment.
+	watchdogRecentComments = 5
+	// watchdogCommentMaxLength cuts quoted comments (in characters) so escalation comment stays readable.
+	watchdogCommentMaxLength = 300
+)
+
+func newWatchdogCommand(deps internal.DependenciesLoader) *cobra.Command {
+	var project string
+	cmd := &cobra.Command{
+		Use:   "watchdog",
+		Short: "Moves issues that are stuck in a state for too long (or too often) to escalation state. [OPTIONAL...] --project <identifier>",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			d := deps()
+			params, err := d.Config.Load()
+			if err != nil {
+				return err
+			}
+
+			projects, err := getProjects(d, project)
+			if err != nil {
+				return fmt.Errorf("failed to get projects: %v", err)
+			}
+
+			log.Println("Starting watchdog check")
+			return processWatchdog(d.Model, params.Workflow, projects)
+		},
+	}
+	cmd.Flags().StringVar(&project, "project", "", "Project identifier (optional)")
+	return cmd
+}
+
+// stateStay describes how issue got into its current state.
+type stateStay struct {
+	Visits   int           // how many times issue entered current state (including creation) since it left escalation state
+	Duration time.Duration // how long issue is in current state
+}
+
+// getStateStay counts visits and time spent in current state using issue status history (oldest first).
+// Visits before last move out of escalation state are not counted, so issue that was moved back by human
+// is not escalated again right away.
+func getStateStay(changes redminemodels.StatusChanges, currentStatusID, escalationStatusID int, issueAge time.Duration) stateStay {
+	if len(changes) == 0 {
+		return stateStay{Visits: 1, Duration: issueAge}
+	}
+
+	stay := stateStay{}
+	counted := changes
+	for n := len(changes) - 1; n >= 0; n-- {
+		if changes[n].StatusIDFrom == escalationStatusID {
+			counted = changes[n:]
+			break
+		}
+	}
+	if len(counted) == len(changes) && changes[0].StatusIDFrom == currentStatusID {
+		stay.Visits++ // issue was created in this state
+	}
+	for _, change := range counted {
+		if change.StatusIDTo == currentStatusID {
+			stay.Visits++
+		}
+	}
+	stay.Duration = time.Duration(changes[len(changes)-1].SecondsAgo) * time.Second
+
+	return stay
+}
+
+// exceededLimits returns human readable list of state limits issue went over. Empty if none.
+func exceededLimits(state settings.State, stay stateStay) []string {
+	reasons := make([]string, 0)
+	if state.MaxVisits > 0 && stay.Visits > state.MaxVisits {
+		reasons = append(reasons, fmt.Sprintf("entered %q %d times (max_visits: %d)", state.Name, stay.Visits, state.MaxVisits))
+	}
+	if maxDuration := state.GetMaxDuration(); maxDuration > 0 && stay.Duration > maxDuration {
+		reasons = append(reasons, fmt.Sprintf("is in %q for %s (max_duration: %s)", state.Name, stay.Duration.Round(time.Second), state.MaxDuration))
+	}
+	return reasons
+}
+
+func processWatchdog(model *model.Model, workflow settings.Workflow, projects []redmine.Project) error {
+	if workflow.EscalationState == "" {
+		return nil
+	}
+
+	escalationStatus, err := model.APIGetIssueStatus(string(workflow.EscalationState))
+	if err != nil {
+		return fmt.Errorf("failed to get escalation status %q err: %v", workflow.EscalationState, err)
+	}
+
+	statuses, err := model.API().IssueStatuses()
+	if err != nil {
+		return fmt.Errorf("failed to get issue statuses err: %v", err)
+	}
+	statusNames := make(map[int]string, len(statuses))
+	for _, status := range statuses {
+		statusNames[status.Id] = status.Name
+	}
+
+	for _, project := range projects {
+		issues, err := model.APIGetProjectIssues(project)
+		if err != nil {
+			return fmt.Errorf("failed to get project %q issues err: %v", project.Identifier, err)
+		}
+
+		for _, issue := range issues {
+			if issue.Status == nil {
+				continue
+			}
+			state := workflow.States.Get(settings.StateName(issue.Status.Name))
+			if !state.HasLimits() || state.Name == workflow.EscalationState {
+				continue
+			}
+
+			err = watchIssue(model, issue, state, escalationStatus, statusNames)
+			if err != nil {
+				return fmt.Errorf("failed to check issue %d err: %v", issue.Id, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func watchIssue(model *model.Model, issue redmine.Issue, state settings.State, escalationStatus redmine.IssueStatus, statusNames map[int]string) error {
+	changes, err := model.DBGetIssueStatusChanges(issue.Id)
+	if err != nil {
+		return fmt.Errorf("failed to get status changes err: %v", err)
+	}
+	issueAge, err := model.DBGetIssueAge(issue.Id)
+	if err != nil {
+		return fmt.Errorf("failed to get issue age err: %v", err)
+	}
+
+	stay := getStateStay(changes, issue.Status.Id, escalationStatus.Id, time.Duration(issueAge)*time.Second)
+	reasons := exceededLimits(state, stay)
+	if len(reasons) == 0 {
+		return nil
+	}
+	log.Printf("Watchdog: issue %d %s. Moving to %q\n", issue.Id, strings.Join(reasons, ", "), escalationStatus.Name)
+
+	comments, err := model.DBGetComments(issue.Id)
+	if err != nil {
+		return fmt.Errorf("failed to get comments err: %v", err)
+	}
+
+	summary := watchdogSummary(reasons, escalationStatus, changes, comments, statusNames)
+	err = model.TransitionWithComment(issue, escalationStatus, summary)
+	if err != nil {
+		return fmt.Errorf("failed to move issue to escalation state err: %v", err)
+	}
+	log.Printf("Successfully escalated issue (%d) to: %q", issue.Id, escalationStatus.Name)
+
+	return nil
+}
+
+func watchdogSummary(
+	reasons []string,
+	escalationStatus redmine.IssueStatus,
+	changes redminemodels.StatusChanges,
+	comments redminemodels.Comments,
+	statusNames map[int]string,
+) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: issue %s. Moved to %q, needs human attention.\n", watchdogCommentPrefix, strings.Join(reasons, " and "), escalationStatus.Name)
+
+	if len(changes) > 0 {
+		b.WriteString("\nStatus history:\n")
+		for _, change := range changes {
+			ago := (time.Duration(change.SecondsAgo) * time.Second).Round(time.Minute)
+			fmt.Fprintf(&b, "- %s -> %s (%s ago)\n", statusNames[change.StatusIDFrom], statusNames[change.StatusIDTo], ago)
+		}
+	}
+
+	if len(comments) > 0 {
+		b.WriteString("\nRecent comments:\n")
+		start := len(comments) - watchdogRecentComments
+		if start < 0 {
+			start = 0
+		}
+		for _, comment := range comments[start:] {
+			text := strings.TrimSpace(comment.Text)
+			if runes := []rune(text); len(runes) > watchdogCommentMaxLength {
+				text = string(runes[:watchdogCommentMaxLength]) + "..."
+			}
+			text = strings.ReplaceAll(text, "\n", " ")
+			fmt.Fprintf(&b, "- #%d (%s): %s\n", comment.Number, comment.CreatedAt, text)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,199 @@
+package work
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	model "github.com/andrejsstepanovs/andai/internal/redmine"
+	"github.com/andrejsstepanovs/andai/internal/redmine/mocks"
+	redminemodels "github.com/andrejsstepanovs/andai/internal/redmine/models"
+	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/mattn/go-redmine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStateStay(t *testing.T) {
+	const inProgress, review, escalated = 2, 3, 9
+
+	tests := []struct {
+		name     string
+		changes  redminemodels.StatusChanges
+		status   int
+		age      time.Duration
+		expected stateStay
+	}{
+		{
+			name:     "never moved",
+			status:   inProgress,
+			age:      time.Hour,
+			expected: stateStay{Visits: 1, Duration: time.Hour},
+		},
+		{
+			name:     "moved once",
+			changes:  redminemodels.StatusChanges{{StatusIDFrom: 1, StatusIDTo: inProgress, SecondsAgo: 60}},
+			status:   inProgress,
+			age:      time.Hour,
+			expected: stateStay{Visits: 1, Duration: time.Minute},
+		},
+		{
+			name: "created in state and came back",
+			changes: redminemodels.StatusChanges{
+				{StatusIDFrom: inProgress, StatusIDTo: review, SecondsAgo: 600},
+				{StatusIDFrom: review, StatusIDTo: inProgress, SecondsAgo: 300},
+			},
+			status:   inProgress,
+			age:      time.Hour,
+			expected: stateStay{Visits: 2, Duration: 5 * time.Minute},
+		},
+		{
+			name: "bounced between states",
+			changes: redminemodels.StatusChanges{
+				{StatusIDFrom: 1, StatusIDTo: inProgress, SecondsAgo: 900},
+				{StatusIDFrom: inProgress, StatusIDTo: review, SecondsAgo: 800},
+				{StatusIDFrom: review, StatusIDTo: inProgress, SecondsAgo: 700},
+				{StatusIDFrom: inProgress, StatusIDTo: review, SecondsAgo: 600},
+				{StatusIDFrom: review, StatusIDTo: inProgress, SecondsAgo: 30},
+			},
+			status:   inProgress,
+			age:      time.Hour,
+			expected: stateStay{Visits: 3, Duration: 30 * time.Second},
+		},
+		{
+			name: "human moves it back from escalation",
+			changes: redminemodels.StatusChanges{
+				{StatusIDFrom: 1, StatusIDTo: inProgress, SecondsAgo: 900},
+				{StatusIDFrom: inProgress, StatusIDTo: review, SecondsAgo: 800},
+				{StatusIDFrom: review, StatusIDTo: inProgress, SecondsAgo: 700},
+				{StatusIDFrom: inProgress, StatusIDTo: escalated, SecondsAgo: 600},
+				{StatusIDFrom: escalated, StatusIDTo: inProgress, SecondsAgo: 30},
+			},
+			status:   inProgress,
+			age:      time.Hour,
+			expected: stateStay{Visits: 1, Duration: 30 * time.Second},
+		},
+		{
+			name: "visits after escalation are counted",
+			changes: redminemodels.StatusChanges{
+				{StatusIDFrom: inProgress, StatusIDTo: escalated, SecondsAgo: 900},
+				{StatusIDFrom: escalated, StatusIDTo: review, SecondsAgo: 800},
+				{StatusIDFrom: review, StatusIDTo: inProgress, SecondsAgo: 700},
+				{StatusIDFrom: inProgress, StatusIDTo: review, SecondsAgo: 600},
+				{StatusIDFrom: review, StatusIDTo: inProgress, SecondsAgo: 30},
+			},
+			status:   inProgress,
+			age:      time.Hour,
+			expected: stateStay{Visits: 2, Duration: 30 * time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, getStateStay(tt.changes, tt.status, escalated, tt.age))
+		})
+	}
+}
+
+func TestWatchIssue_MovedBackFromEscalation(t *testing.T) {
+	conn, db, err := sqlmock.New()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	queryIssueStatusChanges := regexp.QuoteMeta("INNER JOIN journals B ON A.journal_id = B.id WHERE A.property = ? AND A.prop_key = ? AND B.journalized_type = ? AND B.journalized_id = ?")
+	queryIssueAge := regexp.QuoteMeta("SELECT TIMESTAMPDIFF(SECOND, created_on, UTC_TIMESTAMP()) FROM issues WHERE id = ?")
+	db.ExpectQuery(queryIssueStatusChanges).
+		WithArgs(model.JournalPropertyAttr, model.JournalStatusID, model.JournalIssueType, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "issue", "from", "to", "notes", "ago"}).
+			AddRow(11, 5, 1, 2, "", 900).
+			AddRow(12, 5, 2, 3, "", 800).
+			AddRow(13, 5, 3, 2, "", 700).
+			AddRow(14, 5, 2, 9, watchdogCommentPrefix, 600).
+			AddRow(15, 5, 9, 2, "", 30))
+	db.ExpectQuery(queryIssueAge).WithArgs(5).WillReturnRows(sqlmock.NewRows([]string{"age"}).AddRow(3600))
+
+	// no api expectations, issue must not be transitioned
+	api := mocks.NewAPIInterface(t)
+	issue := redmine.Issue{Id: 5, Status: &redmine.IdName{Id: 2, Name: "In Progress"}}
+	state := settings.State{Name: "In Progress", MaxVisits: 1}
+
+	err = watchIssue(model.NewModel(conn, api), issue, state, redmine.IssueStatus{Id: 9, Name: "Escalated"}, map[int]string{})
+	require.NoError(t, err)
+	assert.NoError(t, db.ExpectationsWereMet())
+}
+
+func TestExceededLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		state    settings.State
+		stay     stateStay
+		expected []string
+	}{
+		{
+			name:     "no limits",
+			state:    settings.State{Name: "In Progress"},
+			stay:     stateStay{Visits: 10, Duration: 24 * time.Hour},
+			expected: []string{},
+		},
+		{
+			name:     "within limits",
+			state:    settings.State{Name: "In Progress", MaxVisits: 3, MaxDuration: "2h"},
+			stay:     stateStay{Visits: 3, Duration: 2 * time.Hour},
+			expected: []string{},
+		},
+		{
+			name:     "too many visits",
+			state:    settings.State{Name: "In Progress", MaxVisits: 3},
+			stay:     stateStay{Visits: 4},
+			expected: []string{`entered "In Progress" 4 times (max_visits: 3)`},
+		},
+		{
+			name:     "too long",
+			state:    settings.State{Name: "In Progress", MaxDuration: "2h"},
+			stay:     stateStay{Visits: 1, Duration: 2*time.Hour + 1500*time.Millisecond},
+			expected: []string{`is in "In Progress" for 2h0m2s (max_duration: 2h)`},
+		},
+		{
+			name:  "both",
+			state: settings.State{Name: "QA", MaxVisits: 1, MaxDuration: "30m"},
+			stay:  stateStay{Visits: 2, Duration: time.Hour},
+			expected: []string{
+				`entered "QA" 2 times (max_visits: 1)`,
+				`is in "QA" for 1h0m0s (max_duration: 30m)`,
+			},
+		},
+		{
+			name:     "invalid duration is ignored",
+			state:    settings.State{Name: "QA", MaxDuration: "soon"},
+			stay:     stateStay{Visits: 1, Duration: time.Hour},
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, exceededLimits(tt.state, tt.stay))
+		})
+	}
+}
+
+func TestWatchdogSummary(t *testing.T) {
+	statusNames := map[int]string{1: "New", 2: "In Progress", 9: "Escalated"}
+	changes := redminemodels.StatusChanges{{StatusIDFrom: 1, StatusIDTo: 2, SecondsAgo: 7200}}
+	comments := redminemodels.Comments{}
+	for n := 1; n <= 7; n++ {
+		comments = append(comments, redminemodels.Comment{Number: n, CreatedAt: "2026-01-01", Text: "note"})
+	}
+	comments[6].Text = "first line\n" + strings.Repeat("ž", watchdogCommentMaxLength+10)
+
+	summary := watchdogSummary([]string{"is stuck"}, redmine.IssueStatus{Id: 9, Name: "Escalated"}, changes, comments, statusNames)
+
+	assert.True(t, strings.HasPrefix(summary, watchdogCommentPrefix+`: issue is stuck. Moved to "Escalated"`))
+	assert.Contains(t, summary, "- New -> In Progress (2h0m0s ago)\n")
+	assert.NotContains(t, summary, "- #2 ")
+	assert.Contains(t, summary, "- #3 (2026-01-01): note\n")
+	assert.Contains(t, summary, "- #7 (2026-01-01): first line "+strings.Repeat("ž", watchdogCommentMaxLength-len("first line\n"))+"...\n")
+	assert.NotContains(t, summary, "�", "multi-byte characters are not split")
+}
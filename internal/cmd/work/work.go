@@ -2,10 +2,12 @@ package work
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -13,42 +15,110 @@ import (
 	"github.com/andrejsstepanovs/andai/internal/employee"
 	"github.com/andrejsstepanovs/andai/internal/employee/actions"
 	"github.com/andrejsstepanovs/andai/internal/exec"
+	"github.com/andrejsstepanovs/andai/internal/monitor"
+	redminemodels "github.com/andrejsstepanovs/andai/internal/redmine/models"
+	"github.com/andrejsstepanovs/andai/internal/runlog"
 	"github.com/andrejsstepanovs/andai/internal/settings"
 	"github.com/mattn/go-redmine"
 	"github.com/spf13/cobra"
 )
 
 func newLoopCommand(deps internal.DependenciesLoader) *cobra.Command {
-	var project string
+	var opts LoopOptions
 	cmd := &cobra.Command{
 		Use:   "loop",
-		Short: "Work forever. [OPTIONAL...] --project <identifier>",
+		Short: "Work forever. [OPTIONAL...] --project <identifier> --webhook <addr> --webhook-token <token> --listen <addr> --control-token <token> --once-idle",
 		RunE: func(_ *cobra.Command, _ []string) error {
 			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 			defer stop()
-			return Loop(ctx, deps, project)
+			return Loop(ctx, deps, opts)
 		},
 	}
-	cmd.Flags().StringVar(&project, "project", "", "Project identifier (optional)")
+	BindLoopFlags(cmd, &opts)
 	return cmd
 }
 
-// Loop runs work next loop forever
+// BindLoopFlags registers work loop options as command flags. Used by `work loop` and `go`.
+func BindLoopFlags(cmd *cobra.Command, opts *LoopOptions) {
+	flags := cmd.Flags()
+	flags.StringVar(&opts.Project, "project", "", "Project identifier (optional)")
+	flags.StringVar(&opts.Webhook, "webhook", "", "Listen for redmine webhooks on this address, e.g. :8088 (optional)")
+	flags.StringVar(&opts.WebhookToken, "webhook-token", "", "Require this token in "+WebhookTokenHeader+" header or ?token= query of webhook calls (optional)")
+	flags.StringVar(&opts.Listen, "listen", "", "Serve status API and dashboard on this address, e.g. :8089 (optional)")
+	flags.StringVar(&opts.ControlToken, "control-token", "", "Token that dashboard controls (pause, resume, skip) require. Random one is generated and logged if not set")
+	flags.DurationVar(&opts.DetectInterval, "detect-interval", DefaultDetectInterval, "How often to check redmine db for changes. 0 disables change detection")
+	flags.BoolVar(&opts.OnceIdle, "once-idle", false, "Exit as soon as there is no more work to do")
+}
+
+// LoopOptions configures work loop.
+type LoopOptions struct {
+	Project        string
+	Webhook        string        // address for webhook listener. Empty disables it.
+	WebhookToken   string        // token webhook calls must carry. Empty accepts any call.
+	Listen         string        // address for status API and dashboard. Empty disables it.
+	ControlToken   string        // token dashboard controls must carry. Empty generates random one.
+	DetectInterval time.Duration // redmine db change detector interval. Zero disables it.
+	OnceIdle       bool          // exit when no workable issues are left
+}
+
+// Loop runs work next loop forever.
+// Sleeps when there is nothing to do, but wakes up right away on webhook call or when redmine db changes.
 // TODO fix this
 //
-//nolint:cyclop
-func Loop(ctx context.Context, deps internal.DependenciesLoader, project string) error {
+//nolint:cyclop,gocognit
+func Loop(ctx context.Context, deps internal.DependenciesLoader, opts LoopOptions) error {
 	lastSuccessfulTask := time.Now()
 	consecutiveEmptyChecks := 0
 	currentSleepDuration := time.Duration(0)
+	pausedLogged := false
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	wake := newWakeup()
+	serverErr := make(chan error, 2)
+	if opts.Webhook != "" {
+		go func() {
+			serverErr <- listenWebhook(ctx, opts.Webhook, opts.WebhookToken, wake)
+		}()
+	}
+	if opts.Listen != "" {
+		go func() {
+			serverErr <- listenStatus(ctx, opts.Listen, opts.WebhookToken, opts.ControlToken, monitor.Default, wake)
+		}()
+	}
+	if opts.DetectInterval > 0 {
+		go detectChanges(ctx, deps().Model, opts.DetectInterval, wake)
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
 			log.Println("Received interrupt signal, exiting work loop.")
 			return nil
+		case err := <-serverErr:
+			if err != nil {
+				return fmt.Errorf("http listener failed err: %v", err)
+			}
 		default:
 		}
+		wake.Drain()
+
+		if monitor.Default.Paused() {
+			if !pausedLogged {
+				log.Println("Work loop is paused, waiting for resume.")
+				pausedLogged = true
+			}
+			select {
+			case <-ctx.Done():
+				log.Println("Received interrupt signal, exiting work loop.")
+				return nil
+			case <-wake.C():
+			case <-time.After(time.Minute):
+			}
+			continue
+		}
+		pausedLogged = false
 
 		d := deps()
 		params, err := d.Config.Load()
@@ -60,10 +130,14 @@ func Loop(ctx context.Context, deps internal.DependenciesLoader, project string)
 		if err != nil {
 			return fmt.Errorf("failed to process triggers err: %v", err)
 		}
-		projects, err := getProjects(d, project)
+		projects, err := getProjects(d, opts.Project)
 		if err != nil {
 			return fmt.Errorf("failed to get projects: %v", err)
 		}
+		err = processWatchdog(d.Model, params.Workflow, projects)
+		if err != nil {
+			return fmt.Errorf("failed to process watchdog err: %v", err)
+		}
 		log.Printf("Searching workable issues (in %d projects)", len(projects))
 
 		wasWorking, err := workNext(d, params, projects)
@@ -79,6 +153,10 @@ func Loop(ctx context.Context, deps internal.DependenciesLoader, project string)
 			}
 			currentSleepDuration = time.Duration(0) // Reset sleep duration after work
 		} else {
+			if opts.OnceIdle {
+				log.Println("No workable issues found, exiting work loop (--once-idle).")
+				return nil
+			}
 			consecutiveEmptyChecks++
 			timeSinceLastSuccess := time.Since(lastSuccessfulTask)
 
@@ -121,6 +199,15 @@ func Loop(ctx context.Context, deps internal.DependenciesLoader, project string)
 				case <-ctx.Done():
 					log.Println("Received interrupt signal during sleep, exiting work loop.")
 					return nil
+				case err := <-serverErr:
+					if err != nil {
+						return fmt.Errorf("http listener failed err: %v", err)
+					}
+				case reason := <-wake.C():
+					log.Printf("Woken up by %s", reason)
+					lastSuccessfulTask = time.Now()
+					consecutiveEmptyChecks = 0
+					currentSleepDuration = 0
 				case <-time.After(currentSleepDuration):
 				}
 				continue
@@ -192,12 +279,59 @@ func getFirstWorkableIssuePerProjects(issues []redmine.Issue) []redmine.Issue {
 	return workableProjectIssues
 }
 
+// issueQueue reports workable and blocked issues to monitor, with a reason why they are (not) going to be worked on.
+func issueQueue(workflow settings.Workflow, workable []redmine.Issue, blocked []redminemodels.BlockedIssue) []monitor.QueueItem {
+	items := make([]monitor.QueueItem, 0, len(workable)+len(blocked))
+	queueItem := func(issue redmine.Issue, reason string) monitor.QueueItem {
+		item := monitor.QueueItem{ID: issue.Id, Subject: issue.Subject, Reason: reason}
+		if issue.Tracker != nil {
+			item.Type = issue.Tracker.Name
+		}
+		if issue.Status != nil {
+			item.State = issue.Status.Name
+		}
+		if issue.Project != nil {
+			item.Project = issue.Project.Name
+		}
+		return item
+	}
+
+	for _, issue := range workable {
+		reason := "ready"
+		state := workflow.States.Get(settings.StateName(issue.Status.Name))
+		switch {
+		case monitor.Default.IsSkipped(issue.Id):
+			reason = "skipped"
+		case !state.UseAI.Yes(settings.IssueTypeName(issue.Tracker.Name)):
+			reason = "waiting on user"
+		}
+		items = append(items, queueItem(issue, reason))
+	}
+	for _, b := range blocked {
+		ids := make([]string, 0, len(b.BlockedBy))
+		for _, id := range b.BlockedBy {
+			ids = append(ids, fmt.Sprintf("#%d", id))
+		}
+		items = append(items, queueItem(b.Issue, "blocked by "+strings.Join(ids, ", ")))
+	}
+	return items
+}
+
 func workNext(deps *internal.AppDependencies, params *settings.Settings, projects []redmine.Project) (bool, error) {
-	issues, err := deps.Model.APIGetWorkableIssues(params.Workflow, projects)
+	allIssues, blocked, err := deps.Model.APIGetIssueQueue(params.Workflow, projects)
 	if err != nil {
 		log.Println("Failed to get workable issue")
 		return false, err
 	}
+	monitor.Default.SetQueue(issueQueue(params.Workflow, allIssues, blocked))
+
+	issues := make([]redmine.Issue, 0, len(allIssues))
+	for _, issue := range allIssues {
+		if monitor.Default.IsSkipped(issue.Id) {
+			continue
+		}
+		issues = append(issues, issue)
+	}
 
 	for _, issue := range getFirstWorkableIssuePerProjects(issues) {
 		// check if AI is allowed to work on it
@@ -277,8 +411,10 @@ func workNext(deps *internal.AppDependencies, params *settings.Settings, project
 		log.Printf("Project Repository Opened %s", git.GetPath())
 
 		wb := &exec.Workbench{
-			Git:   git,
-			Issue: issue,
+			Git:            git,
+			Issue:          issue,
+			Project:        projectConfig.Identifier,
+			BranchTemplate: projectConfig.BranchTemplate,
 		}
 
 		work := employee.NewRoutine(
@@ -299,7 +435,35 @@ func workNext(deps *internal.AppDependencies, params *settings.Settings, project
 			params.Workflow.IssueTypes,
 			projectRepo,
 		)
+		runLog, err := runlog.Start(params.Logs.GetDir(), issue.Id)
+		if err != nil {
+			log.Printf("Failed to start run log, step output will not be saved: %v", err)
+		} else {
+			log.Printf("Run log: %s", runLog.Path())
+			work.SetRunLog(runLog)
+		}
+
+		monitor.Default.StartIssue(monitor.Issue{
+			ID:      issue.Id,
+			Subject: issue.Subject,
+			Type:    issue.Tracker.Name,
+			State:   issue.Status.Name,
+			Project: project.Name,
+		})
 		success, err := work.ExecuteWorkflow()
+		monitor.Default.FinishIssue()
+		if runLog != nil {
+			if closeErr := runLog.Close(); closeErr != nil {
+				log.Printf("Failed to close run log: %v", closeErr)
+			}
+			if params.Logs.Comment {
+				attachRunLog(deps.Model, issue.Id, runLog.Path())
+			}
+		}
+		if errors.Is(err, employee.ErrSkipped) {
+			log.Printf("Issue (%d) was skipped, leaving it in %q", issue.Id, issue.Status.Name)
+			return true, nil
+		}
 		if err != nil {
 			return false, fmt.Errorf("failed to finish work on issue err: %v", err)
 		}
@@ -0,0 +1,24 @@
+package codeindex
+
+import (
+	"github.com/andrejsstepanovs/andai/internal/repofiles"
+)
+
+// CacheDir returns default directory for cached indexes.
+func CacheDir() string {
+	return repofiles.CacheDir("repo-map")
+}
+
+// Load returns cached index of project if it was built for given commit, otherwise nil.
+func Load(dir, project, sha string) *Index {
+	var index Index
+	if err := repofiles.Load(dir, project, &index); err != nil || index.SHA != sha {
+		return nil
+	}
+	return &index
+}
+
+// Save stores project index in cache directory. Only latest index of project is kept.
+func Save(dir, project string, index *Index) error {
+	return repofiles.Save(dir, project, index)
+}
@@ -0,0 +1,158 @@
+// Package codeindex builds compact map of repository symbols (packages, types, functions, methods with file:line)
+// so LLM can ask for the right files. Go is parsed with go/parser, other languages use ctags-like regular expressions.
+package codeindex
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/andrejsstepanovs/andai/internal/repofiles"
+)
+
+// Symbol is single named declaration.
+type Symbol struct {
+	Kind string `json:"kind"` // type, struct, interface, func, method, class, def, ...
+	Name string `json:"name"` // methods are "Type.Method"
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// Package is directory (Go package) with its symbols.
+type Package struct {
+	Dir     string   `json:"dir"`
+	Name    string   `json:"name"` // Go package name, empty for other languages
+	Symbols []Symbol `json:"symbols"`
+}
+
+// Index is symbol map of repository at commit.
+type Index struct {
+	SHA      string    `json:"sha"`
+	Packages []Package `json:"packages"`
+}
+
+// Build indexes files (relative to root). Files that can not be read or parsed are skipped.
+func Build(root, sha string, files []string) *Index {
+	packages := make(map[string]*Package)
+	for _, file := range files {
+		if !indexable(file) {
+			continue
+		}
+		src, err := repofiles.Read(root, file)
+		if err != nil {
+			continue
+		}
+
+		var pkgName string
+		var symbols []Symbol
+		if strings.HasSuffix(file, ".go") {
+			pkgName, symbols = parseGo(file, src)
+		} else {
+			symbols = parseRegex(file, src)
+		}
+		if len(symbols) == 0 && pkgName == "" {
+			continue
+		}
+
+		dir := path.Dir(file)
+		pkg, ok := packages[dir]
+		if !ok {
+			pkg = &Package{Dir: dir}
+			packages[dir] = pkg
+		}
+		if pkg.Name == "" {
+			pkg.Name = pkgName
+		}
+		pkg.Symbols = append(pkg.Symbols, symbols...)
+	}
+
+	index := &Index{SHA: sha, Packages: make([]Package, 0, len(packages))}
+	for _, pkg := range packages {
+		slices.SortStableFunc(pkg.Symbols, func(a, b Symbol) int {
+			if a.File != b.File {
+				return strings.Compare(a.File, b.File)
+			}
+			return a.Line - b.Line
+		})
+		index.Packages = append(index.Packages, *pkg)
+	}
+	slices.SortFunc(index.Packages, func(a, b Package) int { return strings.Compare(a.Dir, b.Dir) })
+	return index
+}
+
+func indexable(file string) bool {
+	if !repofiles.Indexable(file) || strings.HasSuffix(file, "_test.go") {
+		return false
+	}
+	return strings.HasSuffix(file, ".go") || patternsFor(file) != nil
+}
+
+// Filter returns packages mentioned in text: by directory, package name, file name or symbol name.
+func (x *Index) Filter(text string) []Package {
+	words := make(map[string]bool)
+	for _, word := range wordRe.FindAllString(text, -1) {
+		words[strings.ToLower(strings.Trim(word, "./"))] = true
+	}
+	lowerText := strings.ToLower(text)
+
+	matched := make([]Package, 0)
+	for _, pkg := range x.Packages {
+		if pkg.mentioned(words, lowerText) {
+			matched = append(matched, pkg)
+		}
+	}
+	return matched
+}
+
+var wordRe = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_./-]*`)
+
+// commonNames are too generic to select package by name alone.
+var commonNames = []string{"main", "src", "lib", "app", "util", "utils", "internal", "cmd", "pkg", "test", "tests", "models", "model"}
+
+func (p Package) mentioned(words map[string]bool, lowerText string) bool {
+	if p.Dir != "." && strings.Contains(lowerText, strings.ToLower(p.Dir)) {
+		return true
+	}
+	for _, name := range []string{p.Name, path.Base(p.Dir)} {
+		name = strings.ToLower(name)
+		if len(name) >= 3 && !slices.Contains(commonNames, name) && words[name] {
+			return true
+		}
+	}
+	for _, symbol := range p.Symbols {
+		if words[strings.ToLower(path.Base(symbol.File))] {
+			return true
+		}
+		name := symbol.Name
+		if idx := strings.LastIndex(name, "."); idx >= 0 {
+			name = name[idx+1:]
+		}
+		if len(name) >= 5 && words[strings.ToLower(name)] {
+			return true
+		}
+	}
+	return false
+}
+
+// Render writes packages as compact text, one symbol per line.
+func Render(packages []Package) string {
+	var b strings.Builder
+	for _, pkg := range packages {
+		b.WriteString(pkg.Header())
+		b.WriteString("\n")
+		for _, symbol := range pkg.Symbols {
+			fmt.Fprintf(&b, "  %s:%d %s %s\n", path.Base(symbol.File), symbol.Line, symbol.Kind, symbol.Name)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// Header is package directory with Go package name.
+func (p Package) Header() string {
+	if p.Name != "" && p.Name != path.Base(p.Dir) {
+		return fmt.Sprintf("%s (package %s)", p.Dir, p.Name)
+	}
+	return p.Dir
+}
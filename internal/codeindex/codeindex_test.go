@@ -0,0 +1,147 @@
+package codeindex_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/codeindex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sources = map[string]string{
+	"internal/billing/invoice.go": `package billing
+
+type Invoice struct {
+	ID int
+}
+
+type Store interface {
+	Save(Invoice) error
+}
+
+type total int
+
+func NewInvoice(id int) *Invoice { return &Invoice{ID: id} }
+
+func (i *Invoice) Total() int { return 0 }
+
+func (i *Invoice) round() {}
+
+func helper() {}
+`,
+	"internal/billing/invoice_test.go": "package billing_test\n\nfunc TestInvoice() {}\n",
+	"cmd/tool/main.go":                 "package main\n\nfunc main() {}\n\nfunc Run() {}\n",
+	"web/src/cart.ts":                  "export class ShoppingCart {\n}\n\nexport async function checkoutCart() {}\nexport const MAX_ITEMS = 5\n",
+	"scripts/report.py":                "class ReportBuilder:\n    def build(self):\n        pass\n\ndef _private():\n    pass\n",
+	"vendor/lib/lib.go":                "package lib\n\nfunc Vendored() {}\n",
+	"README.md":                        "# Readme\n",
+}
+
+func buildIndex(t *testing.T) *codeindex.Index {
+	t.Helper()
+	root := t.TempDir()
+	files := make([]string, 0, len(sources))
+	for name, content := range sources {
+		full := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0o755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0o600))
+		files = append(files, name)
+	}
+	return codeindex.Build(root, "abc123", files)
+}
+
+func find(index *codeindex.Index, dir string) codeindex.Package {
+	for _, pkg := range index.Packages {
+		if pkg.Dir == dir {
+			return pkg
+		}
+	}
+	return codeindex.Package{}
+}
+
+func TestBuild(t *testing.T) {
+	index := buildIndex(t)
+	assert.Equal(t, "abc123", index.SHA)
+
+	dirs := make([]string, 0)
+	for _, pkg := range index.Packages {
+		dirs = append(dirs, pkg.Dir)
+	}
+	assert.Equal(t, []string{"cmd/tool", "internal/billing", "scripts", "web/src"}, dirs, "sorted, vendor skipped")
+
+	billing := find(index, "internal/billing")
+	assert.Equal(t, "billing", billing.Name)
+	assert.Equal(t, []codeindex.Symbol{
+		{Kind: "struct", Name: "Invoice", File: "internal/billing/invoice.go", Line: 3},
+		{Kind: "interface", Name: "Store", File: "internal/billing/invoice.go", Line: 7},
+		{Kind: "func", Name: "NewInvoice", File: "internal/billing/invoice.go", Line: 13},
+		{Kind: "method", Name: "Invoice.Total", File: "internal/billing/invoice.go", Line: 15},
+	}, billing.Symbols, "only exported, tests skipped")
+
+	assert.Equal(t, "main", find(index, "cmd/tool").Name)
+
+	ts := find(index, "web/src")
+	require.Len(t, ts.Symbols, 3)
+	assert.Equal(t, codeindex.Symbol{Kind: "class", Name: "ShoppingCart", File: "web/src/cart.ts", Line: 1}, ts.Symbols[0])
+	assert.Equal(t, "checkoutCart", ts.Symbols[1].Name)
+	assert.Equal(t, "MAX_ITEMS", ts.Symbols[2].Name)
+
+	py := find(index, "scripts")
+	names := make([]string, 0)
+	for _, symbol := range py.Symbols {
+		names = append(names, symbol.Kind+" "+symbol.Name)
+	}
+	assert.Equal(t, []string{"class ReportBuilder", "def build"}, names)
+}
+
+func TestIndex_Filter(t *testing.T) {
+	index := buildIndex(t)
+
+	dirs := func(text string) []string {
+		result := make([]string, 0)
+		for _, pkg := range index.Filter(text) {
+			result = append(result, pkg.Dir)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"internal/billing"}, dirs("Fix rounding in billing totals"), "package name")
+	assert.Equal(t, []string{"internal/billing"}, dirs("NewInvoice should validate id"), "symbol")
+	assert.Equal(t, []string{"web/src"}, dirs("Change web/src so cart.ts limits items"), "directory and file")
+	assert.Equal(t, []string{"scripts"}, dirs("ReportBuilder crashes"), "class")
+	assert.Equal(t, []string{"cmd/tool"}, dirs("Run main in tool"), "directory name")
+	assert.Empty(t, dirs("Run main in app"), "generic and short names do not match")
+}
+
+func TestRender(t *testing.T) {
+	index := buildIndex(t)
+	out := codeindex.Render([]codeindex.Package{find(index, "internal/billing"), find(index, "cmd/tool")})
+	assert.Equal(t, "internal/billing\n"+
+		"  invoice.go:3 struct Invoice\n"+
+		"  invoice.go:7 interface Store\n"+
+		"  invoice.go:13 func NewInvoice\n"+
+		"  invoice.go:15 method Invoice.Total\n"+
+		"cmd/tool (package main)\n"+
+		"  main.go:5 func Run", out)
+}
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	index := buildIndex(t)
+
+	assert.Nil(t, codeindex.Load(dir, "shop", "abc123"))
+	require.NoError(t, codeindex.Save(dir, "shop", index))
+	assert.Equal(t, index, codeindex.Load(dir, "shop", "abc123"))
+	assert.Nil(t, codeindex.Load(dir, "shop", "other"), "index of other commit")
+	assert.Nil(t, codeindex.Load(dir, "blog", "abc123"), "index of other project")
+
+	newer := &codeindex.Index{SHA: "def456"}
+	require.NoError(t, codeindex.Save(dir, "shop", newer))
+	assert.Equal(t, newer, codeindex.Load(dir, "shop", "def456"))
+	assert.Nil(t, codeindex.Load(dir, "shop", "abc123"), "only latest index is kept")
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
@@ -0,0 +1,71 @@
+package codeindex
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+)
+
+// parseGo returns package name and exported declarations of Go file.
+func parseGo(file string, src []byte) (string, []Symbol) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, src, parser.SkipObjectResolution)
+	if err != nil {
+		return "", parseRegex(file, src)
+	}
+
+	symbols := make([]Symbol, 0)
+	add := func(kind, name string, pos token.Pos) {
+		symbols = append(symbols, Symbol{Kind: kind, Name: name, File: file, Line: fset.Position(pos).Line})
+	}
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() {
+				continue
+			}
+			if d.Recv == nil || len(d.Recv.List) == 0 {
+				add("func", d.Name.Name, d.Pos())
+				continue
+			}
+			recv := receiverName(d.Recv.List[0].Type)
+			if ast.IsExported(recv) {
+				add("method", recv+"."+d.Name.Name, d.Pos())
+			}
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				typeSpec, ok := spec.(*ast.TypeSpec)
+				if !ok || !typeSpec.Name.IsExported() {
+					continue
+				}
+				kind := "type"
+				switch typeSpec.Type.(type) {
+				case *ast.StructType:
+					kind = "struct"
+				case *ast.InterfaceType:
+					kind = "interface"
+				}
+				add(kind, typeSpec.Name.Name, typeSpec.Pos())
+			}
+		}
+	}
+	return f.Name.Name, symbols
+}
+
+// receiverName returns receiver type name without pointer and type parameters.
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
@@ -0,0 +1,80 @@
+package codeindex
+
+import (
+	"bufio"
+	"bytes"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/andrejsstepanovs/andai/internal/repofiles"
+)
+
+// symbolPatterns are ctags-like declaration patterns by file extension. Each has "kind" and "name" groups.
+var symbolPatterns = map[string][]*regexp.Regexp{
+	"python": {
+		regexp.MustCompile(`^\s*(?:async\s+)?(?P<kind>def|class)\s+(?P<name>[A-Za-z]\w*)`),
+	},
+	"js": {
+		regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:abstract\s+)?(?:async\s+)?(?P<kind>function|class|interface|type|enum)\s+(?P<name>[A-Za-z_$][\w$]*)`),
+		regexp.MustCompile(`^\s*export\s+(?P<kind>const|let)\s+(?P<name>[A-Za-z_$][\w$]*)`),
+	},
+	"jvm": {
+		regexp.MustCompile(`^\s*(?:(?:public|protected|private|internal|abstract|final|sealed|static|data|open|partial)\s+)*(?P<kind>class|interface|enum|record|object|trait)\s+(?P<name>\w+)`),
+	},
+	"php": {
+		regexp.MustCompile(`^\s*(?:(?:abstract|final|public|protected|private|static)\s+)*(?P<kind>class|interface|trait|enum|function)\s+(?P<name>[A-Za-z]\w*)`),
+	},
+	"ruby": {
+		regexp.MustCompile(`^\s*(?P<kind>class|module|def)\s+(?P<name>[A-Za-z][\w.:?!]*)`),
+	},
+	"rust": {
+		regexp.MustCompile(`^\s*(?:pub(?:\([\w:]+\))?\s+)?(?:async\s+)?(?P<kind>fn|struct|enum|trait|mod)\s+(?P<name>\w+)`),
+	},
+	"c": {
+		regexp.MustCompile(`^\s*(?:typedef\s+)?(?P<kind>struct|class|enum|union|namespace)\s+(?P<name>\w+)\s*[:{]?\s*$`),
+	},
+}
+
+var extensionLanguage = map[string]string{
+	".py": "python",
+	".js": "js", ".jsx": "js", ".mjs": "js", ".ts": "js", ".tsx": "js", ".vue": "js",
+	".java": "jvm", ".kt": "jvm", ".scala": "jvm", ".cs": "jvm",
+	".php": "php",
+	".rb":  "ruby",
+	".rs":  "rust",
+	".c":   "c", ".h": "c", ".cc": "c", ".cpp": "c", ".hpp": "c",
+}
+
+func patternsFor(file string) []*regexp.Regexp {
+	return symbolPatterns[extensionLanguage[strings.ToLower(path.Ext(file))]]
+}
+
+// parseRegex finds declarations line by line.
+func parseRegex(file string, src []byte) []Symbol {
+	patterns := patternsFor(file)
+	if patterns == nil {
+		return nil
+	}
+
+	symbols := make([]Symbol, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	scanner.Buffer(make([]byte, 0, 64*1024), repofiles.MaxFileSize)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		for _, re := range patterns {
+			match := re.FindStringSubmatch(text)
+			if match == nil {
+				continue
+			}
+			symbols = append(symbols, Symbol{
+				Kind: match[re.SubexpIndex("kind")],
+				Name: match[re.SubexpIndex("name")],
+				File: file,
+				Line: line,
+			})
+			break
+		}
+	}
+	return symbols
+}
@@ -0,0 +1,24 @@
+package codesearch
+
+import (
+	"github.com/andrejsstepanovs/andai/internal/repofiles"
+)
+
+// CacheDir returns default directory for stored indexes.
+func CacheDir() string {
+	return repofiles.CacheDir("code-search")
+}
+
+// Load returns stored index of project or empty index.
+func Load(dir, project string) *Index {
+	index := NewIndex()
+	if err := repofiles.Load(dir, project, index); err != nil || index.Docs == nil {
+		return NewIndex()
+	}
+	return index
+}
+
+// Save stores project index.
+func Save(dir, project string, index *Index) error {
+	return repofiles.Save(dir, project, index)
+}
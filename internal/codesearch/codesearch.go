@@ -0,0 +1,211 @@
+// Package codesearch is offline lexical (BM25 with trigram fuzzy matching) search over project files.
+// Index is stored per project and refreshed only for files changed since last indexed commit.
+package codesearch
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/andrejsstepanovs/andai/internal/repofiles"
+)
+
+const (
+	// pathWeight is how many times path terms are counted, file names tell a lot.
+	pathWeight = 3
+	// minSimilarity is lowest trigram similarity for fuzzy term match.
+	minSimilarity = 0.45
+	// maxExpansions is how many similar terms are used for query term that is not indexed.
+	maxExpansions = 3
+
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Doc is indexed file.
+type Doc struct {
+	Terms  map[string]int `json:"terms"`
+	Length int            `json:"length"`
+}
+
+// Index is search index of project files at commit.
+type Index struct {
+	SHA  string         `json:"sha"`
+	Docs map[string]Doc `json:"docs"`
+}
+
+// Result is file found by search.
+type Result struct {
+	File    string
+	Score   float64
+	Matches []Match // best matched terms, highest contribution first
+}
+
+// Match explains score part of single term.
+type Match struct {
+	Term  string
+	Query string // query term, differs from Term if matched by trigram similarity
+	Score float64
+}
+
+// NewIndex returns empty index.
+func NewIndex() *Index {
+	return &Index{Docs: make(map[string]Doc)}
+}
+
+// Update re-indexes changed files (relative to root) and drops files that are not tracked anymore.
+// Pass all tracked files as changed to build index from scratch.
+func (x *Index) Update(root, sha string, tracked, changed []string) {
+	trackedSet := make(map[string]bool, len(tracked))
+	for _, file := range tracked {
+		trackedSet[file] = true
+	}
+	for file := range x.Docs {
+		if !trackedSet[file] {
+			delete(x.Docs, file)
+		}
+	}
+	for _, file := range changed {
+		delete(x.Docs, file)
+		if !trackedSet[file] || !indexable(file) {
+			continue
+		}
+		doc, ok := readDoc(root, file)
+		if ok {
+			x.Docs[file] = doc
+		}
+	}
+	x.SHA = sha
+}
+
+func indexable(file string) bool {
+	base := path.Base(file)
+	return repofiles.Indexable(file) && !strings.HasSuffix(base, ".lock") && base != "go.sum" && base != "package-lock.json"
+}
+
+func readDoc(root, file string) (Doc, bool) {
+	content, err := repofiles.Read(root, file)
+	if err != nil {
+		return Doc{}, false
+	}
+
+	doc := Doc{Terms: make(map[string]int)}
+	for _, term := range Tokenize(string(content)) {
+		doc.Terms[term]++
+		doc.Length++
+	}
+	for _, term := range Tokenize(file) {
+		doc.Terms[term] += pathWeight
+		doc.Length += pathWeight
+	}
+	return doc, true
+}
+
+// Search returns top files for query text.
+func (x *Index) Search(query string, limit int) []Result {
+	if len(x.Docs) == 0 {
+		return nil
+	}
+
+	docFreq := make(map[string]int)
+	totalLength := 0
+	for _, doc := range x.Docs {
+		totalLength += doc.Length
+		for term := range doc.Terms {
+			docFreq[term]++
+		}
+	}
+	avgLength := float64(totalLength) / float64(len(x.Docs))
+
+	scores := make(map[string]*Result)
+	for queryTerm, weightedTerms := range x.expand(Tokenize(query), docFreq) {
+		for term, weight := range weightedTerms {
+			idf := math.Log(1 + (float64(len(x.Docs))-float64(docFreq[term])+0.5)/(float64(docFreq[term])+0.5))
+			for file, doc := range x.Docs {
+				tf := float64(doc.Terms[term])
+				if tf == 0 {
+					continue
+				}
+				score := weight * idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(doc.Length)/avgLength))
+				result, ok := scores[file]
+				if !ok {
+					result = &Result{File: file}
+					scores[file] = result
+				}
+				result.Score += score
+				result.Matches = append(result.Matches, Match{Term: term, Query: queryTerm, Score: score})
+			}
+		}
+	}
+
+	results := make([]Result, 0, len(scores))
+	for _, result := range scores {
+		sort.SliceStable(result.Matches, func(a, b int) bool { return result.Matches[a].Score > result.Matches[b].Score })
+		results = append(results, *result)
+	}
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
+		}
+		return results[a].File < results[b].File
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// expand maps each unique query term to indexed terms with weight. Exact match has weight 1,
+// terms that are not indexed are matched to most similar indexed terms by trigrams.
+func (x *Index) expand(queryTerms []string, docFreq map[string]int) map[string]map[string]float64 {
+	expanded := make(map[string]map[string]float64)
+	for _, queryTerm := range queryTerms {
+		if _, ok := expanded[queryTerm]; ok {
+			continue
+		}
+		if docFreq[queryTerm] > 0 {
+			expanded[queryTerm] = map[string]float64{queryTerm: 1}
+			continue
+		}
+
+		queryGrams := trigrams(queryTerm)
+		candidates := make([]Match, 0)
+		for term := range docFreq {
+			if sim := similarity(queryGrams, trigrams(term)); sim >= minSimilarity {
+				candidates = append(candidates, Match{Term: term, Score: sim})
+			}
+		}
+		sort.Slice(candidates, func(a, b int) bool {
+			if candidates[a].Score != candidates[b].Score {
+				return candidates[a].Score > candidates[b].Score
+			}
+			return candidates[a].Term < candidates[b].Term
+		})
+		weights := make(map[string]float64)
+		for n := 0; n < len(candidates) && n < maxExpansions; n++ {
+			weights[candidates[n].Term] = candidates[n].Score
+		}
+		expanded[queryTerm] = weights
+	}
+	return expanded
+}
+
+// Explain formats result as "file (score 12.3: retry 5.1, payment~payments 4.2)".
+func (r Result) Explain(terms int) string {
+	parts := make([]string, 0, terms)
+	seen := make(map[string]bool)
+	for _, match := range r.Matches {
+		if len(parts) == terms || seen[match.Term] {
+			continue
+		}
+		seen[match.Term] = true
+		name := match.Term
+		if match.Query != match.Term {
+			name = match.Query + "~" + match.Term
+		}
+		parts = append(parts, fmt.Sprintf("%s %.1f", name, match.Score))
+	}
+	return fmt.Sprintf("%s (score %.1f: %s)", r.File, r.Score, strings.Join(parts, ", "))
+}
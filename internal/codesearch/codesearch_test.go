@@ -0,0 +1,143 @@
+package codesearch_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/codesearch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sources = map[string]string{
+	"internal/payment/client.go":  "package payment\n\n// RetryPolicy decides when failed payment request is retried.\ntype RetryPolicy struct{ MaxRetries int }\n\nfunc (c *Client) Charge() {}\n",
+	"internal/payment/refund.go":  "package payment\n\nfunc (c *Client) Refund(amount int) {}\n",
+	"internal/user/profile.go":    "package user\n\ntype Profile struct{ Email string }\n",
+	"internal/user/avatar.go":     "package user\n\nfunc UploadAvatar(image []byte) {}\n",
+	"docs/payments.md":            "# Payments\n\nCharging customers.\n",
+	"vendor/lib/retry.go":         "package lib\n\nfunc Retry() {}\n",
+	"web/static/app.min.js":       "function retry(){}",
+	"assets/logo.png":             "\x89PNG\x00\x00retry",
+	"internal/payment/missing.go": "",
+}
+
+func writeSources(t *testing.T) (string, []string) {
+	t.Helper()
+	root := t.TempDir()
+	files := make([]string, 0, len(sources))
+	for name, content := range sources {
+		files = append(files, name)
+		if content == "" {
+			continue // tracked, but not on disk
+		}
+		full := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0o755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0o600))
+	}
+	return root, files
+}
+
+func resultFiles(results []codesearch.Result) []string {
+	files := make([]string, 0, len(results))
+	for _, result := range results {
+		files = append(files, result.File)
+	}
+	return files
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "camel case", text: "HTTPRetryPolicy", want: []string{"http", "retry", "policy"}},
+		{name: "snake case", text: "max_retry_count", want: []string{"max", "retry", "count"}},
+		{name: "stop words and numbers", text: "Fix the retry for 404 in client", want: []string{"retry", "client"}},
+		{name: "path", text: "internal/payment/client.go", want: []string{"internal", "payment", "client", "go"}},
+		{name: "digits split", text: "oauth2Token", want: []string{"oauth", "token"}},
+		{name: "empty", text: "", want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, codesearch.Tokenize(tt.text))
+		})
+	}
+}
+
+func TestIndex_Update(t *testing.T) {
+	root, files := writeSources(t)
+	index := codesearch.NewIndex()
+	index.Update(root, "sha1", files, files)
+
+	assert.Equal(t, "sha1", index.SHA)
+	assert.Contains(t, index.Docs, "internal/payment/client.go")
+	assert.Contains(t, index.Docs, "docs/payments.md")
+	assert.NotContains(t, index.Docs, "vendor/lib/retry.go")
+	assert.NotContains(t, index.Docs, "web/static/app.min.js")
+	assert.NotContains(t, index.Docs, "assets/logo.png", "binary")
+	assert.NotContains(t, index.Docs, "internal/payment/missing.go")
+
+	// refund.go changed, avatar.go removed
+	require.NoError(t, os.WriteFile(filepath.Join(root, "internal/payment/refund.go"), []byte("package payment\n\nfunc Chargeback() {}\n"), 0o600))
+	tracked := make([]string, 0)
+	for _, file := range files {
+		if file != "internal/user/avatar.go" {
+			tracked = append(tracked, file)
+		}
+	}
+	index.Update(root, "sha2", tracked, []string{"internal/payment/refund.go", "internal/user/avatar.go"})
+
+	assert.Equal(t, "sha2", index.SHA)
+	assert.NotContains(t, index.Docs, "internal/user/avatar.go")
+	assert.Contains(t, index.Docs["internal/payment/refund.go"].Terms, "chargeback")
+	assert.NotContains(t, index.Docs["internal/payment/refund.go"].Terms, "amount")
+	assert.Contains(t, index.Docs, "internal/user/profile.go", "unchanged files are kept")
+}
+
+func TestIndex_Search(t *testing.T) {
+	root, files := writeSources(t)
+	index := codesearch.NewIndex()
+	index.Update(root, "sha1", files, files)
+
+	t.Run("exact terms", func(t *testing.T) {
+		results := index.Search("Payment client should retry failed charges", 2)
+		require.Len(t, results, 2)
+		assert.Equal(t, "internal/payment/client.go", results[0].File)
+		assert.Contains(t, results[0].Explain(3), "internal/payment/client.go (score ")
+	})
+
+	t.Run("fuzzy terms", func(t *testing.T) {
+		results := index.Search("avatars upload broken", 1)
+		require.Len(t, results, 1)
+		assert.Equal(t, "internal/user/avatar.go", results[0].File)
+		assert.Contains(t, results[0].Explain(3), "avatars~avatar")
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		assert.Len(t, index.Search("payment", 1), 1)
+		assert.ElementsMatch(t, []string{"internal/payment/client.go", "internal/payment/refund.go"}, resultFiles(index.Search("payment", 0)), "indexed term is not expanded")
+	})
+
+	t.Run("nothing found", func(t *testing.T) {
+		assert.Empty(t, index.Search("kubernetes", 5))
+		assert.Empty(t, codesearch.NewIndex().Search("payment", 5))
+	})
+}
+
+func TestCache(t *testing.T) {
+	root, files := writeSources(t)
+	dir := t.TempDir()
+
+	index := codesearch.NewIndex()
+	index.Update(root, "sha1", files, files)
+	require.NoError(t, codesearch.Save(dir, "shop", index))
+
+	loaded := codesearch.Load(dir, "shop")
+	assert.Equal(t, index, loaded)
+
+	missing := codesearch.Load(dir, "other")
+	assert.Empty(t, missing.SHA)
+	assert.Empty(t, missing.Docs)
+}
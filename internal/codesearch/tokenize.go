@@ -0,0 +1,87 @@
+package codesearch
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopWords are too common in code and tickets to tell files apart.
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true, "this": true, "from": true, "into": true,
+	"are": true, "was": true, "not": true, "but": true, "all": true, "any": true, "can": true, "should": true,
+	"when": true, "then": true, "there": true, "have": true, "has": true, "will": true, "would": true, "must": true,
+	"func": true, "function": true, "return": true, "if": true, "else": true, "var": true, "let": true, "const": true,
+	"import": true, "package": true, "class": true, "def": true, "self": true, "this.": true, "new": true, "nil": true,
+	"null": true, "true": true, "false": true, "string": true, "int": true, "err": true, "error": true, "fix": true,
+	"is": true, "it": true, "in": true, "of": true, "to": true, "on": true, "or": true, "be": true, "as": true, "by": true,
+	"an": true, "at": true, "we": true, "do": true, "so": true, "no": true, "use": true, "make": true, "add": true,
+}
+
+// Tokenize splits text into lowercase terms. Identifiers are split by camelCase and snake_case.
+func Tokenize(text string) []string {
+	terms := make([]string, 0)
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		for _, part := range splitCamel(word) {
+			part = strings.ToLower(part)
+			if len(part) < 2 || stopWords[part] || isNumber(part) {
+				continue
+			}
+			terms = append(terms, part)
+		}
+	}
+	return terms
+}
+
+// splitCamel splits "HTTPRetryPolicy" into "HTTP", "Retry", "Policy".
+func splitCamel(word string) []string {
+	runes := []rune(word)
+	parts := make([]string, 0, 1)
+	start := 0
+	for n := 1; n < len(runes); n++ {
+		prev, cur := runes[n-1], runes[n]
+		lowerToUpper := unicode.IsLower(prev) && unicode.IsUpper(cur)
+		acronymEnd := unicode.IsUpper(prev) && unicode.IsUpper(cur) && n+1 < len(runes) && unicode.IsLower(runes[n+1])
+		letterDigit := unicode.IsLetter(prev) != unicode.IsLetter(cur)
+		if lowerToUpper || acronymEnd || letterDigit {
+			parts = append(parts, string(runes[start:n]))
+			start = n
+		}
+	}
+	return append(parts, string(runes[start:]))
+}
+
+func isNumber(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// trigrams returns set of 3 letter parts of padded term.
+func trigrams(term string) map[string]bool {
+	padded := " " + term + " "
+	grams := make(map[string]bool, len(padded))
+	for n := 0; n+3 <= len(padded); n++ {
+		grams[padded[n:n+3]] = true
+	}
+	return grams
+}
+
+// similarity is Jaccard index of term trigrams.
+func similarity(a, b map[string]bool) float64 {
+	common := 0
+	for gram := range a {
+		if b[gram] {
+			common++
+		}
+	}
+	union := len(a) + len(b) - common
+	if union == 0 {
+		return 0
+	}
+	return float64(common) / float64(union)
+}
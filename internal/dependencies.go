@@ -4,7 +4,10 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/andrejsstepanovs/andai/internal/exec"
+	"github.com/andrejsstepanovs/andai/internal/redact"
 	"github.com/andrejsstepanovs/andai/internal/redmine"
+	"github.com/andrejsstepanovs/andai/internal/sandbox"
 	"github.com/andrejsstepanovs/andai/internal/settings"
 	apiredmine "github.com/mattn/go-redmine"
 	"github.com/spf13/viper"
@@ -30,6 +33,10 @@ func NewAppDependencies(config *settings.Config) (*AppDependencies, error) {
 		return nil, fmt.Errorf("failed to load settings err: %v", err)
 	}
 
+	exec.SetShell(params.Shell)
+	sandbox.Setup(params.Sandbox)
+	redact.Setup(params.Redaction)
+
 	db, err := sql.Open("mysql", viper.GetString("redmine.db"))
 	if err != nil {
 		return nil, err
@@ -2,12 +2,16 @@ package actions
 
 import (
 	"errors"
+	"fmt"
 	"log"
 	"strings"
 	"time"
 
 	"github.com/andrejsstepanovs/andai/internal/ai"
 	"github.com/andrejsstepanovs/andai/internal/exec"
+	"github.com/andrejsstepanovs/andai/internal/monitor"
+	"github.com/andrejsstepanovs/andai/internal/redact"
+	"github.com/andrejsstepanovs/andai/internal/sandbox"
 	"github.com/andrejsstepanovs/andai/internal/settings"
 )
 
@@ -19,8 +23,22 @@ func AiderExecute(contextFile string, step settings.Step, aiderConfig settings.A
 	//	log.Printf("Context file: %q\n", contextFile)
 	//}
 
+	if contextFile != "" {
+		if err := redact.File(contextFile); err != nil {
+			return exec.Output{}, fmt.Errorf("failed to redact aider message file: %v", err)
+		}
+	} else {
+		step.Prompt = settings.StepPrompt(redact.Text(string(step.Prompt)))
+	}
+
 	options := exec.AiderCommand(contextFile, step, aiderConfig)
-	output, err := exec.Exec(step.Command, aiderConfig.Timeout, options)
+	monitor.Default.CountAiderCall()
+	runOptions := exec.Options{
+		Timeout: aiderConfig.Timeout,
+		Sandbox: sandbox.For(step.Command),
+		Mounts:  append(aiderMounts(contextFile, aiderConfig), sandbox.BinaryMounts(step.Command)...),
+	}
+	output, err := exec.RunWithOptions(runOptions, step.Command, options...)
 	if err != nil {
 		log.Printf("Failed to execute command: %v", err)
 		return output, err
@@ -42,7 +60,7 @@ func AiderExecute(contextFile string, step settings.Step, aiderConfig settings.A
 		if tokenLimitReached {
 			if retry {
 				log.Println("Aider has hit a token limit, removing chat history and trying again once more")
-				_, err = exec.Exec("truncate", time.Minute, "-s", "0", ".aider.chat.history.md")
+				_, err = exec.Run(time.Minute, "truncate", "-s", "0", ".aider.chat.history.md")
 				if err != nil {
 					log.Printf("Failed to truncate .aider.chat.history.md: %v", err)
 					output.Stderr = "Failed to truncate .aider.chat.history.md"
@@ -176,3 +194,14 @@ func aiderHitTokenLimit(output exec.Output) bool {
 
 	return true
 }
+
+// aiderMounts lists files outside repository that aider reads, so they are available in sandbox.
+func aiderMounts(contextFile string, aiderConfig settings.Aider) []string {
+	mounts := make([]string, 0, 3)
+	for _, path := range []string{contextFile, aiderConfig.Config, aiderConfig.ModelMetadataFile} {
+		if path != "" {
+			mounts = append(mounts, path)
+		}
+	}
+	return mounts
+}
@@ -0,0 +1,103 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/andrejsstepanovs/andai/internal/ai"
+	"github.com/andrejsstepanovs/andai/internal/employee/actions/file"
+	"github.com/andrejsstepanovs/andai/internal/employee/actions/models"
+	"github.com/teilomillet/gollm"
+)
+
+// learnAttempts is how many times LLM is asked again if its answer is not valid.
+const learnAttempts = 3
+
+// ExtractLessons asks LLM for durable project lessons from finished issue (knowledge file).
+// Known lessons are given so LLM does not repeat them.
+func ExtractLessons(llm *ai.AI, knowledgeFile string, known []string) ([]string, error) {
+	if knowledgeFile == "" {
+		return nil, fmt.Errorf("knowledge file is required for learning")
+	}
+	knowledge, err := file.GetContents(knowledgeFile)
+	if err != nil {
+		return nil, err
+	}
+
+	validationPrompt := ""
+	for n := 0; n < learnAttempts; n++ {
+		lessons, invalid, err := getLessons(llm, knowledge, known, validationPrompt)
+		if err != nil {
+			return nil, err
+		}
+		if invalid == "" {
+			return lessons.Lessons, nil
+		}
+		log.Printf("Lessons answer is not valid (%s). Trying again.", invalid)
+		validationPrompt = fmt.Sprintf("Your last answer was not good: ----\n\n%s\n\n----. Try again and this time make sure your answer (JSON) is valid!", invalid)
+	}
+	return nil, fmt.Errorf("failed to get valid lessons after %d attempts", learnAttempts)
+}
+
+func getLessons(llm *ai.AI, knowledge string, known []string, promptExtend string) (models.Lessons, string, error) {
+	example := models.Lessons{
+		Lessons: []string{
+			"Tests need `make db-up` first, they fail with connection refused without database.",
+			"Never edit generated files in /gen, change templates in /tmpl and run `make generate`.",
+		},
+	}
+	jsonResp, err := json.Marshal(example)
+	if err != nil {
+		return models.Lessons{}, "", err
+	}
+
+	knownLessons := "(none yet)"
+	if len(known) > 0 {
+		knownLessons = "- " + strings.Join(known, "\n- ")
+	}
+
+	templatePrompt := gollm.NewPromptTemplate("ExtractLessons", "",
+		"Issue work is finished. Your task is to extract lessons that will help to work on other issues of this project.\n\n"+
+			"# Instructions:\n"+
+			"- Use Context: ticket, comments, previous attempts and why they were rejected.\n"+
+			"- Lesson is durable project fact or rule: how to build or test, conventions, places not to touch, "+
+			"mistakes that made people reject the work.\n"+
+			"- Do not write lessons about this issue only (what was implemented), about general programming or about the workflow itself.\n"+
+			"- Each lesson is 1 short sentence (max 300 characters) that makes sense without this issue.\n"+
+			"- Do not repeat known lessons (listed below).\n"+
+			"- Most issues teach nothing new. Then return empty lessons list.\n"+
+			"- Use example data structure for your answer.\n\n"+
+			"# Known lessons:\n{{.Known}}\n\n"+
+			ai.ForceJSON+"\n"+promptExtend,
+		gollm.WithPromptOptions(
+			gollm.WithDirectives("Extract durable project lessons and return them as JSON."),
+			gollm.WithOutput("JSON"),
+			gollm.WithContext(knowledge),
+			gollm.WithExamples([]string{"\n```\n" + string(jsonResp) + "\n```\n"}...),
+		),
+	)
+
+	prompt, err := templatePrompt.Execute(map[string]interface{}{
+		"Known": knownLessons,
+	})
+	if err != nil {
+		return models.Lessons{}, "", err
+	}
+
+	lessons := models.Lessons{}
+	_, validationErr, err := llm.GenerateJSON(context.Background(), prompt, &lessons)
+	if err != nil {
+		return models.Lessons{}, "", err
+	}
+	if validationErr != nil {
+		return models.Lessons{}, validationErr.Error(), nil
+	}
+	if err = lessons.Validate(); err != nil {
+		return lessons, err.Error(), nil
+	}
+
+	return lessons, "", nil
+}
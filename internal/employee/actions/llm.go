@@ -34,11 +34,11 @@ func EvaluateOutcome(llm *ai.AI, knowledgeFile string) (exec.Output, bool, error
 			"- If no comments are present, it probably means that tests were successful and result is positive.\n"+
 			"- Clarification: Negative outcome will mean that task needs to be re-visited and is not ready. Positive outcome means that issue can be moved forward to next step (usually being closed).\n"+
 			"- In case of positive outcome, answer with 1 word \"Positive\".\n"+
-			"- In case of negative outcome, answer with 1 word \"Negative\".\n"+
-			"- Do not explain why you came to this conclusion or any other information about your thinking process.\n"+
-			"- Answer with 1 word (\"Positive\" or \"Negative\")!\n",
+			"- In case of negative outcome, answer with word \"Negative\" and on next line 1 short sentence why.\n"+
+			"- Do not explain your thinking process or add any other information.\n"+
+			"- First word of your answer must be \"Positive\" or \"Negative\"!\n",
 		gollm.WithPromptOptions(
-			gollm.WithOutput("1 word"),
+			gollm.WithOutput("1 word, for negative outcome followed by 1 sentence reason"),
 			gollm.WithContext(knowledge),
 		),
 	)
@@ -57,10 +57,20 @@ func EvaluateOutcome(llm *ai.AI, knowledgeFile string) (exec.Output, bool, error
 		return exec.Output{}, false, err
 	}
 
-	if strings.Trim(strings.TrimSpace(out.Stdout), "\"") == "Positive" {
-		return out, true, nil
+	verdict, _ := EvaluationReason(out.Stdout)
+	return out, verdict == "Positive", nil
+}
+
+// EvaluationReason splits evaluation answer into verdict word and reason that follows it.
+func EvaluationReason(answer string) (string, string) {
+	answer = strings.TrimSpace(answer)
+	end := strings.IndexAny(answer, " \t\n")
+	if end < 0 {
+		end = len(answer)
 	}
-	return out, false, nil
+	verdict := strings.Trim(answer[:end], "\"'*.:,")
+	reason := strings.TrimSpace(strings.TrimLeft(answer[end:], " \t\n-:"))
+	return verdict, reason
 }
 
 func GenerateIssues(llm *ai.AI, targetIssueTypeName settings.IssueTypeName, knowledgeFile string) (exec.Output, map[int]redmine.Issue, map[int][]int, error) {
@@ -0,0 +1,31 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLlm_EvaluationReason(t *testing.T) {
+	tests := []struct {
+		name    string
+		answer  string
+		verdict string
+		reason  string
+	}{
+		{name: "positive", answer: "Positive", verdict: "Positive"},
+		{name: "quoted", answer: " \"Positive\"\n", verdict: "Positive"},
+		{name: "negative without reason", answer: "Negative.", verdict: "Negative"},
+		{name: "negative with reason", answer: "Negative\nTests still fail in parser package.", verdict: "Negative", reason: "Tests still fail in parser package."},
+		{name: "same line reason", answer: "**Negative**: reviewer asked for docs", verdict: "Negative", reason: "reviewer asked for docs"},
+		{name: "empty", answer: "", verdict: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict, reason := EvaluationReason(tt.answer)
+			assert.Equal(t, tt.verdict, verdict)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxLessonLength keeps lessons short enough to be injected into every prompt.
+const maxLessonLength = 300
+
+type Lessons struct {
+	Lessons []string `json:"lessons"`
+}
+
+// Validate checks that lessons are short single line statements.
+func (l Lessons) Validate() error {
+	for n, lesson := range l.Lessons {
+		lesson = strings.TrimSpace(lesson)
+		if lesson == "" {
+			return fmt.Errorf("lesson %d is empty", n+1)
+		}
+		if strings.Contains(lesson, "\n") {
+			return fmt.Errorf("lesson %d must be single line", n+1)
+		}
+		if len([]rune(lesson)) > maxLessonLength {
+			return fmt.Errorf("lesson %d is longer than %d characters", n+1, maxLessonLength)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package models_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/employee/actions/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLessons_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		lessons   models.Lessons
+		expectErr bool
+	}{
+		{name: "no lessons", lessons: models.Lessons{}},
+		{name: "valid", lessons: models.Lessons{Lessons: []string{"Run `make db-up` before tests."}}},
+		{name: "empty", lessons: models.Lessons{Lessons: []string{" "}}, expectErr: true},
+		{name: "multi line", lessons: models.Lessons{Lessons: []string{"first\nsecond"}}, expectErr: true},
+		{name: "too long", lessons: models.Lessons{Lessons: []string{strings.Repeat("a", 301)}}, expectErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.lessons.Validate()
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/andrejsstepanovs/andai/internal/settings"
+)
+
+type ReviewFinding struct {
+	File       string `json:"file"`
+	Line       int    `json:"line"`
+	Severity   string `json:"severity"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion"`
+}
+
+type Review struct {
+	Summary  string          `json:"summary"`
+	Findings []ReviewFinding `json:"findings"`
+}
+
+// Validate checks that every finding has known severity and message.
+func (r Review) Validate() error {
+	for n, finding := range r.Findings {
+		if settings.SeverityRank(finding.Severity) < 0 {
+			return fmt.Errorf("finding %d severity %q is not valid, use one of: %s", n+1, finding.Severity, strings.Join(settings.ReviewSeverities, ", "))
+		}
+		if strings.TrimSpace(finding.Message) == "" {
+			return errors.New("finding message is required")
+		}
+		if finding.Line < 0 {
+			return fmt.Errorf("finding %d line cannot be negative", n+1)
+		}
+	}
+	return nil
+}
+
+// Sorted returns findings from most severe, then by file and line.
+func (r Review) Sorted() []ReviewFinding {
+	findings := slices.Clone(r.Findings)
+	slices.SortStableFunc(findings, func(a, b ReviewFinding) int {
+		return cmp.Or(
+			cmp.Compare(settings.SeverityRank(b.Severity), settings.SeverityRank(a.Severity)),
+			cmp.Compare(a.File, b.File),
+			cmp.Compare(a.Line, b.Line),
+		)
+	})
+	return findings
+}
+
+// Blocking returns findings that are at least as severe as threshold.
+func (r Review) Blocking(threshold string) []ReviewFinding {
+	blocking := make([]ReviewFinding, 0)
+	for _, finding := range r.Findings {
+		if settings.SeverityRank(finding.Severity) >= settings.SeverityRank(threshold) {
+			blocking = append(blocking, finding)
+		}
+	}
+	return blocking
+}
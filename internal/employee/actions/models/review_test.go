@@ -0,0 +1,54 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/employee/actions/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReview_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		review    models.Review
+		expectErr bool
+	}{
+		{name: "no findings", review: models.Review{}},
+		{name: "valid", review: models.Review{Findings: []models.ReviewFinding{{File: "a.go", Line: 1, Severity: "error", Message: "bug"}}}},
+		{name: "unknown severity", review: models.Review{Findings: []models.ReviewFinding{{Severity: "high", Message: "bug"}}}, expectErr: true},
+		{name: "no message", review: models.Review{Findings: []models.ReviewFinding{{Severity: "info"}}}, expectErr: true},
+		{name: "negative line", review: models.Review{Findings: []models.ReviewFinding{{Severity: "info", Message: "x", Line: -1}}}, expectErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.review.Validate()
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestReview_SortedAndBlocking(t *testing.T) {
+	review := models.Review{Findings: []models.ReviewFinding{
+		{File: "b.go", Line: 3, Severity: "warning", Message: "w"},
+		{File: "a.go", Line: 9, Severity: "error", Message: "e2"},
+		{File: "a.go", Line: 2, Severity: "error", Message: "e1"},
+		{File: "c.go", Severity: "info", Message: "i"},
+		{File: "d.go", Severity: "critical", Message: "c"},
+	}}
+
+	messages := make([]string, 0)
+	for _, finding := range review.Sorted() {
+		messages = append(messages, finding.Message)
+	}
+	assert.Equal(t, []string{"c", "e1", "e2", "w", "i"}, messages)
+	assert.Equal(t, "w", review.Findings[0].Message, "original order kept")
+
+	assert.Len(t, review.Blocking("critical"), 1)
+	assert.Len(t, review.Blocking("error"), 3)
+	assert.Len(t, review.Blocking("info"), 5)
+	assert.Empty(t, models.Review{}.Blocking("info"))
+}
@@ -0,0 +1,117 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/andrejsstepanovs/andai/internal/ai"
+	"github.com/andrejsstepanovs/andai/internal/employee/actions/file"
+	"github.com/andrejsstepanovs/andai/internal/employee/actions/models"
+	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/teilomillet/gollm"
+)
+
+// reviewAttempts is how many times LLM is asked again if its answer is not valid.
+const reviewAttempts = 3
+
+// ReviewCode asks LLM to review branch diff (part of knowledge file) and return structured findings.
+func ReviewCode(llm *ai.AI, knowledgeFile string) (models.Review, error) {
+	if knowledgeFile == "" {
+		return models.Review{}, fmt.Errorf("knowledge file is required for review")
+	}
+	knowledge, err := file.GetContents(knowledgeFile)
+	if err != nil {
+		return models.Review{}, err
+	}
+
+	validationPrompt := ""
+	for n := 0; n < reviewAttempts; n++ {
+		review, invalid, err := getReview(llm, knowledge, validationPrompt)
+		if err != nil {
+			return models.Review{}, err
+		}
+		if invalid == "" {
+			return review, nil
+		}
+		log.Printf("Review answer is not valid (%s). Trying again.", invalid)
+		validationPrompt = fmt.Sprintf("Your last answer was not good: ----\n\n%s\n\n----. Try again and this time make sure your answer (JSON) is valid!", invalid)
+	}
+	return models.Review{}, fmt.Errorf("failed to get valid review after %d attempts", reviewAttempts)
+}
+
+func getReview(llm *ai.AI, knowledge, promptExtend string) (models.Review, string, error) {
+	example := models.Review{
+		Summary: "Login works, but password is logged in plain text.",
+		Findings: []models.ReviewFinding{
+			{
+				File:       "internal/auth/login.go",
+				Line:       42,
+				Severity:   settings.SeverityCritical,
+				Message:    "Password is written to log.",
+				Suggestion: "Remove password from log message.",
+			},
+			{
+				File:       "internal/auth/login_test.go",
+				Line:       0,
+				Severity:   settings.SeverityWarning,
+				Message:    "Failed login is not tested.",
+				Suggestion: "Add test case with wrong password.",
+			},
+		},
+	}
+	jsonResp, err := json.Marshal(example)
+	if err != nil {
+		return models.Review{}, "", err
+	}
+
+	templatePrompt := gollm.NewPromptTemplate("ReviewCode", "",
+		"You are senior software engineer reviewing code changes made for the issue.\n\n"+
+			"# Instructions:\n"+
+			"- Use Context to understand what the issue asks for. Review only the changes in the branch diff.\n"+
+			"- Look for bugs, missing requirements, security problems, missing tests and code that will be hard to maintain.\n"+
+			"- Do not report style nitpicks that linters would catch.\n"+
+			"- Each finding should contain: file (path from diff), line (line number in new file, 0 if not about specific line), "+
+			"severity (one of: {{.Severities}}), message (what is wrong), suggestion (how to fix it).\n"+
+			"- Use `critical` for security problems and data loss, `error` for bugs and missing requirements, "+
+			"`warning` for risky code and missing tests, `info` for everything else.\n"+
+			"- summary is 1-2 sentences about overall quality of the change.\n"+
+			"- If there is nothing to report, return empty findings list.\n"+
+			"- Use example data structure for your answer.\n\n"+
+			ai.ForceJSON+"\n"+promptExtend,
+		gollm.WithPromptOptions(
+			gollm.WithDirectives("Review code changes and return findings as JSON."),
+			gollm.WithOutput("JSON"),
+			gollm.WithContext(knowledge),
+			gollm.WithExamples([]string{"\n```\n" + string(jsonResp) + "\n```\n"}...),
+		),
+	)
+
+	prompt, err := templatePrompt.Execute(map[string]interface{}{
+		"Severities": strings.Join(settings.ReviewSeverities, ", "),
+	})
+	if err != nil {
+		return models.Review{}, "", err
+	}
+
+	review := models.Review{}
+	_, validationErr, err := llm.GenerateJSON(context.Background(), prompt, &review)
+	if err != nil {
+		return models.Review{}, "", err
+	}
+	if validationErr != nil {
+		return models.Review{}, validationErr.Error(), nil
+	}
+
+	for n := range review.Findings {
+		review.Findings[n].Severity = strings.ToLower(strings.TrimSpace(review.Findings[n].Severity))
+		review.Findings[n].File = strings.TrimPrefix(strings.TrimSpace(review.Findings[n].File), "b/")
+	}
+	if err = review.Validate(); err != nil {
+		return review, err.Error(), nil
+	}
+
+	return review, "", nil
+}
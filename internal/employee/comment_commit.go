@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"github.com/andrejsstepanovs/andai/internal/exec"
-	model "github.com/andrejsstepanovs/andai/internal/redmine"
 )
 
 // CommitCommentFormat is the format for the commit comment
@@ -63,16 +62,10 @@ func (i *Routine) commentCommitsSince(currentCommitSku, commitMessage string) (i
 }
 
 func (i *Routine) commitUncommitted(commitMessage string) (exec.Output, error) {
-	modified := "git status | cat | grep modified | awk '{print $2}'"
-	out, err := exec.Exec(modified, time.Minute)
+	files, err := exec.ModifiedFiles()
 	if err != nil {
 		return exec.Output{}, err
 	}
-	if out.Stdout == "" {
-		log.Println("No files to add")
-		return exec.Output{}, nil
-	}
-	files := strings.Split(out.Stdout, "\n")
 	if len(files) == 0 {
 		log.Println("No files to add")
 		return exec.Output{}, nil
@@ -83,19 +76,17 @@ func (i *Routine) commitUncommitted(commitMessage string) (exec.Output, error) {
 		return exec.Output{}, err
 	}
 
-	for _, f := range files {
-		ret, err := exec.Exec(fmt.Sprintf("git add %s", f), time.Minute)
-		if err != nil {
-			return ret, err
-		}
+	ret, err := exec.Run(time.Minute, "git", append([]string{"add", "--"}, files...)...)
+	if err != nil {
+		return ret, err
 	}
-	ret, err := exec.Exec("git commit -m \"code reformat\"", time.Minute)
+	ret, err = exec.Run(time.Minute, "git", "commit", "-m", "code reformat")
 	if err != nil {
 		return ret, err
 	}
 	_, err = i.commentCommitsSince(lastCommit, commitMessage)
 	if err != nil {
-		return out, err
+		return ret, err
 	}
 	return ret, nil
 }
@@ -157,17 +148,7 @@ func (i *Routine) commentParentBranchDiff() error {
 		return nil
 	}
 
-	parentSha := ""
-	lastSha := ""
-	for _, field := range i.issue.CustomFields {
-		if field.Name == model.CustomFieldParentSha {
-			parentSha = field.Value.(string)
-		}
-		if field.Name == model.CustomFieldLastSha {
-			lastSha = field.Value.(string)
-		}
-	}
-
+	parentSha, lastSha := i.workbench.GetIssueParentLastSha(i.issue)
 	if parentSha == "" || lastSha == "" || parentSha == lastSha {
 		return nil
 	}
@@ -194,24 +175,14 @@ func (i *Routine) commentParentBranchDiff() error {
 	}
 
 	{ // mention all in-between commits. will be useful for "context-commits" command later on.
-		allLastCommits, err := i.workbench.GetLastCommits(10)
+		commits, err := i.workbench.GetCommitRange(parentSha, lastSha)
 		if err != nil {
-			return fmt.Errorf("failed to get last commits: %v", err)
+			return fmt.Errorf("failed to get branch commits: %v", err)
 		}
 
-		txt := make([]string, 0)
-		start := false
-		n := 0
-		for _, commit := range allLastCommits {
-			if commit == parentSha {
-				break
-			}
-			if commit == lastSha {
-				start = true
-			}
-			if start {
-				txt = append(txt, fmt.Sprintf(CommitCommentFormat, n+1, commit, i.project.Identifier, i.project.Identifier, commit, "code changes"))
-			}
+		txt := make([]string, 0, len(commits))
+		for n, commit := range commits {
+			txt = append(txt, fmt.Sprintf(CommitCommentFormat, n+1, commit, i.project.Identifier, i.project.Identifier, commit, "code changes"))
 		}
 
 		if len(txt) == 0 {
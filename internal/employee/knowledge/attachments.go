@@ -0,0 +1,172 @@
+package knowledge
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/andrejsstepanovs/andai/internal/ai"
+	redminemodels "github.com/andrejsstepanovs/andai/internal/redmine/models"
+	"github.com/andrejsstepanovs/andai/internal/truncate"
+)
+
+const (
+	// maxAttachmentTextBytes is biggest text attachment that is downloaded.
+	maxAttachmentTextBytes = 512 * 1024
+	// maxAttachmentTextTokens is how much of text attachment is inlined, rest is truncated.
+	maxAttachmentTextTokens = 4000
+	// maxAttachmentImageBytes is biggest image that is sent to vision model.
+	maxAttachmentImageBytes = 5 * 1024 * 1024
+	// maxAttachmentImages is how many images are sent to vision model.
+	maxAttachmentImages = 5
+)
+
+// textExtensions are inlined even if redmine reports generic content type.
+var textExtensions = []string{
+	".txt", ".log", ".md", ".csv", ".tsv", ".json", ".yaml", ".yml", ".xml", ".html", ".sql", ".diff", ".patch",
+	".go", ".py", ".js", ".ts", ".php", ".rb", ".java", ".sh", ".ini", ".toml", ".env",
+}
+
+// AttachmentSource lists and downloads issue attachments.
+type AttachmentSource interface {
+	APIGetAttachments(issueID int) (redminemodels.Attachments, error)
+	APIDownloadAttachment(attachment redminemodels.Attachment, limit int64) ([]byte, error)
+}
+
+func (k Knowledge) getAttachments() (string, error) {
+	return k.attachmentsContext(k.Issue.Id, "attachments")
+}
+
+func (k Knowledge) getParentAttachments() (string, error) {
+	if k.Parent == nil || k.Parent.Id == 0 {
+		return "", nil
+	}
+	return k.attachmentsContext(k.Parent.Id, "parent_attachments")
+}
+
+func (k Knowledge) attachmentsContext(issueID int, tag string) (string, error) {
+	if k.Attachments == nil {
+		return "", nil
+	}
+	attachments, err := k.Attachments.APIGetAttachments(issueID)
+	if err != nil {
+		log.Printf("Failed to get attachments: %v", err)
+		return "", err
+	}
+	if len(attachments) == 0 {
+		return "", nil
+	}
+
+	parts := make([]string, 0, len(attachments))
+	others := make([]string, 0)
+	images := 0
+	for _, attachment := range attachments {
+		switch {
+		case isTextAttachment(attachment):
+			part, ok := k.textAttachment(attachment)
+			if ok {
+				parts = append(parts, part)
+				continue
+			}
+		case isImageAttachment(attachment) && k.Vision && images < maxAttachmentImages && attachment.FileSize <= maxAttachmentImageBytes:
+			part, ok := k.imageAttachment(attachment)
+			if ok {
+				images++
+				parts = append(parts, part)
+				continue
+			}
+		}
+		others = append(others, fmt.Sprintf("- %s (%s, %s)%s", attachment.FileName, attachmentType(attachment), humanSize(attachment.FileSize), attachmentDescription(attachment)))
+	}
+
+	if len(others) > 0 {
+		parts = append(parts, k.TagContent("other_files", strings.Join(others, "\n"), 1))
+	}
+	return k.TagContent(tag, strings.Join(parts, "\n"), 1), nil
+}
+
+func (k Knowledge) textAttachment(attachment redminemodels.Attachment) (string, bool) {
+	if attachment.FileSize > maxAttachmentTextBytes {
+		return "", false
+	}
+	content, err := k.Attachments.APIDownloadAttachment(attachment, maxAttachmentTextBytes)
+	if err != nil {
+		log.Printf("Failed to download attachment: %v", err)
+		return "", false
+	}
+	if bytes.IndexByte(content, 0) >= 0 || !utf8.Valid(content) {
+		return "", false
+	}
+	text := truncate.Truncate(string(content), maxAttachmentTextTokens, truncate.ModeHeadTail)
+	return k.TagContent("file", fmt.Sprintf("Name: %s%s\n%s", attachment.FileName, attachmentDescription(attachment), text), 1), true
+}
+
+// imageAttachment downloads image once per AttachmentsDir so steps of same run share it.
+func (k Knowledge) imageAttachment(attachment redminemodels.Attachment) (string, bool) {
+	if k.AttachmentsDir == "" {
+		return "", false
+	}
+	path := filepath.Join(k.AttachmentsDir, fmt.Sprintf("attachment-%d%s", attachment.ID, strings.ToLower(filepath.Ext(attachment.FileName))))
+	if _, err := os.Stat(path); err != nil {
+		content, err := k.Attachments.APIDownloadAttachment(attachment, maxAttachmentImageBytes)
+		if err != nil {
+			log.Printf("Failed to download attachment: %v", err)
+			return "", false
+		}
+		if err = os.WriteFile(path, content, 0o600); err != nil {
+			log.Printf("Failed to save attachment: %v", err)
+			return "", false
+		}
+	}
+	return ai.ImageTag(attachment.FileName, path) + attachmentDescription(attachment), true
+}
+
+func isTextAttachment(attachment redminemodels.Attachment) bool {
+	contentType := strings.ToLower(attachment.ContentType)
+	if strings.HasPrefix(contentType, "text/") {
+		return true
+	}
+	for _, kind := range []string{"json", "xml", "yaml", "javascript", "x-sh", "sql"} {
+		if strings.Contains(contentType, kind) {
+			return true
+		}
+	}
+	return slices.Contains(textExtensions, strings.ToLower(filepath.Ext(attachment.FileName)))
+}
+
+func isImageAttachment(attachment redminemodels.Attachment) bool {
+	if strings.HasPrefix(strings.ToLower(attachment.ContentType), "image/") {
+		return !strings.Contains(attachment.ContentType, "svg")
+	}
+	return slices.Contains([]string{".png", ".jpg", ".jpeg", ".gif", ".webp"}, strings.ToLower(filepath.Ext(attachment.FileName)))
+}
+
+func attachmentType(attachment redminemodels.Attachment) string {
+	if attachment.ContentType != "" {
+		return attachment.ContentType
+	}
+	return "unknown type"
+}
+
+func attachmentDescription(attachment redminemodels.Attachment) string {
+	if description := strings.TrimSpace(attachment.Description); description != "" {
+		return " - " + description
+	}
+	return ""
+}
+
+func humanSize(size int64) string {
+	switch {
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(size)/1024/1024)
+	case size >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
@@ -0,0 +1,123 @@
+package knowledge_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/employee/knowledge"
+	redminemodels "github.com/andrejsstepanovs/andai/internal/redmine/models"
+	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/mattn/go-redmine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAttachments struct {
+	attachments map[int]redminemodels.Attachments
+	content     map[int][]byte
+}
+
+func (f fakeAttachments) APIGetAttachments(issueID int) (redminemodels.Attachments, error) {
+	return f.attachments[issueID], nil
+}
+
+func (f fakeAttachments) APIDownloadAttachment(attachment redminemodels.Attachment, limit int64) ([]byte, error) {
+	content, ok := f.content[attachment.ID]
+	if !ok || int64(len(content)) > limit {
+		return nil, errors.New("download failed")
+	}
+	return content, nil
+}
+
+func buildKnowledge(t *testing.T, k knowledge.Knowledge) string {
+	t.Helper()
+	file, err := k.BuildIssueKnowledgeTmpFile()
+	require.NoError(t, err)
+	if file == "" {
+		return ""
+	}
+	t.Cleanup(func() { _ = os.Remove(file) })
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestKnowledge_Attachments(t *testing.T) {
+	source := fakeAttachments{
+		attachments: map[int]redminemodels.Attachments{
+			1: {
+				{ID: 10, FileName: "test.log", FileSize: 17, ContentType: "text/plain", Description: "failing run"},
+				{ID: 11, FileName: "screen.png", FileSize: 4, ContentType: "image/png"},
+				{ID: 12, FileName: "spec.pdf", FileSize: 2048, ContentType: "application/pdf"},
+				{ID: 13, FileName: "dump.bin", FileSize: 3, ContentType: "application/octet-stream"},
+			},
+			2: {
+				{ID: 20, FileName: "parent.json", FileSize: 13, ContentType: "application/json"},
+			},
+		},
+		content: map[int][]byte{
+			10: []byte("panic: nil map\nok"),
+			11: []byte("\x89PNG"),
+			13: {0, 1, 2},
+			20: []byte(`{"a": "b"}`),
+		},
+	}
+	issue := redmine.Issue{Id: 1}
+	parent := redmine.Issue{Id: 2}
+
+	t.Run("text inlined, images listed without vision", func(t *testing.T) {
+		k := knowledge.Knowledge{Issue: issue, Attachments: source, Step: settings.Step{Context: []string{settings.ContextAttachments}}}
+		content := buildKnowledge(t, k)
+
+		assert.Contains(t, content, "<attachments>")
+		assert.Contains(t, content, "Name: test.log - failing run")
+		assert.Contains(t, content, "panic: nil map")
+		assert.Contains(t, content, "- screen.png (image/png, 4 B)")
+		assert.Contains(t, content, "- spec.pdf (application/pdf, 2.0 KB)")
+		assert.Contains(t, content, "- dump.bin (application/octet-stream, 3 B)")
+		assert.NotContains(t, content, "<image ")
+	})
+
+	t.Run("images for vision model", func(t *testing.T) {
+		dir := t.TempDir()
+		k := knowledge.Knowledge{Issue: issue, Attachments: source, Vision: true, AttachmentsDir: dir, Step: settings.Step{Context: []string{settings.ContextAttachments}}}
+		content := buildKnowledge(t, k)
+
+		match := regexp.MustCompile(`<image name="screen.png" path="([^"]+)" />`).FindStringSubmatch(content)
+		require.Len(t, match, 2)
+		assert.Equal(t, dir, filepath.Dir(match[1]))
+		image, err := os.ReadFile(match[1])
+		require.NoError(t, err)
+		assert.Equal(t, "\x89PNG", string(image))
+		assert.NotContains(t, content, "- screen.png")
+
+		// next step of same run reuses downloaded image
+		k.Attachments = fakeAttachments{attachments: source.attachments, content: map[int][]byte{10: source.content[10]}}
+		assert.Contains(t, buildKnowledge(t, k), match[0])
+	})
+
+	t.Run("images listed without attachments dir", func(t *testing.T) {
+		k := knowledge.Knowledge{Issue: issue, Attachments: source, Vision: true, Step: settings.Step{Context: []string{settings.ContextAttachments}}}
+		content := buildKnowledge(t, k)
+		assert.Contains(t, content, "- screen.png (image/png, 4 B)")
+		assert.NotContains(t, content, "<image ")
+	})
+
+	t.Run("parent", func(t *testing.T) {
+		k := knowledge.Knowledge{Issue: issue, Parent: &parent, Attachments: source, Step: settings.Step{Context: []string{settings.ContextParentAttachments}}}
+		content := buildKnowledge(t, k)
+		assert.Contains(t, content, "<parent_attachments>")
+		assert.Contains(t, content, `{"a": "b"}`)
+
+		k.Parent = nil
+		assert.Empty(t, buildKnowledge(t, k))
+	})
+
+	t.Run("no attachments", func(t *testing.T) {
+		k := knowledge.Knowledge{Issue: redmine.Issue{Id: 3}, Attachments: source, Step: settings.Step{Context: []string{settings.ContextAttachments}}}
+		assert.Empty(t, buildKnowledge(t, k))
+	})
+}
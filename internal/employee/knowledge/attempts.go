@@ -0,0 +1,79 @@
+package knowledge
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andrejsstepanovs/andai/internal/attempts"
+	"github.com/andrejsstepanovs/andai/internal/truncate"
+)
+
+const (
+	// attemptsMax is how many latest attempts are summarized.
+	attemptsMax = 5
+	// attemptReasonsTokens limits verdict reasons of single attempt.
+	attemptReasonsTokens = 300
+	// attemptRejectionTokens limits human rejection comment of single attempt.
+	attemptRejectionTokens = 500
+	// attemptCommitsMax is how many commits of single attempt are listed.
+	attemptCommitsMax = 10
+)
+
+// getPreviousAttempts summarizes earlier attempts made in current issue state,
+// so LLM knows what was already tried and why it was rejected.
+func (k Knowledge) getPreviousAttempts() (string, error) {
+	if len(k.PreviousAttempts) == 0 {
+		return "", nil
+	}
+
+	list := k.PreviousAttempts
+	parts := make([]string, 0, attemptsMax+1)
+	if skipped := len(list) - attemptsMax; skipped > 0 {
+		parts = append(parts, fmt.Sprintf("(%d earlier attempts omitted)", skipped))
+		list = list[skipped:]
+	}
+	first := len(k.PreviousAttempts) - len(list) + 1
+	for n, attempt := range list {
+		parts = append(parts, k.TagContent(fmt.Sprintf("attempt_%d", first+n), attemptSummary(attempt), 1))
+	}
+
+	return k.TagContent("previous_attempts", strings.Join(parts, "\n"), 1), nil
+}
+
+func attemptSummary(attempt attempts.Attempt) string {
+	lines := []string{
+		fmt.Sprintf("When: %s (state %q)", attempt.Started.Format("2006-01-02 15:04"), attempt.State),
+		fmt.Sprintf("Verdict: %s", attempt.Verdict),
+	}
+
+	steps := make([]string, 0, len(attempt.Steps))
+	for _, step := range attempt.Steps {
+		name := strings.TrimSpace(step.Command + " " + step.Action)
+		steps = append(steps, fmt.Sprintf("%s (%s)", name, step.Outcome))
+	}
+	if len(steps) > 0 {
+		lines = append(lines, "Steps: "+strings.Join(steps, ", "))
+	}
+
+	commits := attempt.Commits
+	if len(commits) == 0 {
+		lines = append(lines, "Commits: none")
+	} else {
+		lines = append(lines, "Commits:")
+		if len(commits) > attemptCommitsMax {
+			lines = append(lines, fmt.Sprintf("- ... %d earlier commits", len(commits)-attemptCommitsMax))
+			commits = commits[len(commits)-attemptCommitsMax:]
+		}
+		for _, commit := range commits {
+			lines = append(lines, "- "+commit)
+		}
+	}
+
+	if reasons := strings.TrimSpace(attempt.Reasons); reasons != "" {
+		lines = append(lines, "Reasons:", truncate.Truncate(reasons, attemptReasonsTokens, truncate.ModeHead))
+	}
+	if rejection := strings.TrimSpace(attempt.Rejection); rejection != "" {
+		lines = append(lines, "Human feedback:", truncate.Truncate(rejection, attemptRejectionTokens, truncate.ModeHeadTail))
+	}
+	return strings.Join(lines, "\n")
+}
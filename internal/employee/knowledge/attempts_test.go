@@ -0,0 +1,78 @@
+package knowledge_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrejsstepanovs/andai/internal/attempts"
+	"github.com/andrejsstepanovs/andai/internal/employee/knowledge"
+	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/mattn/go-redmine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKnowledge_PreviousAttempts(t *testing.T) {
+	started := time.Date(2026, 2, 3, 14, 5, 0, 0, time.UTC)
+	build := func(list []attempts.Attempt) string {
+		k := knowledge.Knowledge{
+			Issue:            redmine.Issue{Id: 1},
+			PreviousAttempts: list,
+			Step:             settings.Step{Context: []string{settings.ContextPreviousAttempts}},
+		}
+		return buildKnowledge(t, k)
+	}
+
+	t.Run("no attempts", func(t *testing.T) {
+		assert.NotContains(t, build(nil), "previous_attempts")
+	})
+
+	t.Run("summary", func(t *testing.T) {
+		content := build([]attempts.Attempt{
+			{
+				State:   "In Progress",
+				Started: started,
+				Steps: []attempts.Step{
+					{Command: "aider", Action: "code", Outcome: "ok"},
+					{Command: "evaluate", Outcome: "negative"},
+				},
+				Commits:   []string{"abc1234 Add retry loop"},
+				Verdict:   attempts.VerdictNegative,
+				Reasons:   "Retry loop never stops",
+				Rejection: "Do not retry, fail fast instead.",
+			},
+			{
+				State:   "In Progress",
+				Started: started.Add(time.Hour),
+				Steps:   []attempts.Step{{Command: "aider", Action: "code", Outcome: "exit status 1"}},
+				Verdict: attempts.VerdictFailed,
+			},
+		})
+
+		assert.Contains(t, content, "<previous_attempts>")
+		assert.Contains(t, content, "<attempt_1>")
+		assert.Contains(t, content, `When: 2026-02-03 14:05 (state "In Progress")`)
+		assert.Contains(t, content, "Steps: aider code (ok), evaluate (negative)")
+		assert.Contains(t, content, "- abc1234 Add retry loop")
+		assert.Contains(t, content, "Reasons:\n\t\tRetry loop never stops")
+		assert.Contains(t, content, "Human feedback:\n\t\tDo not retry, fail fast instead.")
+		assert.Contains(t, content, "<attempt_2>")
+		assert.Contains(t, content, "Verdict: failed")
+		assert.Contains(t, content, "Commits: none")
+	})
+
+	t.Run("only latest attempts", func(t *testing.T) {
+		list := make([]attempts.Attempt, 0)
+		for n := 1; n <= 7; n++ {
+			list = append(list, attempts.Attempt{State: "In Progress", Verdict: attempts.VerdictNegative, Reasons: fmt.Sprintf("reason %d", n)})
+		}
+		content := build(list)
+
+		assert.Contains(t, content, "(2 earlier attempts omitted)")
+		assert.NotContains(t, content, "reason 2\n")
+		assert.Contains(t, content, "<attempt_3>")
+		assert.Contains(t, content, "<attempt_7>")
+		assert.Equal(t, 5, strings.Count(content, "</attempt_"))
+	})
+}
@@ -0,0 +1,52 @@
+package knowledge
+
+import (
+	"fmt"
+	"strings"
+
+	redminemodels "github.com/andrejsstepanovs/andai/internal/redmine/models"
+	"github.com/andrejsstepanovs/andai/internal/truncate"
+)
+
+const (
+	// collapseCommentTokens is AI comment size above which it is collapsed in full comment history.
+	collapseCommentTokens = 500
+	// collapseStubLines is how many first lines of collapsed comment are kept.
+	collapseStubLines = 3
+	// collapseStubLineChars is how long kept lines can be.
+	collapseStubLineChars = 200
+)
+
+// collapseAIComments shortens large AI output comments (aider, command results) to a stub,
+// so they do not crowd out human feedback. Last comment is never collapsed, it usually is what next step works with.
+func collapseAIComments(comments redminemodels.Comments) redminemodels.Comments {
+	collapsed := make(redminemodels.Comments, len(comments))
+	for n, comment := range comments {
+		if comment.AI && n < len(comments)-1 {
+			if tokens := truncate.EstimateTokens(comment.Text); tokens > collapseCommentTokens {
+				comment.Text = commentStub(comment.Text, tokens)
+			}
+		}
+		collapsed[n] = comment
+	}
+	return collapsed
+}
+
+func commentStub(text string, tokens int) string {
+	lines := make([]string, 0, collapseStubLines+1)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if runes := []rune(line); len(runes) > collapseStubLineChars {
+			line = string(runes[:collapseStubLineChars]) + "..."
+		}
+		lines = append(lines, line)
+		if len(lines) == collapseStubLines {
+			break
+		}
+	}
+	lines = append(lines, fmt.Sprintf("... (AI output collapsed, ~%d tokens)", tokens))
+	return strings.Join(lines, "\n")
+}
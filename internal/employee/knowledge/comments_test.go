@@ -0,0 +1,57 @@
+package knowledge_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/employee/knowledge"
+	redminemodels "github.com/andrejsstepanovs/andai/internal/redmine/models"
+	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/mattn/go-redmine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKnowledge_CommentRoles(t *testing.T) {
+	aiderOutput := "Command: aider code\n\nApplied edit to main.go\n" + strings.Repeat("diff line with some changes\n", 200)
+	comments := redminemodels.Comments{
+		{Number: 1, Author: "Jane Doe", Text: "Please keep the API backwards compatible.", CreatedAt: "2026-01-01"},
+		{Number: 2, Author: "Redmine Admin", AI: true, Text: aiderOutput, CreatedAt: "2026-01-02"},
+		{Number: 3, Author: "Jane Doe", Text: "Also update the docs.", CreatedAt: "2026-01-03"},
+		{Number: 4, Author: "Redmine Admin", AI: true, Text: aiderOutput, CreatedAt: "2026-01-04"},
+	}
+	build := func(context string) string {
+		k := knowledge.Knowledge{Issue: redmine.Issue{Id: 1}, Comments: comments, Step: settings.Step{Context: []string{context}}}
+		return buildKnowledge(t, k)
+	}
+
+	t.Run("comments tagged and old AI output collapsed", func(t *testing.T) {
+		content := build(settings.ContextComments)
+		assert.Contains(t, content, `<comment_1 at="2026-01-01" author="Jane Doe" role="human">`)
+		assert.Contains(t, content, `<comment_2 at="2026-01-02" author="Redmine Admin" role="ai">`)
+		require.Equal(t, 1, strings.Count(content, "AI output collapsed"), "last comment is kept in full")
+		assert.Contains(t, content, "Command: aider code\n\tApplied edit to main.go\n\tdiff line with some changes\n\t... (AI output collapsed, ~")
+		assert.Equal(t, 201, strings.Count(content, "diff line with some changes"))
+	})
+
+	t.Run("human comments", func(t *testing.T) {
+		content := build(settings.ContextHumanComments)
+		assert.Contains(t, content, "backwards compatible")
+		assert.Contains(t, content, "update the docs")
+		assert.NotContains(t, content, "aider")
+	})
+
+	t.Run("ai comments are not collapsed", func(t *testing.T) {
+		content := build(settings.ContextAIComments)
+		assert.NotContains(t, content, "Jane")
+		assert.NotContains(t, content, "collapsed")
+		assert.Equal(t, 400, strings.Count(content, "diff line with some changes"))
+	})
+
+	t.Run("last human comment", func(t *testing.T) {
+		content := build(settings.ContextLastHumanComment)
+		assert.Contains(t, content, "<last-human-comment>")
+		assert.Contains(t, content, "update the docs")
+		assert.NotContains(t, content, "backwards compatible")
+	})
+}
@@ -0,0 +1,87 @@
+package knowledge
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/andrejsstepanovs/andai/internal/gitdiff"
+	"github.com/andrejsstepanovs/andai/internal/truncate"
+)
+
+// diffOptions returns project diff limits overridden by step ones.
+func (k Knowledge) diffOptions() gitdiff.Options {
+	return k.Project.Diff.Merge(k.Step.Diff).Options()
+}
+
+func (k Knowledge) getBranchDiff() (string, error) {
+	diff, err := k.Workbench.GetBranchDiff(k.parentRef(), "HEAD")
+	if err != nil {
+		log.Printf("Failed to get branch diff: %v", err)
+		return "", err
+	}
+	diff = gitdiff.Render(diff, k.diffOptions())
+	if diff == "" {
+		return "", nil
+	}
+	return k.TagContent("branch_diff", diff, 1), nil
+}
+
+func (k Knowledge) getLastCommitDiff() (string, error) {
+	diff, err := k.Workbench.GetCommitDiff("HEAD")
+	if err != nil {
+		log.Printf("Failed to get last commit diff: %v", err)
+		return "", err
+	}
+	diff = gitdiff.Render(diff, k.diffOptions())
+	if diff == "" {
+		return "", nil
+	}
+	return k.TagContent("last_commit_diff", diff, 1), nil
+}
+
+// getChildrenDiff returns diff of each closed (merged) child using its Parent SHA and Last SHA.
+// Token limit is shared between children.
+func (k Knowledge) getChildrenDiff() (string, error) {
+	opts := k.diffOptions()
+	used := 0
+	parts := make([]string, 0)
+	omitted := make([]string, 0)
+	for _, child := range k.Children {
+		if !slices.Contains(k.ClosedChildrenIDs, child.Id) {
+			continue
+		}
+		parentSha, lastSha := k.Workbench.GetIssueParentLastSha(child)
+		if parentSha == "" || lastSha == "" || parentSha == lastSha {
+			continue
+		}
+		if used >= opts.MaxTokens {
+			omitted = append(omitted, fmt.Sprintf("#%d", child.Id))
+			continue
+		}
+		diff, err := k.Workbench.GetBranchDiff(parentSha, lastSha)
+		if err != nil {
+			log.Printf("Failed to get diff of child %d: %v", child.Id, err)
+			return "", err
+		}
+
+		childOpts := opts
+		childOpts.MaxTokens = opts.MaxTokens - used
+		diff = gitdiff.Render(diff, childOpts)
+		if diff == "" {
+			continue
+		}
+		used += truncate.EstimateTokens(diff)
+
+		txt := fmt.Sprintf("Child issue #%d %s\n%s", child.Id, child.Subject, diff)
+		parts = append(parts, k.TagContent("child_diff", txt, 2))
+	}
+	if len(omitted) > 0 {
+		parts = append(parts, fmt.Sprintf("... diff of children %s omitted (too big) ...", strings.Join(omitted, ", ")))
+	}
+	if len(parts) == 0 {
+		return "", nil
+	}
+	return k.TagContent("children_diff", strings.Join(parts, "\n"), 1), nil
+}
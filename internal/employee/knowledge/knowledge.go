@@ -8,7 +8,9 @@ import (
 	"strings"
 	"text/template"
 
+	"github.com/andrejsstepanovs/andai/internal/attempts"
 	"github.com/andrejsstepanovs/andai/internal/exec"
+	"github.com/andrejsstepanovs/andai/internal/lessons"
 	redminemodels "github.com/andrejsstepanovs/andai/internal/redmine/models"
 	"github.com/andrejsstepanovs/andai/internal/settings"
 	"github.com/mattn/go-redmine"
@@ -32,6 +34,11 @@ type Knowledge struct {
 	ParentComments    redminemodels.Comments
 	SiblingsComments  redminemodels.Comments
 	Step              settings.Step
+	Attachments       AttachmentSource   // nil disables attachments contexts
+	Vision            bool               // step model accepts images
+	AttachmentsDir    string             // images for vision model are downloaded here, owner removes it
+	PreviousAttempts  []attempts.Attempt // earlier attempts in current issue state, oldest first
+	Lessons           []lessons.Lesson   // project lessons learned page entries
 }
 
 func (k Knowledge) BuildPromptTmpFile() (string, error) {
@@ -147,11 +154,21 @@ func (k Knowledge) getCommentContext(context string) (string, error) {
 	case settings.ContextFifeComment:
 		return k.getLastNComments(5, settings.ContextFifeComment)
 	case settings.ContextComments:
-		return k.getComments(k.Comments, settings.ContextComments)
+		return k.getComments(collapseAIComments(k.Comments), settings.ContextComments)
 	case settings.ContextParentComments:
-		return k.getComments(k.ParentComments, settings.ContextParentComments)
+		return k.getComments(collapseAIComments(k.ParentComments), settings.ContextParentComments)
 	case settings.ContextSiblingsComments:
-		return k.getComments(k.SiblingsComments, settings.ContextSiblingsComments)
+		return k.getComments(collapseAIComments(k.SiblingsComments), settings.ContextSiblingsComments)
+	case settings.ContextHumanComments:
+		return k.getComments(k.Comments.Human(), settings.ContextHumanComments)
+	case settings.ContextAIComments:
+		return k.getComments(k.Comments.AI(), settings.ContextAIComments)
+	case settings.ContextLastHumanComment:
+		human := k.Comments.Human()
+		if len(human) == 0 {
+			return "", nil
+		}
+		return k.getComments(human[len(human)-1:], settings.ContextLastHumanComment)
 	default:
 		return "", fmt.Errorf("unknown comment context: %q", context)
 	}
@@ -181,8 +198,24 @@ func (k Knowledge) getContext(context string) (string, error) {
 		return k.getChildren()
 	case settings.ContextAffectedFiles:
 		return k.getChangedFiles()
+	case settings.ContextBranchDiff:
+		return k.getBranchDiff()
+	case settings.ContextChildrenDiff:
+		return k.getChildrenDiff()
+	case settings.ContextLastCommitDiff:
+		return k.getLastCommitDiff()
+	case settings.ContextRepoMap:
+		return k.getRepoMap()
 	case settings.ContextIssueTypes:
 		return k.getIssueTypes()
+	case settings.ContextAttachments:
+		return k.getAttachments()
+	case settings.ContextParentAttachments:
+		return k.getParentAttachments()
+	case settings.ContextPreviousAttempts:
+		return k.getPreviousAttempts()
+	case settings.ContextLessons:
+		return k.getLessons()
 	default:
 		return "", fmt.Errorf("unknown context: %q", context)
 	}
@@ -324,32 +357,24 @@ func (k Knowledge) getChildren() (string, error) {
 	return issueContext, nil
 }
 
-// getChangedFiles returns files that were changed in the branch (not perfect yet, relies on count of closed children)
-func (k Knowledge) getChangedFiles() (string, error) {
-	if len(k.ClosedChildrenIDs) == 0 {
-		return "", nil
+// parentRef returns where issue branch was created from: Parent SHA or parent (final) branch.
+func (k Knowledge) parentRef() string {
+	parentSha, _ := k.Workbench.GetIssueParentLastSha(k.Issue)
+	if parentSha != "" {
+		return parentSha
 	}
-	commits, err := k.Workbench.GetBranchCommits(len(k.ClosedChildrenIDs)) // TODO use custom fields to retrieve commits
-	if err != nil {
-		log.Printf("Failed to get branch commits: %v", err)
-		return "", err
-	}
-
-	filesMap := make(map[string]struct{})
-	for _, commit := range commits {
-		files, err := k.Workbench.GetAffectedFiles(commit)
-		if err != nil {
-			log.Printf("Failed to get affected files for commit %q: %v", commit, err)
-			return "", err
-		}
-		for _, file := range files {
-			filesMap[file] = struct{}{}
-		}
+	if k.Parent != nil && k.Parent.Id != 0 {
+		return k.Workbench.GetIssueBranchName(*k.Parent)
 	}
+	return k.Project.FinalBranch
+}
 
-	files := make([]string, 0)
-	for file := range filesMap {
-		files = append(files, file)
+// getChangedFiles returns files that were changed in the issue branch since it was created from parent branch.
+func (k Knowledge) getChangedFiles() (string, error) {
+	files, err := k.Workbench.GetChangedFiles(k.parentRef(), "HEAD")
+	if err != nil {
+		log.Printf("Failed to get changed files: %v", err)
+		return "", err
 	}
 
 	if len(files) == 0 {
@@ -376,7 +401,7 @@ func (k Knowledge) getIssueTypes() (string, error) {
 
 func (k Knowledge) getCommentsContext(comments redminemodels.Comments) (string, error) {
 	promptTemplate := "{{ range .Comments }}" +
-		"\n<comment_{{.Number}} at=\"{{.CreatedAt}}\">\n" +
+		"\n<comment_{{.Number}} at=\"{{.CreatedAt}}\"{{ if .Author }} author=\"{{.Author}}\"{{ end }} role=\"{{ if .AI }}ai{{ else }}human{{ end }}\">\n" +
 		"{{.Text}}" +
 		"\n</comment_{{.Number}}>" +
 		"{{ end }}"
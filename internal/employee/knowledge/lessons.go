@@ -0,0 +1,28 @@
+package knowledge
+
+import (
+	"strings"
+
+	"github.com/andrejsstepanovs/andai/internal/lessons"
+)
+
+// lessonsMax is how many most relevant project lessons are given.
+const lessonsMax = 10
+
+// getLessons returns project lessons learned that are most relevant to the issue.
+func (k Knowledge) getLessons() (string, error) {
+	if len(k.Lessons) == 0 {
+		return "", nil
+	}
+
+	text := []string{k.Issue.Subject, k.Issue.Description}
+	for _, comment := range k.Comments.Human() {
+		text = append(text, comment.Text)
+	}
+
+	lines := make([]string, 0, lessonsMax)
+	for _, lesson := range lessons.Relevant(k.Lessons, strings.Join(text, "\n"), lessonsMax) {
+		lines = append(lines, "- "+lesson.Text)
+	}
+	return k.TagContent("project_lessons", strings.Join(lines, "\n"), 1), nil
+}
@@ -0,0 +1,43 @@
+package knowledge_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/employee/knowledge"
+	"github.com/andrejsstepanovs/andai/internal/lessons"
+	redminemodels "github.com/andrejsstepanovs/andai/internal/redmine/models"
+	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/mattn/go-redmine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKnowledge_Lessons(t *testing.T) {
+	page := []string{"- Payment client retries must be idempotent (#4)"}
+	for n := 1; n <= 12; n++ {
+		page = append(page, fmt.Sprintf("- Unrelated lesson number %d", n))
+	}
+	build := func(all []lessons.Lesson) string {
+		k := knowledge.Knowledge{
+			Issue:    redmine.Issue{Id: 1, Subject: "Retry failed payment"},
+			Comments: redminemodels.Comments{{Number: 1, Text: "Client retries twice"}},
+			Lessons:  all,
+			Step:     settings.Step{Context: []string{settings.ContextLessons}},
+		}
+		return buildKnowledge(t, k)
+	}
+
+	t.Run("no lessons", func(t *testing.T) {
+		assert.NotContains(t, build(nil), "project_lessons")
+	})
+
+	t.Run("most relevant lessons", func(t *testing.T) {
+		content := build(lessons.Parse(strings.Join(page, "\n")))
+		assert.Contains(t, content, "<project_lessons>\n\t- Payment client retries must be idempotent\n")
+		assert.NotContains(t, content, "#4")
+		assert.Equal(t, 10, strings.Count(content, "\t- "))
+		assert.Contains(t, content, "Unrelated lesson number 12")
+		assert.NotContains(t, content, "Unrelated lesson number 1\n")
+	})
+}
@@ -0,0 +1,92 @@
+package knowledge
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/andrejsstepanovs/andai/internal/codeindex"
+	"github.com/andrejsstepanovs/andai/internal/truncate"
+)
+
+const (
+	// repoMapMaxTokens limits symbols of mentioned packages.
+	repoMapMaxTokens = 4000
+	// repoMapMaxOther limits how many other package names are listed.
+	repoMapMaxOther = 200
+)
+
+// getRepoMap returns symbols of packages mentioned in ticket and list of other packages.
+// Index is cached by commit SHA.
+func (k Knowledge) getRepoMap() (string, error) {
+	index, err := k.repoIndex()
+	if err != nil {
+		log.Printf("Failed to build repository map: %v", err)
+		return "", err
+	}
+	if len(index.Packages) == 0 {
+		return "", nil
+	}
+
+	text := []string{k.Issue.Subject, k.Issue.Description}
+	for _, comment := range k.Comments {
+		text = append(text, comment.Text)
+	}
+	matched := index.Filter(strings.Join(text, "\n"))
+
+	parts := make([]string, 0, 2)
+	if len(matched) > 0 {
+		symbols := truncate.Truncate(codeindex.Render(matched), repoMapMaxTokens, truncate.ModeHead)
+		parts = append(parts, k.TagContent("mentioned_packages", symbols, 2))
+	}
+
+	other := make([]string, 0)
+	for _, pkg := range index.Packages {
+		if len(other) == repoMapMaxOther {
+			other = append(other, fmt.Sprintf("... and %d more", len(index.Packages)-len(matched)-repoMapMaxOther))
+			break
+		}
+		if !containsPackage(matched, pkg.Dir) {
+			other = append(other, fmt.Sprintf("%s (%d symbols)", pkg.Header(), len(pkg.Symbols)))
+		}
+	}
+	if len(other) > 0 {
+		parts = append(parts, k.TagContent("other_packages", strings.Join(other, "\n"), 2))
+	}
+
+	return k.TagContent("repo_map", strings.Join(parts, "\n"), 1), nil
+}
+
+func (k Knowledge) repoIndex() (*codeindex.Index, error) {
+	sha, err := k.Workbench.GetLastCommit()
+	if err != nil {
+		return nil, err
+	}
+	cacheDir := codeindex.CacheDir()
+	if index := codeindex.Load(cacheDir, k.Project.Identifier, sha); index != nil {
+		return index, nil
+	}
+
+	files, err := k.Workbench.GetTrackedFiles()
+	if err != nil {
+		return nil, err
+	}
+	root := k.Workbench.WorkingDir
+	if root == "" {
+		root = "."
+	}
+	index := codeindex.Build(root, sha, files)
+	if err = codeindex.Save(cacheDir, k.Project.Identifier, index); err != nil {
+		log.Printf("Failed to cache repository map: %v", err)
+	}
+	return index, nil
+}
+
+func containsPackage(packages []codeindex.Package, dir string) bool {
+	for _, pkg := range packages {
+		if pkg.Dir == dir {
+			return true
+		}
+	}
+	return false
+}
@@ -3,15 +3,20 @@ package employee
 import (
 	"errors"
 
+	"github.com/andrejsstepanovs/andai/internal/attempts"
 	"github.com/andrejsstepanovs/andai/internal/exec"
 	model "github.com/andrejsstepanovs/andai/internal/redmine"
 	redminemodels "github.com/andrejsstepanovs/andai/internal/redmine/models"
+	"github.com/andrejsstepanovs/andai/internal/runlog"
 	"github.com/andrejsstepanovs/andai/internal/settings"
 	"github.com/mattn/go-redmine"
 )
 
 var ErrNegativeOutcome = errors.New("negative outcome")
 
+// ErrSkipped is returned when work on issue was skipped (from dashboard) and issue must be left where it is.
+var ErrSkipped = errors.New("skipped")
+
 type Routine struct {
 	model             *model.Model
 	llmPool           *settings.LlmModels
@@ -32,6 +37,11 @@ type Routine struct {
 	job               settings.Job
 	history           []string
 	contextFiles      []string
+	runLog            *runlog.Run
+	attempts          attempts.History
+	attempt           *attempts.Attempt // attempt being recorded, nil if not recording
+	attemptHead       string            // HEAD when attempt started
+	attachmentsDir    string            // attachment images downloaded during current workflow run
 }
 
 // NewRoutine creates an Routine instance configured to work on a specific Redmine issue.
@@ -75,3 +85,8 @@ func NewRoutine(
 		projectRepo:       projectRepo,
 	}
 }
+
+// SetRunLog makes step command output stream into given run log.
+func (i *Routine) SetRunLog(run *runlog.Run) {
+	i.runLog = run
+}
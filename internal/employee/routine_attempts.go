@@ -0,0 +1,121 @@
+package employee
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/andrejsstepanovs/andai/internal/attempts"
+	"github.com/andrejsstepanovs/andai/internal/exec"
+	model "github.com/andrejsstepanovs/andai/internal/redmine"
+	"github.com/andrejsstepanovs/andai/internal/settings"
+)
+
+// startAttempt loads issue attempt history, attaches human feedback given since last attempt
+// to it and starts recording new attempt.
+func (i *Routine) startAttempt() {
+	history, err := attempts.Decode(i.issue.Id, i.customFieldValue(model.CustomFieldAttempts))
+	if err != nil {
+		log.Printf("Failed to load previous attempts: %v", err)
+	}
+	i.attempts = history
+
+	if last := i.attempts.Last(); last != nil && last.Rejection == "" {
+		comments, err := i.getComments()
+		if err != nil {
+			log.Printf("Failed to get feedback of previous attempt: %v", err)
+		}
+		feedback := make([]string, 0)
+		for _, comment := range comments.Human() {
+			if comment.Number > last.LastComment {
+				feedback = append(feedback, fmt.Sprintf("%s: %s", comment.Author, strings.TrimSpace(comment.Text)))
+			}
+		}
+		last.Rejection = strings.Join(feedback, "\n\n")
+	}
+
+	i.attempt = &attempts.Attempt{
+		State:   string(i.state.Name),
+		Started: time.Now(),
+	}
+	i.attemptHead = exec.RevParse("HEAD")
+}
+
+// previousAttempts returns attempts made earlier in current state.
+// Learn step gets attempts from all states, rejections usually happened before.
+func (i *Routine) previousAttempts(step settings.Step) []attempts.Attempt {
+	if step.Command == "learn" {
+		return i.attempts.Attempts
+	}
+	return i.attempts.ForState(string(i.state.Name))
+}
+
+// recordStep adds executed step outcome to current attempt.
+func (i *Routine) recordStep(command, action string, err error) {
+	if i.attempt == nil {
+		return
+	}
+	outcome := "ok"
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrNegativeOutcome):
+		outcome = attempts.VerdictNegative
+	case isConflict(err):
+		outcome = attempts.VerdictConflict
+	default:
+		outcome = err.Error()
+	}
+	i.attempt.AddStep(command, action, outcome)
+}
+
+// finishAttempt stores current attempt with its verdict into issue attempt history.
+func (i *Routine) finishAttempt(verdict, reasons string) {
+	if i.attempt == nil {
+		return
+	}
+	attempt := *i.attempt
+	i.attempt = nil
+
+	attempt.Finished = time.Now()
+	attempt.Verdict = verdict
+	attempt.Reasons = strings.TrimSpace(reasons)
+	attempt.Commits = i.attemptCommits()
+
+	comments, err := i.getComments()
+	if err != nil {
+		log.Printf("Failed to get comments for attempt record: %v", err)
+	} else if len(comments) > 0 {
+		attempt.LastComment = comments[len(comments)-1].Number
+	}
+
+	i.attempts.Add(attempt)
+	value, err := attempts.Encode(i.attempts)
+	if err != nil {
+		log.Printf("Failed to save attempt: %v", err)
+		return
+	}
+	if err = i.saveCustomFieldValue(model.CustomFieldAttempts, value, true); err != nil {
+		log.Printf("Failed to save attempt: %v", err)
+	}
+}
+
+// attemptCommits lists commits made since attempt started. Oldest first.
+func (i *Routine) attemptCommits() []string {
+	if i.attemptHead == "" {
+		return nil
+	}
+	out, err := exec.Run(time.Minute, "git", "log", "--reverse", "--format=%h %s", i.attemptHead+"..HEAD")
+	if err != nil {
+		log.Printf("Failed to list attempt commits: %v", err)
+		return nil
+	}
+	commits := make([]string, 0)
+	for _, line := range strings.Split(out.Stdout, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			commits = append(commits, line)
+		}
+	}
+	return commits
+}
@@ -0,0 +1,210 @@
+package employee
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/andrejsstepanovs/andai/internal/employee/actions"
+	"github.com/andrejsstepanovs/andai/internal/employee/actions/file"
+	"github.com/andrejsstepanovs/andai/internal/exec"
+	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/andrejsstepanovs/andai/internal/truncate"
+)
+
+const (
+	conflictCommentPrefix = "**Merge conflict**"
+	// maxConflictHunkTokens limits conflict diff that goes into issue comment.
+	maxConflictHunkTokens = 3000
+)
+
+// abortOnConflict comments conflicting files and hunks, aborts merge and returns conflict error.
+// Workflow treats it as negative outcome, so issue moves to fail state.
+func (i *Routine) abortOnConflict(operation string, files []string) error {
+	hunks, err := exec.ConflictHunks(files)
+	if err != nil {
+		log.Printf("Failed to get conflict hunks: %v", err)
+	}
+
+	comment := fmt.Sprintf("%s while %s in %d files:\n- `%s`", conflictCommentPrefix, operation, len(files), strings.Join(files, "`\n- `"))
+	if hunks != "" {
+		comment += fmt.Sprintf("\n\n```diff\n%s\n```", truncate.Truncate(hunks, maxConflictHunkTokens, truncate.ModeHead))
+	}
+	if err = i.AddComment(comment); err != nil {
+		log.Printf("Failed to comment merge conflict: %v", err)
+	}
+
+	if err = exec.AbortMerge(); err != nil {
+		log.Printf("Failed to abort merge: %v", err)
+	}
+
+	return &exec.ConflictError{Operation: operation, Files: files}
+}
+
+// handleMergeConflict applies project conflict_policy to merge that stopped on conflicts.
+// On success conflicts are resolved and staged, merge is not committed yet.
+func (i *Routine) handleMergeConflict(operation string, files []string) error {
+	if i.projectCfg.GetConflictPolicy() != settings.ConflictPolicyResolve {
+		return i.abortOnConflict(operation, files)
+	}
+
+	_, err := i.resolveConflicts(files, i.projectCfg.ConflictCheck)
+	if err != nil {
+		log.Printf("Failed to resolve conflicts: %v", err)
+		return i.abortOnConflict(fmt.Sprintf("%s (automatic resolution failed: %v)", operation, err), files)
+	}
+	return nil
+}
+
+// resolveConflictsStep resolves conflicts left in repository by previous steps and commits the merge.
+func (i *Routine) resolveConflictsStep(workflowStep settings.Step) (exec.Output, error) {
+	files, err := exec.ConflictedFiles()
+	if err != nil {
+		return exec.Output{}, err
+	}
+	if len(files) == 0 {
+		return exec.Output{Stdout: "No merge conflicts"}, nil
+	}
+
+	checkCommand := workflowStep.Action
+	if checkCommand == "" {
+		checkCommand = i.projectCfg.ConflictCheck
+	}
+
+	out, err := i.resolveConflicts(files, checkCommand)
+	if err != nil {
+		log.Printf("Failed to resolve conflicts: %v", err)
+		return out, i.abortOnConflict(fmt.Sprintf("resolving conflicts (%v)", err), files)
+	}
+
+	return i.commitConflictResolution()
+}
+
+// commitConflictResolution commits staged conflict resolution, finishing merge if one is in progress.
+func (i *Routine) commitConflictResolution() (exec.Output, error) {
+	if exec.IsMerging() {
+		return exec.Run(time.Minute, "git", "commit", "--no-edit")
+	}
+	return exec.Run(time.Minute, "git", "commit", "-m", fmt.Sprintf("#%d Resolve merge conflicts", i.issue.Id))
+}
+
+// prepareWorkplace checks out issue branch on top of its target branches.
+// Conflicts follow project conflict_policy. Returns false (without error) if conflicts were not resolved.
+func (i *Routine) prepareWorkplace() (bool, error) {
+	parentBranches := i.getTargetBranch()
+	_, err := i.workbench.PrepareWorkplace(parentBranches...)
+	var conflictErr *exec.ConflictError
+	if !errors.As(err, &conflictErr) {
+		return err == nil, err
+	}
+
+	if err = i.handleMergeConflict(conflictErr.Operation, conflictErr.Files); err != nil {
+		return false, nil
+	}
+	if out, err := i.commitConflictResolution(); err != nil {
+		return false, fmt.Errorf("failed to commit resolved conflicts: %w, stderr: %s", err, out.Stderr)
+	}
+
+	_, err = i.workbench.PrepareWorkplace(parentBranches...)
+	if errors.As(err, &conflictErr) {
+		_ = i.abortOnConflict(conflictErr.Operation, conflictErr.Files)
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// resolveConflicts asks coding agent to resolve conflicted files, verifies no markers are left,
+// runs check project command (if given) and stages resolved files.
+func (i *Routine) resolveConflicts(files []string, checkCommand string) (exec.Output, error) {
+	promptFile, err := i.buildConflictPrompt(files)
+	if err != nil {
+		return exec.Output{}, err
+	}
+
+	absFiles := make([]string, 0, len(files))
+	for _, f := range files {
+		abs, err := filepath.Abs(f)
+		if err != nil {
+			return exec.Output{}, err
+		}
+		absFiles = append(absFiles, abs)
+	}
+
+	step := settings.Step{Command: "aider", Action: "resolve", ContextFiles: absFiles}
+	out, err := actions.AiderExecute(promptFile, step, i.codingAgents.Aider, true)
+	if err != nil {
+		return out, fmt.Errorf("coding agent failed: %w", err)
+	}
+
+	marked, err := exec.FilesWithConflictMarkers(files)
+	if err != nil {
+		return out, err
+	}
+	if len(marked) > 0 {
+		return out, fmt.Errorf("conflict markers left in: %s", strings.Join(marked, ", "))
+	}
+
+	if checkCommand != "" {
+		checkOut, err := i.runProjectCmd(settings.Step{Command: "project-cmd", Action: checkCommand}, "")
+		if err != nil {
+			return checkOut, fmt.Errorf("%q failed after resolving conflicts: %w", checkCommand, err)
+		}
+	}
+
+	args := append([]string{"add", "--"}, files...)
+	if addOut, err := exec.Run(time.Minute, "git", args...); err != nil {
+		return addOut, err
+	}
+
+	msg := fmt.Sprintf("Resolved merge conflicts in:\n- `%s`", strings.Join(files, "`\n- `"))
+	if err = i.AddComment(msg); err != nil {
+		log.Printf("Failed to comment resolved conflicts: %v", err)
+	}
+	return exec.Output{Stdout: msg}, nil
+}
+
+func (i *Routine) buildConflictPrompt(files []string) (string, error) {
+	hunks, err := exec.ConflictHunks(files)
+	if err != nil {
+		return "", err
+	}
+
+	ours, theirs := "HEAD", "MERGE_HEAD"
+	if !exec.IsMerging() {
+		theirs = "" // squash merge has no MERGE_HEAD
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Task:\nMerge of branches stopped with conflicts in %d files. Resolve them.\n", len(files))
+	b.WriteString("Keep intent of both sides. Remove all conflict markers (<<<<<<<, =======, >>>>>>>). " +
+		"Do not change anything else.\n\n")
+	fmt.Fprintf(&b, "# Issue #%d: %s\n\n", i.issue.Id, i.issue.Subject)
+	if theirs != "" {
+		fmt.Fprintf(&b, "# Commits on current branch:\n%s\n\n", commitMessages(theirs, ours))
+		fmt.Fprintf(&b, "# Commits being merged:\n%s\n\n", commitMessages(ours, theirs))
+	}
+	fmt.Fprintf(&b, "# Conflicts:\n```diff\n%s\n```\n", hunks)
+
+	return file.BuildPromptTextTmpFile(b.String())
+}
+
+// commitMessages lists subjects of commits that are in to but not in from.
+func commitMessages(from, to string) string {
+	out, err := exec.Run(time.Minute, "git", "log", "-n", "20", "--format=- %s", fmt.Sprintf("%s..%s", from, to))
+	if err != nil {
+		log.Printf("Failed to get commit messages %s..%s: %v", from, to, err)
+		return "(unknown)"
+	}
+	if out.Stdout == "" {
+		return "(none)"
+	}
+	return out.Stdout
+}
+
+// isConflict tells if error is merge conflict that was already handled (commented and aborted).
+func isConflict(err error) bool {
+	return errors.Is(err, exec.ErrMergeConflict)
+}
@@ -0,0 +1,68 @@
+package employee
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/andrejsstepanovs/andai/internal/ai"
+	"github.com/andrejsstepanovs/andai/internal/employee/actions"
+	"github.com/andrejsstepanovs/andai/internal/exec"
+	"github.com/andrejsstepanovs/andai/internal/lessons"
+	"github.com/andrejsstepanovs/andai/internal/settings"
+)
+
+// learnStep extracts durable lessons from finished issue and adds new ones to project lessons wiki page.
+func (i *Routine) learnStep(contextFile string) (exec.Output, error) {
+	page, err := i.model.APIGetWikiPage(i.project.Id, lessons.PageTitle)
+	if err != nil {
+		return exec.Output{}, err
+	}
+	known := make([]string, 0)
+	for _, lesson := range lessons.Parse(page) {
+		known = append(known, lesson.Text)
+	}
+
+	m := i.llmPool.ForCommand(settings.LlmModelNormal, "learn")
+	llmModel, err := ai.NewAI(m)
+	if err != nil {
+		return exec.Output{}, err
+	}
+	learned, err := actions.ExtractLessons(llmModel, contextFile, known)
+	if err != nil {
+		log.Printf("Failed to extract lessons: %v", err)
+		return exec.Output{}, err
+	}
+
+	updated, added := lessons.Update(page, learned, i.issue.Id)
+	if updated == page {
+		log.Printf("No new lessons learned from issue (%d)", i.issue.Id)
+		return exec.Output{Command: "learn", Stdout: "No new lessons"}, nil
+	}
+
+	comment := fmt.Sprintf("Lessons from #%d", i.issue.Id)
+	if err = i.model.APISaveWikiPage(i.project.Id, lessons.PageTitle, updated, comment); err != nil {
+		return exec.Output{}, err
+	}
+	log.Printf("Learned %d new lessons from issue (%d)", len(added), i.issue.Id)
+
+	out := fmt.Sprintf("Learned %d new lessons", len(added))
+	if len(added) > 0 {
+		out += ":\n- " + strings.Join(added, "\n- ")
+	}
+	return exec.Output{Command: "learn", Stdout: out}, nil
+}
+
+// projectLessons returns project lessons page entries if step context asks for them.
+func (i *Routine) projectLessons(step settings.Step) []lessons.Lesson {
+	if !slices.Contains(step.Context, settings.ContextLessons) {
+		return nil
+	}
+	page, err := i.model.APIGetWikiPage(i.project.Id, lessons.PageTitle)
+	if err != nil {
+		log.Printf("Failed to get project lessons: %v", err)
+		return nil
+	}
+	return lessons.Parse(page)
+}
@@ -0,0 +1,142 @@
+package employee
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/andrejsstepanovs/andai/internal/ai"
+	"github.com/andrejsstepanovs/andai/internal/exec"
+	model "github.com/andrejsstepanovs/andai/internal/redmine"
+	"github.com/andrejsstepanovs/andai/internal/settings"
+)
+
+// rebaseOnto rebases branch onto target branch. Aborts rebase if it fails, leaving branch as it was.
+func (i *Routine) rebaseOnto(branchName, ontoBranchName string) (exec.Output, error) {
+	_, err := i.workbench.CheckoutBranch(branchName)
+	if err != nil {
+		return exec.Output{}, fmt.Errorf("failed to checkout branch %q for rebase: %v", branchName, err)
+	}
+
+	out, err := exec.Run(10*time.Minute, "git", "rebase", ontoBranchName)
+	if err != nil {
+		log.Printf("Failed to rebase %q onto %q: %v, stderr: %s", branchName, ontoBranchName, err, out.Stderr)
+		// rebase conflicts can span many commits, they are not resolved automatically
+		if files, conflictErr := exec.ConflictedFiles(); conflictErr == nil && len(files) > 0 {
+			return out, i.abortOnConflict(fmt.Sprintf("rebasing %q onto %q", branchName, ontoBranchName), files)
+		}
+		if abortOut, abortErr := exec.Run(time.Minute, "git", "rebase", "--abort"); abortErr != nil {
+			log.Printf("Failed to abort rebase: %v, stderr: %s", abortErr, abortOut.Stderr)
+		}
+		return out, fmt.Errorf("failed to rebase %q onto %q: %w", branchName, ontoBranchName, err)
+	}
+	log.Printf("Rebased %q onto %q", branchName, ontoBranchName)
+	return out, nil
+}
+
+// mergeBranch merges branch into currently checked out parent branch using given strategy.
+// Conflicts are handled by project conflict_policy.
+func (i *Routine) mergeBranch(strategy, branchName, parentBranchName string) (exec.Output, error) {
+	var args []string
+	switch strategy {
+	case settings.MergeStrategyFFOnly, settings.MergeStrategyRebase:
+		return exec.Run(time.Minute, "git", "merge", "--ff-only", branchName)
+	case settings.MergeStrategySquash:
+		args = []string{"merge", "--squash", branchName}
+	default:
+		args = []string{"merge", branchName, "--no-edit"}
+	}
+
+	out, err := exec.Run(time.Minute, "git", args...)
+	if err != nil {
+		files, conflictErr := exec.ConflictedFiles()
+		if conflictErr != nil || len(files) == 0 {
+			return out, err
+		}
+		err = i.handleMergeConflict(fmt.Sprintf("merging %q into %q", branchName, parentBranchName), files)
+		if err != nil {
+			return out, err
+		}
+		if strategy != settings.MergeStrategySquash {
+			return exec.Run(time.Minute, "git", "commit", "--no-edit")
+		}
+	}
+
+	if strategy == settings.MergeStrategySquash {
+		return i.commitSquash(branchName, parentBranchName)
+	}
+	return out, nil
+}
+
+func (i *Routine) commitSquash(branchName, parentBranchName string) (exec.Output, error) {
+	// exit code 0 means nothing is staged
+	if out, err := exec.Run(time.Minute, "git", "diff", "--cached", "--quiet"); err == nil {
+		log.Printf("Nothing to squash from %q into %q", branchName, parentBranchName)
+		return out, nil
+	}
+
+	message := i.squashCommitMessage(branchName, parentBranchName)
+	return exec.Run(time.Minute, "git", "commit", "-m", message)
+}
+
+// squashCommitMessage builds squash commit message from issue. With `squash_message: llm` LLM summary of branch commits is added.
+func (i *Routine) squashCommitMessage(branchName, parentBranchName string) string {
+	message := fmt.Sprintf("#%d %s", i.issue.Id, i.issue.Subject)
+	if i.projectCfg.GetSquashMessage() != settings.SquashMessageLLM {
+		return message
+	}
+
+	summary, err := i.summarizeBranchCommits(branchName, parentBranchName)
+	if err != nil {
+		log.Printf("Failed to summarize branch commits, using issue subject only: %v", err)
+		return message
+	}
+	return fmt.Sprintf("%s\n\n%s", message, summary)
+}
+
+func (i *Routine) summarizeBranchCommits(branchName, parentBranchName string) (string, error) {
+	logOut, err := exec.Run(time.Minute, "git", "log", "--reverse", "--format=- %s%n%b", fmt.Sprintf("%s..%s", parentBranchName, branchName))
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(logOut.Stdout) == "" {
+		return "", errors.New("no commits in branch")
+	}
+
+	m := i.llmPool.ForCommand(settings.LlmModelNormal, "merge-into-parent")
+	llmModel, err := ai.NewAI(m)
+	if err != nil {
+		return "", err
+	}
+
+	prompt := fmt.Sprintf("These commits are squashed into one for task #%d %q.\n"+
+		"Write short commit message body (plain text, max 10 lines, no title) that summarizes what changed.\n"+
+		"Answer only with the message body.\n\n# Commits:\n%s", i.issue.Id, i.issue.Subject, logOut.Stdout)
+	ret, err := llmModel.Simple(prompt)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(ret.Stdout), nil
+}
+
+// saveMergedSHAs keeps `Last SHA` and `Parent SHA` pointing to commits that exist on parent branch.
+// Squash and rebase create new commits, so branch commits saved before merge would not show what landed.
+func (i *Routine) saveMergedSHAs(strategy, parentHeadSha string) error {
+	if strategy != settings.MergeStrategySquash && strategy != settings.MergeStrategyRebase {
+		return nil
+	}
+
+	headSha, err := i.workbench.GetLastCommit()
+	if err != nil {
+		return fmt.Errorf("failed to get merged head: %v", err)
+	}
+	if err = i.saveCustomFieldValue(model.CustomFieldParentSha, parentHeadSha, true); err != nil {
+		return fmt.Errorf("failed to save parent SHA: %v", err)
+	}
+	if err = i.saveCustomFieldValue(model.CustomFieldLastSha, headSha, true); err != nil {
+		return fmt.Errorf("failed to save last SHA: %v", err)
+	}
+	return nil
+}
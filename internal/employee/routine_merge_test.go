@@ -0,0 +1,150 @@
+package employee
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/andrejsstepanovs/andai/internal/exec"
+	model "github.com/andrejsstepanovs/andai/internal/redmine"
+	"github.com/andrejsstepanovs/andai/internal/redmine/mocks"
+	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/mattn/go-redmine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func git(t *testing.T, args ...string) string {
+	t.Helper()
+	out, err := exec.Run(10*time.Second, "git", args...)
+	require.NoError(t, err, out.Stderr)
+	return out.Stdout
+}
+
+func commitFile(t *testing.T, file, content, message string) string {
+	t.Helper()
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	git(t, "add", file)
+	git(t, "commit", "-q", "-m", message)
+	return git(t, "rev-parse", "HEAD")
+}
+
+// mergeRepo creates repository with "main" and "AI-1" branch that has two commits on top of it.
+// Working directory is changed into repository and "main" is checked out.
+func mergeRepo(t *testing.T) *Routine {
+	t.Helper()
+	originalWd, err := os.Getwd()
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, os.Chdir(originalWd)) })
+
+	dir := t.TempDir()
+	require.NoError(t, os.Chdir(dir))
+	git(t, "init", "-q", "-b", "main")
+	git(t, "config", "user.email", "test@example.com")
+	git(t, "config", "user.name", "Test")
+	commitFile(t, "main.txt", "main\n", "initial")
+	git(t, "checkout", "-q", "-b", "AI-1")
+	commitFile(t, "feature.txt", "one\n", "first change")
+	commitFile(t, "feature.txt", "one\ntwo\n", "second change")
+	git(t, "checkout", "-q", "main")
+
+	g := exec.NewGit(dir)
+	require.NoError(t, g.Open())
+	return &Routine{
+		workbench: &exec.Workbench{Git: g, WorkingDir: dir},
+		issue:     redmine.Issue{Id: 1, Subject: "Add feature"},
+	}
+}
+
+func commitSubjects(t *testing.T, ref string) []string {
+	t.Helper()
+	return strings.Split(git(t, "log", "--format=%s", ref), "\n")
+}
+
+func TestRoutine_MergeBranch_Squash(t *testing.T) {
+	routine := mergeRepo(t)
+
+	_, err := routine.mergeBranch(settings.MergeStrategySquash, "AI-1", "main")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"#1 Add feature", "initial"}, commitSubjects(t, "main"))
+	assert.Equal(t, "one\ntwo", git(t, "show", "main:feature.txt"))
+	assert.Empty(t, git(t, "status", "--porcelain"))
+
+	// branch already landed, nothing is staged and nothing is committed
+	_, err = routine.mergeBranch(settings.MergeStrategySquash, "AI-1", "main")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"#1 Add feature", "initial"}, commitSubjects(t, "main"))
+}
+
+func TestRoutine_MergeBranch_Rebase(t *testing.T) {
+	routine := mergeRepo(t)
+	parentHead := commitFile(t, "main.txt", "main moved\n", "parent change")
+
+	_, err := routine.rebaseOnto("AI-1", "main")
+	require.NoError(t, err)
+	_, err = routine.workbench.CheckoutBranch("main")
+	require.NoError(t, err)
+	_, err = routine.mergeBranch(settings.MergeStrategyRebase, "AI-1", "main")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"second change", "first change", "parent change", "initial"}, commitSubjects(t, "main"))
+	assert.Empty(t, git(t, "rev-list", "--merges", "main"), "history is linear")
+	assert.Equal(t, parentHead, git(t, "rev-parse", "main~2"))
+	assert.Equal(t, git(t, "rev-parse", "AI-1"), git(t, "rev-parse", "main"))
+}
+
+func TestRoutine_MergeBranch_FFOnly(t *testing.T) {
+	t.Run("fast forward", func(t *testing.T) {
+		routine := mergeRepo(t)
+
+		_, err := routine.mergeBranch(settings.MergeStrategyFFOnly, "AI-1", "main")
+		require.NoError(t, err)
+		assert.Equal(t, git(t, "rev-parse", "AI-1"), git(t, "rev-parse", "main"))
+	})
+
+	t.Run("parent moved", func(t *testing.T) {
+		routine := mergeRepo(t)
+		parentHead := commitFile(t, "main.txt", "main moved\n", "parent change")
+
+		_, err := routine.mergeBranch(settings.MergeStrategyFFOnly, "AI-1", "main")
+		require.Error(t, err)
+		assert.Equal(t, parentHead, git(t, "rev-parse", "main"), "parent is not changed")
+		assert.Equal(t, "main", git(t, "branch", "--show-current"))
+		assert.Empty(t, git(t, "status", "--porcelain"), "no merge is left in progress")
+	})
+}
+
+func TestRoutine_SaveMergedSHAs(t *testing.T) {
+	querySelectValue := regexp.QuoteMeta("SELECT id FROM custom_values WHERE customized_type = 'Issue' AND customized_id = ? AND custom_field_id = ?")
+	queryUpdateValue := regexp.QuoteMeta("UPDATE custom_values SET value = ? WHERE id = ?")
+
+	routine := mergeRepo(t)
+	parentHead := git(t, "rev-parse", "main")
+	_, err := routine.mergeBranch(settings.MergeStrategySquash, "AI-1", "main")
+	require.NoError(t, err)
+	squashHead := git(t, "rev-parse", "main")
+
+	conn, db, err := sqlmock.New()
+	require.NoError(t, err)
+	defer conn.Close()
+	db.ExpectQuery(querySelectValue).WithArgs(1, 10).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(100))
+	db.ExpectExec(queryUpdateValue).WithArgs(parentHead, 100).WillReturnResult(sqlmock.NewResult(0, 1))
+	db.ExpectQuery(querySelectValue).WithArgs(1, 11).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(101))
+	db.ExpectExec(queryUpdateValue).WithArgs(squashHead, 101).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	routine.issue.CustomFields = []*redmine.CustomField{
+		{Id: 10, Name: model.CustomFieldParentSha, Value: ""},
+		{Id: 11, Name: model.CustomFieldLastSha, Value: ""},
+	}
+	api := mocks.NewAPIInterface(t)
+	api.On("Issue", 1).Return(&routine.issue, nil).Twice()
+	routine.model = model.NewModel(conn, api)
+
+	require.NoError(t, routine.saveMergedSHAs(settings.MergeStrategyMerge, parentHead), "merge keeps branch commits")
+	require.NoError(t, routine.saveMergedSHAs(settings.MergeStrategySquash, parentHead))
+	assert.NoError(t, db.ExpectationsWereMet())
+}
@@ -0,0 +1,66 @@
+package employee
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/andrejsstepanovs/andai/internal/ai"
+	"github.com/andrejsstepanovs/andai/internal/exec"
+	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/andrejsstepanovs/andai/internal/truncate"
+)
+
+// summarizeInputFactor is how much more output (than max_output_tokens) is given to LLM to summarize.
+const summarizeInputFactor = 8
+
+// limitOutput shrinks stdout and stderr so they fit into max_output_tokens before going into prompts and history.
+func (i *Routine) limitOutput(limit settings.OutputLimit, output exec.Output) exec.Output {
+	if limit.MaxOutputTokens <= 0 {
+		return output
+	}
+	output.Stdout = i.limitText(limit, "stdout", output.Stdout)
+	output.Stderr = i.limitText(limit, "stderr", output.Stderr)
+	return output
+}
+
+func (i *Routine) limitText(limit settings.OutputLimit, name, text string) string {
+	if truncate.Fits(text, limit.MaxOutputTokens) {
+		return text
+	}
+	deduped := truncate.Dedup(text)
+	if truncate.Fits(deduped, limit.MaxOutputTokens) {
+		log.Printf("Deduplicated %s to fit into %d tokens", name, limit.MaxOutputTokens)
+		return deduped
+	}
+
+	if limit.SummarizeOutput {
+		summary, err := i.summarizeOutput(limit, deduped)
+		if err == nil && truncate.Fits(summary, limit.MaxOutputTokens) {
+			log.Printf("Summarized %s to fit into %d tokens", name, limit.MaxOutputTokens)
+			return summary
+		}
+		log.Printf("Failed to summarize %s, truncating instead: %v", name, err)
+	}
+
+	log.Printf("Truncating %s (~%d tokens) to %d tokens using %q", name, truncate.EstimateTokens(deduped), limit.MaxOutputTokens, limit.GetTruncate())
+	return truncate.Truncate(deduped, limit.MaxOutputTokens, limit.GetTruncate())
+}
+
+func (i *Routine) summarizeOutput(limit settings.OutputLimit, text string) (string, error) {
+	llmModel, err := ai.NewAI(i.llmPool.Get(settings.LlmModelNormal))
+	if err != nil {
+		return "", err
+	}
+
+	input := truncate.Truncate(text, limit.MaxOutputTokens*summarizeInputFactor, limit.GetTruncate())
+	words := limit.MaxOutputTokens * 3 / 4
+	prompt := fmt.Sprintf("Summarize this command output in less than %d words. "+
+		"Keep exact error messages, failed test names and file:line locations. "+
+		"Skip passing tests and progress noise. Answer only with the summary.\n\n```\n%s\n```", words, input)
+
+	ret, err := llmModel.Simple(prompt)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Output summary (original ~%d tokens):\n%s", truncate.EstimateTokens(text), ret.Stdout), nil
+}
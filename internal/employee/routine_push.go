@@ -0,0 +1,80 @@
+package employee
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/andrejsstepanovs/andai/internal/exec"
+	"github.com/andrejsstepanovs/andai/internal/settings"
+)
+
+// pushBranch pushes local branch to configured remote. Force push is used only if allowed for branch.
+func (i *Routine) pushBranch(branchName string) (exec.Output, error) {
+	remote := i.projectCfg.Remote
+	if err := exec.EnsureRemote(remote.GetName(), remote.URL); err != nil {
+		return exec.Output{}, err
+	}
+
+	remoteBranch := remote.RemoteBranch(branchName)
+	force := remote.CanForce(branchName, i.projectCfg.FinalBranch)
+	out, err := exec.Push(remote.GetName(), branchName, remoteBranch, force)
+	if err != nil {
+		log.Printf("Push failed: %v, stderr: %s", err, out.Stderr)
+		return out, err
+	}
+
+	msg := fmt.Sprintf("Pushed branch %q to %s/%s", branchName, remote.GetName(), remoteBranch)
+	log.Println(msg)
+	return exec.Output{Command: out.Command, Stdout: msg}, nil
+}
+
+// autoPush pushes branch if project remote push_on has given event.
+// Failure is commented in issue, it does not fail the workflow (code is already merged / committed locally).
+func (i *Routine) autoPush(event, branchName string) {
+	if !i.projectCfg.Remote.PushesOn(event) {
+		return
+	}
+	out, err := i.pushBranch(branchName)
+	if err == nil {
+		return
+	}
+	msg := fmt.Sprintf("**Push failed** (%s) for branch %q: %v\n```\n%s\n```", event, branchName, err, out.Stderr)
+	if errComment := i.AddComment(msg); errComment != nil {
+		log.Printf("Failed to comment push failure: %v", errComment)
+	}
+}
+
+// issueBranchHead returns issue branch head if it should be pushed on every commit.
+func (i *Routine) issueBranchHead() string {
+	if !i.projectCfg.Remote.PushesOn(settings.PushOnEveryCommit) {
+		return ""
+	}
+	return exec.RevParse(i.workbench.GetIssueBranchName(i.issue))
+}
+
+// pushNewCommits pushes issue branch if step moved its head.
+func (i *Routine) pushNewCommits(headBefore string) {
+	if !i.projectCfg.Remote.PushesOn(settings.PushOnEveryCommit) {
+		return
+	}
+	branchName := i.workbench.GetIssueBranchName(i.issue)
+	head := exec.RevParse(branchName)
+	if head == "" || head == headBefore {
+		return
+	}
+	i.autoPush(settings.PushOnEveryCommit, branchName)
+}
+
+func (i *Routine) gitPushStep(workflowStep settings.Step) (exec.Output, error) {
+	var branchName string
+	switch workflowStep.Action {
+	case "parent":
+		parentBranches := i.getTargetBranch()
+		branchName = parentBranches[len(parentBranches)-1]
+	case "final":
+		branchName = i.projectCfg.FinalBranch
+	default:
+		branchName = i.workbench.GetIssueBranchName(i.issue)
+	}
+	return i.pushBranch(branchName)
+}
@@ -0,0 +1,113 @@
+package employee
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/andrejsstepanovs/andai/internal/employee/actions/file"
+	"github.com/andrejsstepanovs/andai/internal/exec"
+	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/andrejsstepanovs/andai/internal/testresult"
+)
+
+// maxReportFailures limits how many failures are listed in comment and history.
+const maxReportFailures = 20
+
+// reportFilePath returns command report file path. Relative path is resolved against command workdir.
+func reportFilePath(command settings.ProjectCommand, workdir string) string {
+	if filepath.IsAbs(command.ReportFile) {
+		return command.ReportFile
+	}
+	return filepath.Join(workdir, command.ReportFile)
+}
+
+// removeStaleReport deletes report file left by previous run, so it is never parsed as result of this one.
+func removeStaleReport(command settings.ProjectCommand, workdir string) {
+	if command.Parser == "" || command.ReportFile == "" {
+		return
+	}
+	reportFile := reportFilePath(command, workdir)
+	err := os.Remove(reportFile)
+	if err != nil && !os.IsNotExist(err) {
+		log.Printf("Failed to remove old %q report file %q: %v", command.Name, reportFile, err)
+	}
+}
+
+// parseCommandReport parses project command output (or its report file) with configured parser.
+// Returns false if command has no parser or report could not be parsed. Raw output is used then.
+func (i *Routine) parseCommandReport(command settings.ProjectCommand, workdir string, output exec.Output) (testresult.Report, bool) {
+	if command.Parser == "" {
+		return testresult.Report{}, false
+	}
+
+	data := []byte(output.Stdout)
+	if command.ReportFile != "" {
+		reportFile := reportFilePath(command, workdir)
+		content, err := os.ReadFile(reportFile)
+		if err != nil {
+			log.Printf("Failed to read %q report file %q: %v", command.Name, reportFile, err)
+			return testresult.Report{}, false
+		}
+		data = content
+	}
+
+	report, err := testresult.Parse(command.Parser, data)
+	if err != nil {
+		log.Printf("Failed to parse %q output, using raw output: %v", command.Name, err)
+		return testresult.Report{}, false
+	}
+	log.Printf("Parsed %q output: %d passed, %d failed, %d skipped", command.Name, report.Passed, report.Failed, report.Skipped)
+
+	return report, true
+}
+
+// rememberReportFailures puts failures into history, failed files into aider context and comments compact summary.
+// Step comment and remember flags are respected when command succeeded, as RememberOutput will handle them then.
+func (i *Routine) rememberReportFailures(step settings.Step, command settings.ProjectCommand, report testresult.Report, commandFailed bool) {
+	summary := report.Summary(maxReportFailures)
+	msg := fmt.Sprintf("Command: **%s %s**\n<result>\n%s\n</result>", step.Command, step.Action, summary)
+
+	if commandFailed || !step.Remember {
+		i.history = append(i.history, msg)
+	}
+	if commandFailed || !step.Comment {
+		if err := i.AddComment(fmt.Sprintf("`%s` failures:\n\n%s", command.Name, summary)); err != nil {
+			log.Printf("Failed to comment %q failures: %v", command.Name, err)
+		}
+	}
+
+	files := report.Files()
+	if len(files) == 0 {
+		return
+	}
+	allPossiblePaths, err := exec.GetAllPossiblePaths(i.projectCfg, i.projectRepo, false)
+	if err != nil {
+		log.Printf("Failed to get all possible paths: %v", err)
+		return
+	}
+	foundFiles, err := file.NewFileFinder(allPossiblePaths).FindFilesInText(strings.Join(files, "\n"))
+	if err != nil {
+		log.Printf("Failed to find failed files: %v", err)
+		return
+	}
+
+	i.contextFiles = appendUnique(i.contextFiles, foundFiles.GetAbsolutePaths()...)
+	log.Printf("Added %d failed files to context: %v", len(foundFiles), foundFiles.GetAbsolutePaths())
+}
+
+func appendUnique(list []string, values ...string) []string {
+	seen := make(map[string]bool, len(list))
+	for _, v := range list {
+		seen[v] = true
+	}
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -0,0 +1,86 @@
+package employee
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/andrejsstepanovs/andai/internal/ai"
+	"github.com/andrejsstepanovs/andai/internal/employee/actions"
+	"github.com/andrejsstepanovs/andai/internal/employee/actions/models"
+	"github.com/andrejsstepanovs/andai/internal/exec"
+	"github.com/andrejsstepanovs/andai/internal/settings"
+)
+
+// reviewStep lets LLM review issue branch diff, comments findings and fails if any finding reaches threshold.
+func (i *Routine) reviewStep(step settings.Step, contextFile string) (exec.Output, error) {
+	m := i.llmPool.ForCommand(settings.LlmModelNormal, "review")
+	llmModel, err := ai.NewAI(m)
+	if err != nil {
+		return exec.Output{}, err
+	}
+
+	review, err := actions.ReviewCode(llmModel, contextFile)
+	if err != nil {
+		log.Printf("Failed to review code: %v", err)
+		return exec.Output{}, err
+	}
+
+	sha, err := i.workbench.GetLastCommit()
+	if err != nil {
+		return exec.Output{}, fmt.Errorf("failed to get last commit: %v", err)
+	}
+
+	threshold := step.GetReviewThreshold()
+	blocking := review.Blocking(threshold)
+	comment := i.formatReview(review, threshold, len(blocking), sha)
+	if err = i.AddComment(comment); err != nil {
+		return exec.Output{}, fmt.Errorf("failed to comment review: %v", err)
+	}
+
+	out := exec.Output{Command: "review", Stdout: comment}
+	if len(blocking) > 0 {
+		log.Printf("Review found %d findings at or above %q", len(blocking), threshold)
+		return out, ErrNegativeOutcome
+	}
+	return out, nil
+}
+
+// formatReview renders findings as markdown with links to files in repository browser.
+func (i *Routine) formatReview(review models.Review, threshold string, blocking int, sha string) string {
+	branchName := i.workbench.GetIssueBranchName(i.issue)
+	status := "passed"
+	if blocking > 0 {
+		status = fmt.Sprintf("failed, %d findings at `%s` or above", blocking, threshold)
+	}
+
+	txt := []string{fmt.Sprintf("**Code review** of branch %q: %s", branchName, status)}
+	if summary := strings.TrimSpace(review.Summary); summary != "" {
+		txt = append(txt, "", summary)
+	}
+	if len(review.Findings) == 0 {
+		txt = append(txt, "", "No findings.")
+		return strings.Join(txt, "\n")
+	}
+
+	txt = append(txt, "")
+	for n, finding := range review.Sorted() {
+		line := fmt.Sprintf("%d. **%s** %s - %s", n+1, finding.Severity, i.reviewLocation(finding, sha), strings.TrimSpace(finding.Message))
+		if suggestion := strings.TrimSpace(finding.Suggestion); suggestion != "" {
+			line += "\n   Suggestion: " + suggestion
+		}
+		txt = append(txt, line)
+	}
+	return strings.Join(txt, "\n")
+}
+
+func (i *Routine) reviewLocation(finding models.ReviewFinding, sha string) string {
+	if finding.File == "" {
+		return "(general)"
+	}
+	url := fmt.Sprintf("/projects/%s/repository/%s/revisions/%s/entry/%s", i.project.Identifier, i.project.Identifier, sha, finding.File)
+	if finding.Line > 0 {
+		return fmt.Sprintf("[%s:%d](%s#L%d)", finding.File, finding.Line, url, finding.Line)
+	}
+	return fmt.Sprintf("[%s](%s)", finding.File, url)
+}
@@ -0,0 +1,101 @@
+package employee
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/andrejsstepanovs/andai/internal/codesearch"
+	"github.com/andrejsstepanovs/andai/internal/exec"
+	"github.com/andrejsstepanovs/andai/internal/settings"
+)
+
+// searchComments is how many latest issue comments are used in search query.
+const searchComments = 3
+
+// contextSearch finds files relevant to the issue with local lexical search and adds them to context files.
+func (i *Routine) contextSearch(step settings.Step) (exec.Output, error) {
+	index, err := i.searchIndex()
+	if err != nil {
+		return exec.Output{}, fmt.Errorf("failed to prepare search index: %v", err)
+	}
+
+	query, err := i.searchQuery()
+	if err != nil {
+		return exec.Output{}, err
+	}
+
+	results := index.Search(query, step.GetSearchLimit())
+	if len(results) == 0 {
+		log.Println("Search found no relevant files")
+		return exec.Output{Command: "context-search", Stdout: "No relevant files found"}, nil
+	}
+
+	root := i.searchRoot()
+	lines := make([]string, 0, len(results))
+	files := make([]string, 0, len(results))
+	for n, result := range results {
+		files = append(files, filepath.Join(root, filepath.FromSlash(result.File)))
+		lines = append(lines, fmt.Sprintf("%d. %s", n+1, result.Explain(5)))
+	}
+	i.contextFiles = appendUnique(i.contextFiles, files...)
+	log.Printf("Search added %d files to context: %v", len(files), files)
+
+	return exec.Output{Command: "context-search", Stdout: "Relevant files:\n" + strings.Join(lines, "\n")}, nil
+}
+
+// searchQuery is issue subject, description and latest comments.
+func (i *Routine) searchQuery() (string, error) {
+	parts := []string{i.issue.Subject, i.issue.Description}
+	comments, err := i.getComments()
+	if err != nil {
+		return "", err
+	}
+	if len(comments) > searchComments {
+		comments = comments[len(comments)-searchComments:]
+	}
+	for _, comment := range comments {
+		parts = append(parts, comment.Text)
+	}
+	return strings.Join(parts, "\n"), nil
+}
+
+// searchIndex loads stored project index and re-indexes files changed since it was built.
+func (i *Routine) searchIndex() (*codesearch.Index, error) {
+	sha, err := i.workbench.GetLastCommit()
+	if err != nil {
+		return nil, err
+	}
+	cacheDir := codesearch.CacheDir()
+	index := codesearch.Load(cacheDir, i.project.Identifier)
+	if index.SHA == sha {
+		return index, nil
+	}
+
+	tracked, err := i.workbench.GetTrackedFiles()
+	if err != nil {
+		return nil, err
+	}
+	changed := tracked
+	if index.SHA != "" {
+		if changed, err = i.workbench.GetDiffFiles(index.SHA, sha); err != nil {
+			log.Printf("Rebuilding search index, failed to get changed files: %v", err)
+			index, changed = codesearch.NewIndex(), tracked
+		}
+	}
+	log.Printf("Indexing %d files for search", len(changed))
+
+	index.Update(i.searchRoot(), sha, tracked, changed)
+	if err = codesearch.Save(cacheDir, i.project.Identifier, index); err != nil {
+		log.Printf("Failed to save search index: %v", err)
+	}
+	return index, nil
+}
+
+func (i *Routine) searchRoot() string {
+	if i.workbench.WorkingDir == "" {
+		return "."
+	}
+	return i.workbench.WorkingDir
+}
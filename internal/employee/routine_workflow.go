@@ -5,17 +5,21 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/andrejsstepanovs/andai/internal/ai"
+	"github.com/andrejsstepanovs/andai/internal/attempts"
 	"github.com/andrejsstepanovs/andai/internal/employee/actions"
 	"github.com/andrejsstepanovs/andai/internal/employee/actions/file"
 	"github.com/andrejsstepanovs/andai/internal/employee/actions/models"
 	"github.com/andrejsstepanovs/andai/internal/employee/knowledge"
 	"github.com/andrejsstepanovs/andai/internal/exec"
+	"github.com/andrejsstepanovs/andai/internal/monitor"
 	model "github.com/andrejsstepanovs/andai/internal/redmine"
 	redminemodels "github.com/andrejsstepanovs/andai/internal/redmine/models"
+	"github.com/andrejsstepanovs/andai/internal/sandbox"
 	"github.com/andrejsstepanovs/andai/internal/settings"
 )
 
@@ -35,41 +39,80 @@ func (i *Routine) ExecuteWorkflow() (bool, error) {
 	}
 
 	if needSetup {
-		parentBranches := i.getTargetBranch()
-		_, err := i.workbench.PrepareWorkplace(parentBranches...)
+		prepared, err := i.prepareWorkplace()
 		if err != nil {
 			log.Printf("Failed to prepare workplace: %v", err)
 			return false, err
 		}
+		if !prepared {
+			return false, nil
+		}
 
 		err = i.saveCustomFieldLastCommitSHA(model.CustomFieldParentSha)
 		if err != nil {
 			return false, fmt.Errorf("failed to save parent SHA: %v", err)
 		}
+
+		// templated name depends on issue subject, keep it stable once branch exists
+		if i.projectCfg.BranchTemplate != "" {
+			err = i.saveCustomFieldValue(model.CustomFieldBranch, i.workbench.GetIssueBranchName(i.issue), false)
+			if err != nil {
+				return false, fmt.Errorf("failed to save branch name: %v", err)
+			}
+		}
+
+		i.startAttempt()
+	}
+
+	attachmentsDir, err := os.MkdirTemp("", fmt.Sprintf("andai-%d-attachments-", i.issue.Id))
+	if err != nil {
+		return false, fmt.Errorf("failed to create attachments dir: %v", err)
 	}
+	defer os.RemoveAll(attachmentsDir)
+	i.attachmentsDir = attachmentsDir
 
 	for stepIndex, step := range i.job.Steps {
+		if monitor.Default.SkipRequested(i.issue.Id) {
+			log.Printf("Skip requested, leaving issue (%d) in %q", i.issue.Id, i.state.Name)
+			i.finishAttempt(attempts.VerdictSkipped, "")
+			return false, ErrSkipped
+		}
 		log.Printf("Step: %d / %d", stepIndex+1, len(i.job.Steps))
+		monitor.Default.StartStep(stepIndex+1, len(i.job.Steps), step.Command, step.Action)
 
 		step.History = i.history
 		if len(i.contextFiles) > 0 {
 			step.ContextFiles = i.contextFiles
 		}
 
+		headBefore := i.issueBranchHead()
+		stopStreaming := i.streamStepOutput(stepIndex, step)
 		executionOutput, err := i.executeWorkflowStep(step)
+		stopStreaming()
+		monitor.Default.FinishStep(err)
+		i.recordStep(step.Command, step.Action, err)
 		if err != nil {
 			if errors.Is(err, ErrNegativeOutcome) {
 				log.Printf("Negative outcome, skipping remaining steps and moving issue to negative path state.")
+				i.finishAttempt(attempts.VerdictNegative, executionOutput.Stdout)
+				return false, nil
+			}
+			if isConflict(err) {
+				log.Printf("Merge conflict, skipping remaining steps and moving issue to negative path state.")
+				i.finishAttempt(attempts.VerdictConflict, err.Error())
 				return false, nil
 			}
 			log.Printf("Failed to action step: %v", err)
+			i.finishAttempt(attempts.VerdictFailed, err.Error())
 			return false, err
 		}
 		i.RememberOutput(step, executionOutput)
+		i.pushNewCommits(headBefore)
 
 		log.Println("Success")
 	}
 
+	i.finishAttempt(attempts.VerdictSuccess, "")
 	if needSetup {
 		err := i.model.APISyncRepo(i.project)
 		if err != nil {
@@ -80,12 +123,48 @@ func (i *Routine) ExecuteWorkflow() (bool, error) {
 	return true, nil
 }
 
+// streamStepOutput streams step command output into run log (and console) while step is running.
+// Returned func stops streaming.
+func (i *Routine) streamStepOutput(stepIndex int, step settings.Step) func() {
+	if i.runLog == nil {
+		return func() {}
+	}
+
+	stepName := strings.TrimSpace(fmt.Sprintf("%s %s", step.Command, step.Action))
+	i.runLog.Step(fmt.Sprintf("#%d %d/%d %s", i.issue.Id, stepIndex+1, len(i.job.Steps), stepName))
+	previous := exec.SetStream(i.runLog.Line)
+
+	return func() {
+		exec.SetStream(previous)
+	}
+}
+
 func (i *Routine) saveCustomFieldLastCommitSHA(fieldName string) error {
+	// Get the last commit SHA and save it to the custom field
+	currentCommitSku, err := i.workbench.GetLastCommit()
+	if err != nil {
+		return fmt.Errorf("failed to get last commit: %v", err)
+	}
+	return i.saveCustomFieldValue(fieldName, currentCommitSku, false)
+}
+
+// customFieldValue returns issue custom field value. Empty if not set.
+func (i *Routine) customFieldValue(fieldName string) string {
+	for _, field := range i.issue.CustomFields {
+		if value, ok := field.Value.(string); ok && field.Name == fieldName {
+			return value
+		}
+	}
+	return ""
+}
+
+// saveCustomFieldValue stores value in issue custom field. Existing value is kept unless overwrite is true.
+func (i *Routine) saveCustomFieldValue(fieldName, value string, overwrite bool) error {
 	var customFieldID int
 	for _, issueField := range i.issue.CustomFields {
 		if issueField.Name == fieldName {
 			customFieldID = issueField.Id
-			if issueField.Value != nil && issueField.Value.(string) != "" {
+			if !overwrite && issueField.Value != nil && issueField.Value.(string) != "" {
 				return nil // No need to update if the field already has a value
 			}
 		}
@@ -103,24 +182,21 @@ func (i *Routine) saveCustomFieldLastCommitSHA(fieldName string) error {
 			}
 		}
 	}
+	if customFieldID == 0 {
+		return fmt.Errorf("custom field %q not found, run `andai setup custom-fields`", fieldName)
+	}
 
 	customValueID, err := i.model.DBFindCustomFieldValueID(i.issue.Id, customFieldID)
 	if err != nil {
 		return fmt.Errorf("failed to find custom field value ID: %v", err)
 	}
 
-	// Get the last commit SHA and save it to the custom field
-	currentCommitSku, err := i.workbench.GetLastCommit()
-	if err != nil {
-		return fmt.Errorf("failed to get last commit: %v", err)
-	}
-
 	if customValueID > 0 {
 		//log.Printf("Found custom field value ID: %v", customValueID)
-		err = i.model.DBUpdateCustomFieldValue(customValueID, currentCommitSku)
+		err = i.model.DBUpdateCustomFieldValue(customValueID, value)
 	} else {
 		//log.Printf("No custom field value ID: %v", customFieldID)
-		err = i.model.DBInsertCustomFieldValue(i.issue.Id, customFieldID, currentCommitSku)
+		err = i.model.DBInsertCustomFieldValue(i.issue.Id, customFieldID, value)
 	}
 
 	if err != nil {
@@ -151,6 +227,15 @@ func (i *Routine) saveCustomFieldLastCommitSHA(fieldName string) error {
 func (i *Routine) executeWorkflowStep(workflowStep settings.Step) (exec.Output, error) {
 	log.Println(workflowStep.String("Execute Step"))
 
+	// review is always about branch changes
+	if workflowStep.Command == "review" && !slices.Contains(workflowStep.Context, settings.ContextBranchDiff) {
+		workflowStep.Context = append(slices.Clone(workflowStep.Context), settings.ContextBranchDiff)
+	}
+	// learn is mostly about what went wrong before
+	if workflowStep.Command == "learn" && !slices.Contains(workflowStep.Context, settings.ContextPreviousAttempts) {
+		workflowStep.Context = append(slices.Clone(workflowStep.Context), settings.ContextPreviousAttempts)
+	}
+
 	comments, err := i.getComments()
 	if err != nil {
 		log.Printf("Failed to get comments: %v", err)
@@ -187,6 +272,11 @@ func (i *Routine) executeWorkflowStep(workflowStep settings.Step) (exec.Output,
 		Comments:          comments,
 		ParentComments:    parentComments,
 		Step:              workflowStep,
+		Attachments:       i.model,
+		PreviousAttempts:  i.previousAttempts(workflowStep),
+		Lessons:           i.projectLessons(workflowStep),
+		AttachmentsDir:    i.attachmentsDir,
+		Vision:            settings.IsLlmCommand(workflowStep.Command) && i.llmPool.ForCommand(settings.LlmModelNormal, workflowStep.Command).Vision,
 	}
 
 	contextFile, err := understanding.BuildIssueKnowledgeTmpFile()
@@ -233,7 +323,11 @@ func (i *Routine) executeCommand(workflowStep settings.Step, contextFile string)
 			}, nil
 		},
 		"git": func(step settings.Step, _ string) (exec.Output, error) {
-			return exec.Exec(step.Command, time.Minute, step.Action)
+			args, err := exec.SplitArgs(step.Action)
+			if err != nil {
+				return exec.Output{Command: "git " + step.Action}, fmt.Errorf("invalid git action: %v", err)
+			}
+			return exec.Run(time.Minute, "git", args...)
 		},
 		"create-issues": func(step settings.Step, contextFile string) (exec.Output, error) {
 			return i.createIssueCommand(step, contextFile)
@@ -254,10 +348,23 @@ func (i *Routine) executeCommand(workflowStep settings.Step, contextFile string)
 			if success {
 				return exec.Output{Stdout: "Positive outcome"}, nil
 			}
-			return exec.Output{Stdout: "Negative"}, ErrNegativeOutcome
+			_, reason := actions.EvaluationReason(resp.Stdout)
+			return exec.Output{Stdout: strings.TrimSpace("Negative. " + reason)}, ErrNegativeOutcome
+		},
+		"learn": func(_ settings.Step, contextFile string) (exec.Output, error) {
+			return i.learnStep(contextFile)
+		},
+		"merge-into-parent": func(step settings.Step, _ string) (exec.Output, error) {
+			return i.mergeIntoParent(i.projectCfg.GetMergeStrategy(step.Action), i.projectCfg.DeleteBranchAfterMerge)
+		},
+		"git-push": func(step settings.Step, _ string) (exec.Output, error) {
+			return i.gitPushStep(step)
 		},
-		"merge-into-parent": func(_ settings.Step, _ string) (exec.Output, error) {
-			return i.mergeIntoParent(i.projectCfg.DeleteBranchAfterMerge)
+		"resolve-conflicts": func(step settings.Step, _ string) (exec.Output, error) {
+			return i.resolveConflictsStep(step)
+		},
+		"review": func(step settings.Step, contextFile string) (exec.Output, error) {
+			return i.reviewStep(step, contextFile)
 		},
 		"bash": func(step settings.Step, _ string) (exec.Output, error) {
 			return i.runBash(step)
@@ -293,6 +400,9 @@ func (i *Routine) executeCommand(workflowStep settings.Step, contextFile string)
 			}
 			return i.findMentionedFiles(contextFile)
 		},
+		"context-search": func(step settings.Step, _ string) (exec.Output, error) {
+			return i.contextSearch(step)
+		},
 		"ai": func(_ settings.Step, contextFile string) (exec.Output, error) {
 			return i.simpleAI(contextFile)
 		},
@@ -348,7 +458,7 @@ func (i *Routine) findMentionedCommits(contextFile string) ([]string, error) {
 func (i *Routine) findCommitPatches(commits []string) (exec.Output, error) {
 	patches := make([]string, 0)
 	for n, commit := range commits {
-		execOut, err := exec.Exec("git", time.Minute, "format-patch", "-1", "--stdout", "--no-binary", "--no-stat", commit)
+		execOut, err := exec.Run(time.Minute, "git", "format-patch", "-1", "--stdout", "--no-binary", "--no-stat", commit)
 		if err != nil {
 			log.Printf("Failed to get commit patch for %q: %v", commit, err)
 			continue
@@ -377,7 +487,18 @@ func (i *Routine) runProjectCmd(workflowStep settings.Step, contextFile string)
 		arguments = parts[1:]
 	}
 
-	ret, err := exec.Exec(cmd, time.Minute*30, arguments...)
+	options := commandOptions(i.projectCfg.CommandRuntime(command))
+	options.Sandbox = sandbox.For(workflowStep.Command)
+	removeStaleReport(command, options.Dir)
+	ret, err := exec.RunWithOptions(options, cmd, arguments...)
+
+	if report, ok := i.parseCommandReport(command, options.Dir, ret); ok && report.HasFailures() {
+		// raw logs are in run log, only compact failures go further
+		ret.Stdout = report.Summary(maxReportFailures)
+		ret.Stderr = ""
+		i.rememberReportFailures(workflowStep, command, report, err != nil && !command.IgnoreError)
+	}
+	ret = i.limitOutput(command.OutputLimit, ret)
 
 	if err != nil {
 		hardErr := i.checkCommandHardFailure(err, parts)
@@ -426,6 +547,24 @@ func (i *Routine) runProjectCmd(workflowStep settings.Step, contextFile string)
 	return ret, nil
 }
 
+// commandOptions converts project command runtime settings into exec options.
+func commandOptions(runtime settings.Runtime) exec.Options {
+	const mb = 1024 * 1024
+	return exec.Options{
+		Dir:     runtime.Workdir,
+		Env:     runtime.EnvList(),
+		Timeout: runtime.GetTimeout(),
+		Limits: exec.Limits{
+			CPUSeconds:    uint64(runtime.Limits.CPUSeconds),      // nolint:gosec
+			MemoryBytes:   uint64(runtime.Limits.MemoryMB) * mb,   // nolint:gosec
+			FileSizeBytes: uint64(runtime.Limits.FileSizeMB) * mb, // nolint:gosec
+			Processes:     uint64(runtime.Limits.Processes),       // nolint:gosec
+			CPUs:          runtime.Limits.CPUs,
+		},
+		MaxOutputBytes: runtime.Limits.OutputKB * 1024,
+	}
+}
+
 func (i *Routine) checkCommandHardFailure(err error, command []string) error {
 	failedIf := [][]string{
 		{"make:", "No rule to make target"},
@@ -446,14 +585,10 @@ func (i *Routine) checkCommandHardFailure(err error, command []string) error {
 	return nil
 }
 
+// runBash runs step action as is in configured shell, so quotes, pipes and redirects work.
 func (i *Routine) runBash(workflowStep settings.Step) (exec.Output, error) {
-	parts := strings.Split(workflowStep.Action, " ")
-	cmd := parts[0]
-	arguments := make([]string, 0)
-	if len(parts) > 1 {
-		arguments = parts[1:]
-	}
-	ret, err := exec.Exec(cmd, time.Minute*30, arguments...)
+	ret, err := exec.Exec(workflowStep.Action, time.Minute*30)
+	ret = i.limitOutput(workflowStep.OutputLimit, ret)
 	if err != nil {
 		return ret, err
 	}
@@ -676,11 +811,11 @@ func (i *Routine) aiderArchitect(workflowStep settings.Step, contextFile string)
 		return architectResult, err
 	}
 	// because architect is running with --yes flag he is proceeding with code changes. We clean it after the run.
-	_, err = exec.Exec("git", time.Minute, "reset", "--hard")
+	_, err = exec.Run(time.Minute, "git", "reset", "--hard")
 	if err != nil {
 		return architectResult, err
 	}
-	_, err = exec.Exec("git", time.Minute, "clean", "-fd", ".aider.tags.cache.v3")
+	_, err = exec.Run(time.Minute, "git", "clean", "-fd", ".aider.tags.cache.v3")
 	if err != nil {
 		return architectResult, err
 	}
@@ -734,7 +869,7 @@ func (i *Routine) simpleAI(promptFile string) (exec.Output, error) {
 	return ret, err
 }
 
-func (i *Routine) mergeIntoParent(deleteBranchAfterMerge bool) (exec.Output, error) {
+func (i *Routine) mergeIntoParent(strategy string, deleteBranchAfterMerge bool) (exec.Output, error) {
 	currentBranchName := i.workbench.GetIssueBranchName(i.issue)
 	parentBranches := i.getTargetBranch()
 	parentBranchName := parentBranches[len(parentBranches)-1] // last is our first parent
@@ -755,7 +890,14 @@ func (i *Routine) mergeIntoParent(deleteBranchAfterMerge bool) (exec.Output, err
 		return exec.Output{Stdout: "Skipping merge"}, nil
 	}
 
-	log.Printf("Merging current branch: %q into parent branch: %q", currentBranchName, parentBranchName)
+	log.Printf("Merging (%s) current branch: %q into parent branch: %q", strategy, currentBranchName, parentBranchName)
+
+	if strategy == settings.MergeStrategyRebase {
+		out, err := i.rebaseOnto(currentBranchName, parentBranchName)
+		if err != nil {
+			return out, err
+		}
+	}
 
 	_, err = i.workbench.CheckoutBranch(parentBranchName)
 	if err != nil {
@@ -763,15 +905,24 @@ func (i *Routine) mergeIntoParent(deleteBranchAfterMerge bool) (exec.Output, err
 		return exec.Output{}, err
 	}
 	log.Printf("Checked out parent branch: %q", parentBranchName)
+	parentHeadSha, err := i.workbench.GetLastCommit()
+	if err != nil {
+		return exec.Output{}, fmt.Errorf("failed to get parent branch head: %v", err)
+	}
 	log.Printf("Merging...")
 
-	out, err := exec.Exec("git", time.Minute, "merge", currentBranchName, "--no-edit")
+	out, err := i.mergeBranch(strategy, currentBranchName, parentBranchName)
 	if err != nil {
 		log.Printf("Failed to merge current branch: %q into parent branch: %q err: %v, stderr: %s", currentBranchName, parentBranchName, err, out.Stderr)
 		return out, err
 	}
 	log.Printf("Merged current branch: %q into parent branch: %q", currentBranchName, parentBranchName)
 
+	err = i.saveMergedSHAs(strategy, parentHeadSha)
+	if err != nil {
+		return out, err
+	}
+
 	err = i.commentParentBranchDiff()
 	if err != nil {
 		return out, err
@@ -787,6 +938,10 @@ func (i *Routine) mergeIntoParent(deleteBranchAfterMerge bool) (exec.Output, err
 		return out, err
 	}
 
+	if parentBranchName == i.projectCfg.FinalBranch {
+		i.autoPush(settings.PushOnMergeIntoFinal, parentBranchName)
+	}
+
 	// delete the branch after merge
 	if deleteBranchAfterMerge {
 		err = i.workbench.Git.DeleteBranch(currentBranchName)
@@ -2,7 +2,6 @@ package exec
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/andrejsstepanovs/andai/internal/settings"
 )
@@ -50,6 +49,11 @@ var (
 		"--no-auto-commits",
 	}
 
+	// resolve edits conflicted files during merge. Merge is committed by andai, not aider.
+	aiderResolveArgs = []string{
+		"--no-auto-commits",
+	}
+
 	aiderCommitArgs = []string{
 		"--commit",
 	}
@@ -63,12 +67,14 @@ var (
 	aiderArchitectCodeParams = map[string]string{
 		"--chat-mode": "architect",
 	}
-	aiderCommitParams = map[string]string{
+	aiderResolveParams = map[string]string{}
+	aiderCommitParams  = map[string]string{
 		"--chat-mode": "code", // not sure if this is correct
 	}
 )
 
-func AiderCommand(contextFile string, step settings.Step, config settings.Aider) string {
+// AiderCommand returns aider arguments. Meant to be executed directly (without shell), so values are not quoted.
+func AiderCommand(contextFile string, step settings.Step, config settings.Aider) []string {
 	var (
 		params map[string]string
 		args   []string
@@ -86,6 +92,9 @@ func AiderCommand(contextFile string, step settings.Step, config settings.Aider)
 	case "architect-code":
 		params = aiderArchitectCodeParams
 		args = aiderArchitectCodeArgs
+	case "resolve":
+		params = aiderResolveParams
+		args = aiderResolveArgs
 	default:
 		panic("unknown step action")
 	}
@@ -99,7 +108,7 @@ func AiderCommand(contextFile string, step settings.Step, config settings.Aider)
 	if contextFile != "" {
 		params["--message-file"] = contextFile
 	} else {
-		params["--message"] = step.Prompt.ForCli()
+		params["--message"] = string(step.Prompt)
 	}
 	if config.APIKey != "" {
 		params["--openai-api-key"] = config.APIKey.String()
@@ -111,26 +120,25 @@ func AiderCommand(contextFile string, step settings.Step, config settings.Aider)
 
 	paramsCli := make([]string, 0, len(params))
 	for k, v := range aiderDefaultParams {
-		paramsCli = append(paramsCli, fmt.Sprintf("%s=%q", k, v))
+		paramsCli = append(paramsCli, fmt.Sprintf("%s=%s", k, v))
 	}
 	for k, v := range params {
-		paramsCli = append(paramsCli, fmt.Sprintf("%s=%q", k, v))
+		paramsCli = append(paramsCli, fmt.Sprintf("%s=%s", k, v))
 	}
 
 	if len(step.ContextFiles) > 0 {
 		for _, file := range step.ContextFiles {
 			//log.Printf("Aider adding file: %q\n", file)
-			paramsCli = append(paramsCli, fmt.Sprintf("--file=%q", file))
+			paramsCli = append(paramsCli, fmt.Sprintf("--file=%s", file))
 		}
 	}
 
-	args = append(args, aiderArgs...)
+	cli := make([]string, 0, len(args)+len(aiderArgs)+len(paramsCli))
+	cli = append(cli, args...)
+	cli = append(cli, aiderArgs...)
+	cli = append(cli, paramsCli...)
 
-	return fmt.Sprintf(
-		"%s %s",
-		strings.Join(args, " "),
-		strings.Join(paramsCli, " "),
-	)
+	return cli
 }
 
 //#### I’ll need to see the existing tests for the other merger components and the API to know exactly what to change. Could you please add the contents of the following test files:
@@ -0,0 +1,38 @@
+package exec_test
+
+import (
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/exec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SplitArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected []string
+	}{
+		{name: "plain", line: "  log --oneline  -n 5 ", expected: []string{"log", "--oneline", "-n", "5"}},
+		{name: "double quotes", line: `commit -m "two words"`, expected: []string{"commit", "-m", "two words"}},
+		{name: "single quotes", line: `commit -m 'it is "$HOME"'`, expected: []string{"commit", "-m", `it is "$HOME"`}},
+		{name: "escapes", line: `commit -m "say \"hi\" \n" two\ words`, expected: []string{"commit", "-m", `say "hi" \n`, "two words"}},
+		{name: "empty argument", line: `commit -m ""`, expected: []string{"commit", "-m", ""}},
+		{name: "joined quotes", line: `--author="A B"<a@b>`, expected: []string{"--author=A B<a@b>"}},
+		{name: "empty", line: "", expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := exec.SplitArgs(tt.line)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, args)
+		})
+	}
+
+	t.Run("unclosed quote", func(t *testing.T) {
+		_, err := exec.SplitArgs(`commit -m "oops`)
+		assert.Error(t, err)
+	})
+}
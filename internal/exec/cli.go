@@ -9,18 +9,37 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/andrejsstepanovs/andai/internal/settings"
 )
 
-const (
-	shell     = "zsh"
-	shellPath = "/usr/bin/zsh"
-	shellArg  = "-ic"
+// waitDelay is how long output of finished or killed command is still read before pipes are closed.
+const waitDelay = 5 * time.Second
+
+var (
+	shellMu sync.RWMutex
+	shell   = settings.Shell{Name: settings.DefaultShell}
 )
 
-// Exec executes command with timeout.
+// SetShell configures shell that is used by Exec and WithContext.
+func SetShell(s settings.Shell) {
+	shellMu.Lock()
+	defer shellMu.Unlock()
+	shell = s
+}
+
+func getShell() settings.Shell {
+	shellMu.RLock()
+	defer shellMu.RUnlock()
+	return shell
+}
+
+// Exec executes command in configured shell with timeout.
+// Arguments are joined with spaces, so quoting is up to the caller. Prefer Run if shell features are not needed.
 func Exec(command string, timeout time.Duration, args ...string) (Output, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
@@ -28,7 +47,7 @@ func Exec(command string, timeout time.Duration, args ...string) (Output, error)
 	return WithContext(ctx, command, args...)
 }
 
-// WithContext executes command with context for cancellation and timeout.
+// WithContext executes command in configured shell with context for cancellation and timeout.
 func WithContext(ctx context.Context, command string, args ...string) (Output, error) {
 	// The caller is now responsible for context timeout and cancellation.
 	// No need to create a context with timeout here.
@@ -43,51 +62,89 @@ func WithContext(ctx context.Context, command string, args ...string) (Output, e
 		cmdExec = fmt.Sprintf("%s %s", command, strings.Join(args, " "))
 	}
 
-	output := Output{Command: cmdExec}
+	sh := getShell()
+	log.Printf("EXEC (%s): %s", sh.GetName(), cmdExec)
 
-	fullCommand := fmt.Sprintf("%s %s", command, strings.Join(args, " "))
-	log.Printf("EXEC: %s", fullCommand)
+	shellArgs := append(sh.Args(), cmdExec)
+	cmd := exec.CommandContext(ctx, sh.GetName(), shellArgs...) // nolint:gosec
+	cmd.Env = os.Environ()
+	if shellPath, err := exec.LookPath(sh.GetName()); err == nil {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("SHELL=%s", shellPath))
+	}
 
-	cmd := exec.CommandContext(ctx, shell, shellArg, fullCommand) // nolint:gosec
-	cmd.Env = append(os.Environ(), fmt.Sprintf("SHELL=%s", shellPath))
+	return run(ctx, cmd, Output{Command: cmdExec})
+}
 
+// Run executes program directly (no shell) with timeout. Arguments are passed as is, no quoting needed.
+func Run(timeout time.Duration, name string, args ...string) (Output, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return RunWithContext(ctx, name, args...)
+}
+
+// RunWithContext executes program directly (no shell) with context for cancellation and timeout.
+func RunWithContext(ctx context.Context, name string, args ...string) (Output, error) {
+	cmdExec := CommandString(name, args...)
+	log.Printf("EXEC: %s", cmdExec)
+
+	cmd := exec.CommandContext(ctx, name, args...) // nolint:gosec
+	return run(ctx, cmd, Output{Command: cmdExec})
+}
+
+// CommandString renders program and arguments as a readable command line. Arguments with spaces or quotes are quoted.
+func CommandString(name string, args ...string) string {
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, name)
+	for _, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'\\$`|&;<>()*?[]{}#~") {
+			arg = strconv.Quote(arg)
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
+
+func run(ctx context.Context, cmd *exec.Cmd, output Output) (Output, error) {
+	return runLimited(ctx, cmd, output, 0)
+}
+
+// nolint: cyclop
+// Output cap applies only to captured stdout and stderr, stream (run log) gets everything.
+func runLimited(ctx context.Context, cmd *exec.Cmd, output Output, maxOutputBytes int) (Output, error) {
 	var stdout, stderr bytes.Buffer
-	stdoutPipe, err := cmd.StdoutPipe()
-	if err != nil {
-		return output, fmt.Errorf("failed to get stdout pipe: %w", err)
+	var stdoutWriter, stderrWriter io.Writer = &stdout, &stderr
+	var stdoutCap, stderrCap *capWriter
+	if maxOutputBytes > 0 {
+		stdoutCap = newCapWriter(&stdout, maxOutputBytes)
+		stderrCap = newCapWriter(&stderr, maxOutputBytes)
+		stdoutWriter, stderrWriter = stdoutCap, stderrCap
 	}
-	stderrPipe, err := cmd.StderrPipe()
-	if err != nil {
-		return output, fmt.Errorf("failed to get stderr pipe: %w", err)
+	if fn := getStream(); fn != nil {
+		stdoutLines := newLineWriter(StreamStdout, fn)
+		stderrLines := newLineWriter(StreamStderr, fn)
+		defer stdoutLines.Flush()
+		defer stderrLines.Flush()
+		stdoutWriter = io.MultiWriter(stdoutWriter, stdoutLines)
+		stderrWriter = io.MultiWriter(stderrWriter, stderrLines)
 	}
 
-	var wg sync.WaitGroup
+	// exec copies output in its own goroutines. On timeout whole process group is killed
+	// and WaitDelay makes sure that leftover (detached) processes holding output pipes do not block Wait.
+	cmd.Stdout = stdoutWriter
+	cmd.Stderr = stderrWriter
+	cmd.WaitDelay = waitDelay
+	killProcessGroup(cmd)
 
-	// Start the command
 	if err := cmd.Start(); err != nil {
 		return output, fmt.Errorf("failed to start command: %w", err)
 	}
 
-	// Read stdout asynchronously
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		_, _ = io.Copy(&stdout, stdoutPipe)
-	}()
-
-	// Read stderr asynchronously
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		_, _ = io.Copy(&stderr, stderrPipe)
-	}()
-
-	// Wait for I/O goroutines to finish. This ensures we capture all output
-	// *before* waiting for the command to exit.
-	wg.Wait()
-
-	// Wait for the command to complete and release resources
-	err = cmd.Wait()
+	err := cmd.Wait()
+	if errors.Is(err, exec.ErrWaitDelay) && ctx.Err() == nil {
+		log.Printf("Command finished, but its background processes kept output open, ignoring them")
+		err = nil
+	}
 
 	// Check if the context timed out or was canceled.
 	// This is important because cmd.Wait() might return a generic error (like "signal: killed")
@@ -108,6 +165,13 @@ func WithContext(ctx context.Context, command string, args ...string) (Output, e
 		retStdErr = ""
 	}
 
+	if stdoutCap != nil && stdoutCap.capped {
+		retStdOut = strings.TrimRight(retStdOut, "\n") + "\n" + cappedNote(maxOutputBytes)
+	}
+	if stderrCap != nil && stderrCap.capped {
+		retStdErr = strings.TrimRight(retStdErr, "\n") + "\n" + cappedNote(maxOutputBytes)
+	}
+
 	output.Stdout = strings.TrimSpace(retStdOut)
 	output.Stderr = strings.TrimSpace(retStdErr)
 
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/andrejsstepanovs/andai/internal/exec"
+	"github.com/andrejsstepanovs/andai/internal/settings"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -105,3 +106,51 @@ func Test_ExecWithContext(t *testing.T) {
 		assert.Empty(t, output.Stderr)
 	})
 }
+
+func Test_RunWithContext(t *testing.T) {
+	t.Run("arguments with spaces and quotes are passed as is", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		output, err := exec.RunWithContext(ctx, "printf", "%s|%s", "hello world", `say "hi"`)
+		require.NoError(t, err)
+		assert.Equal(t, `hello world|say "hi"`, output.Stdout)
+		assert.Equal(t, `printf "%s|%s" "hello world" "say \"hi\""`, output.Command)
+	})
+
+	t.Run("no shell expansion", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		output, err := exec.RunWithContext(ctx, "echo", "$HOME", "*")
+		require.NoError(t, err)
+		assert.Equal(t, "$HOME *", output.Stdout)
+	})
+
+	t.Run("missing program", func(t *testing.T) {
+		output, err := exec.Run(time.Second, "andai-program-that-does-not-exist")
+		require.Error(t, err)
+		assert.Equal(t, "andai-program-that-does-not-exist", output.Command)
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		_, err := exec.Run(50*time.Millisecond, "sleep", "1")
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func Test_CommandString(t *testing.T) {
+	assert.Equal(t, "git status", exec.CommandString("git", "status"))
+	assert.Equal(t, `git commit -m "code reformat"`, exec.CommandString("git", "commit", "-m", "code reformat"))
+	assert.Equal(t, `echo ""`, exec.CommandString("echo", ""))
+	assert.Equal(t, "pwd", exec.CommandString("pwd"))
+}
+
+func Test_SetShell(t *testing.T) {
+	defer exec.SetShell(settings.Shell{})
+
+	exec.SetShell(settings.Shell{Name: "bash"})
+	output, err := exec.Exec("echo $BASH_VERSION | cut -c1", time.Second*10)
+	require.NoError(t, err)
+	assert.NotEmpty(t, output.Stdout)
+	assert.Equal(t, "echo $BASH_VERSION | cut -c1", output.Command)
+}
@@ -0,0 +1,113 @@
+package exec
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrMergeConflict is returned (wrapped in ConflictError) when git could not merge branches.
+var ErrMergeConflict = errors.New("merge conflict")
+
+// ConflictError tells which files git left with conflicts.
+type ConflictError struct {
+	Operation string
+	Files     []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s: %d conflicted files: %s", e.Operation, len(e.Files), strings.Join(e.Files, ", "))
+}
+
+func (e *ConflictError) Unwrap() error {
+	return ErrMergeConflict
+}
+
+// ConflictedFiles returns files with unresolved conflicts in current repository.
+func ConflictedFiles() ([]string, error) {
+	out, err := Run(time.Second*10, "git", "diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conflicted files: %w", err)
+	}
+	if out.Stdout == "" {
+		return nil, nil
+	}
+	return strings.Split(out.Stdout, "\n"), nil
+}
+
+// ConflictHunks returns diff of conflicted files, showing both sides of each conflict.
+func ConflictHunks(files []string) (string, error) {
+	args := append([]string{"diff", "--"}, files...)
+	out, err := Run(time.Minute, "git", args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to get conflict hunks: %w", err)
+	}
+	return out.Stdout, nil
+}
+
+// IsMerging tells if merge is in progress (MERGE_HEAD exists).
+func IsMerging() bool {
+	_, err := Run(time.Second*10, "git", "rev-parse", "-q", "--verify", "MERGE_HEAD")
+	return err == nil
+}
+
+// AbortMerge leaves merge, squash merge or rebase that stopped on conflicts, restoring branch as it was.
+func AbortMerge() error {
+	attempts := [][]string{
+		{"merge", "--abort"},
+		{"rebase", "--abort"},
+		{"reset", "--merge"},
+	}
+	var lastErr error
+	for _, args := range attempts {
+		out, err := Run(time.Minute, "git", args...)
+		if err == nil {
+			log.Printf("Aborted with git %s", strings.Join(args, " "))
+			return nil
+		}
+		lastErr = fmt.Errorf("git %s: %w, stderr: %s", strings.Join(args, " "), err, out.Stderr)
+	}
+	return fmt.Errorf("failed to abort merge: %w", lastErr)
+}
+
+// HasConflictMarkers tells if content still contains git conflict markers.
+// "=======" alone is a markdown heading underline, it counts only after opening marker.
+func HasConflictMarkers(content string) bool {
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	opened := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "<<<<<<<" || strings.HasPrefix(line, "<<<<<<< "):
+			opened = true
+		case line == ">>>>>>>" || strings.HasPrefix(line, ">>>>>>> "):
+			return true
+		case line == "=======" && opened:
+			return true
+		}
+	}
+	return opened
+}
+
+// FilesWithConflictMarkers returns files that still contain conflict markers.
+func FilesWithConflictMarkers(files []string) ([]string, error) {
+	marked := make([]string, 0)
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue // resolved by deleting the file
+			}
+			return nil, fmt.Errorf("failed to read %q: %w", file, err)
+		}
+		if HasConflictMarkers(string(content)) {
+			marked = append(marked, file)
+		}
+	}
+	return marked, nil
+}
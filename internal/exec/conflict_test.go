@@ -0,0 +1,137 @@
+package exec_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andrejsstepanovs/andai/internal/exec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func git(t *testing.T, args ...string) exec.Output {
+	t.Helper()
+	out, err := exec.Run(10*time.Second, "git", args...)
+	require.NoError(t, err, out.Stderr)
+	return out
+}
+
+// initRepo creates empty repository with "main" branch in dir and changes working directory into it until test ends.
+func initRepo(t *testing.T, dir string) {
+	t.Helper()
+	originalWd, err := os.Getwd()
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, os.Chdir(originalWd)) })
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.Chdir(dir))
+
+	git(t, "init", "-q", "-b", "main")
+	git(t, "config", "user.email", "test@example.com")
+	git(t, "config", "user.name", "Test")
+}
+
+// conflictRepo creates repository where merging "feature" into "main" conflicts in file.txt.
+func conflictRepo(t *testing.T) {
+	t.Helper()
+	initRepo(t, t.TempDir())
+	require.NoError(t, os.WriteFile("file.txt", []byte("hello\n"), 0o600))
+	require.NoError(t, os.WriteFile("other.txt", []byte("same\n"), 0o600))
+	git(t, "add", ".")
+	git(t, "commit", "-q", "-m", "initial")
+
+	git(t, "checkout", "-q", "-b", "feature")
+	require.NoError(t, os.WriteFile("file.txt", []byte("hello feature\n"), 0o600))
+	git(t, "commit", "-q", "-am", "feature change")
+
+	git(t, "checkout", "-q", "main")
+	require.NoError(t, os.WriteFile("file.txt", []byte("hello main\n"), 0o600))
+	git(t, "commit", "-q", "-am", "main change")
+}
+
+func TestConflicts(t *testing.T) {
+	conflictRepo(t)
+
+	files, err := exec.ConflictedFiles()
+	require.NoError(t, err)
+	assert.Empty(t, files)
+	assert.False(t, exec.IsMerging())
+
+	_, err = exec.Run(10*time.Second, "git", "merge", "feature", "--no-edit")
+	require.Error(t, err)
+
+	files, err = exec.ConflictedFiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"file.txt"}, files)
+	assert.True(t, exec.IsMerging())
+
+	hunks, err := exec.ConflictHunks(files)
+	require.NoError(t, err)
+	assert.Contains(t, hunks, "<<<<<<< HEAD")
+	assert.Contains(t, hunks, "hello main")
+	assert.Contains(t, hunks, "hello feature")
+
+	marked, err := exec.FilesWithConflictMarkers([]string{"file.txt", "other.txt", "deleted.txt"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"file.txt"}, marked)
+
+	require.NoError(t, exec.AbortMerge())
+	assert.False(t, exec.IsMerging())
+	content, err := os.ReadFile(filepath.Join(".", "file.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello main\n", string(content))
+}
+
+func TestAbortMerge_Squash(t *testing.T) {
+	conflictRepo(t)
+
+	_, err := exec.Run(10*time.Second, "git", "merge", "--squash", "feature")
+	require.Error(t, err)
+	assert.False(t, exec.IsMerging())
+
+	require.NoError(t, exec.AbortMerge())
+	files, err := exec.ConflictedFiles()
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestExecCheckoutBranch_Conflict(t *testing.T) {
+	conflictRepo(t)
+
+	_, err := exec.Run(10*time.Second, "git", "merge", "feature", "--no-edit")
+	require.Error(t, err)
+
+	_, err = exec.NewGit(".").ExecCheckoutBranch("feature")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, exec.ErrMergeConflict))
+
+	var conflictErr *exec.ConflictError
+	require.True(t, errors.As(err, &conflictErr))
+	assert.Equal(t, []string{"file.txt"}, conflictErr.Files)
+	assert.Equal(t, "checkout feature: 1 conflicted files: file.txt", conflictErr.Error())
+}
+
+func TestHasConflictMarkers(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected bool
+	}{
+		{name: "clean", content: "a\nb\n", expected: false},
+		{name: "markers", content: "<<<<<<< HEAD\na\n=======\nb\n>>>>>>> feature\n", expected: true},
+		{name: "separator only is markdown heading", content: "Title\n=======\ntext", expected: false},
+		{name: "separator after opening marker", content: "<<<<<<< HEAD\na\n=======\nb", expected: true},
+		{name: "opening marker only", content: "<<<<<<< HEAD\na\n", expected: true},
+		{name: "closing marker only", content: "a\n>>>>>>> feature\n", expected: true},
+		{name: "markdown underline is not marker", content: "Title\n========\n", expected: false},
+		{name: "inline arrows", content: "if a <<<<<<< b {}", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, exec.HasConflictMarkers(tt.content))
+		})
+	}
+}
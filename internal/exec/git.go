@@ -214,7 +214,7 @@ func (g *Git) BranchExists(branchName string) (bool, error) {
 // ExecCheckoutBranch checks out a branch or creates it if it does not exist.
 // Returns true if new branch was created, false if it already existed.
 func (g *Git) ExecCheckoutBranch(branchName string) (bool, error) {
-	respGit, err := Exec("git", time.Second*10, "branch")
+	respGit, err := Run(time.Second*10, "git", "branch")
 	if err != nil {
 		log.Printf("stderr: %s", respGit.Stderr)
 		return false, fmt.Errorf("failed to check if branch exists err: %v", err)
@@ -237,16 +237,19 @@ func (g *Git) ExecCheckoutBranch(branchName string) (bool, error) {
 
 	if branchExists {
 		log.Printf("Branch %s already exists\n", branchName)
-		checkoutResp, checkoutErr = Exec("git", time.Second*10, "checkout", branchName)
+		checkoutResp, checkoutErr = Run(time.Second*10, "git", "checkout", branchName)
 	} else {
 		log.Printf("Branch %s does not exist\n", branchName)
-		checkoutResp, checkoutErr = Exec("git", time.Second*10, "checkout", "-b", branchName)
+		checkoutResp, checkoutErr = Run(time.Second*10, "git", "checkout", "-b", branchName)
 	}
 
 	branchCreated := !branchExists
 
 	if checkoutErr != nil {
-		exec, errDiff := Exec("git", time.Second*10, "diff", "--name-only")
+		if conflicted, errConflict := ConflictedFiles(); errConflict == nil && len(conflicted) > 0 {
+			return branchCreated, &ConflictError{Operation: fmt.Sprintf("checkout %s", branchName), Files: conflicted}
+		}
+		exec, errDiff := Run(time.Second*10, "git", "diff", "--name-only")
 		if errDiff == nil && exec.Stdout != "" {
 			files := strings.Split(exec.Stdout, "\n")
 			if len(files) > 0 {
@@ -366,7 +369,7 @@ func FindProjectGit(projectConfig settings.Project, projectRepo redminemodels.Re
 }
 
 func IsGitInstalled() bool {
-	out, err := Exec("git", time.Second*10, "--version")
+	out, err := Run(time.Second*10, "git", "--version")
 	if err != nil {
 		log.Printf("Git is not installed: %v", err)
 		return false
@@ -376,7 +379,7 @@ func IsGitInstalled() bool {
 }
 
 func IsTreeInstalled() bool {
-	out, err := Exec("tree", time.Second*10, "--version")
+	out, err := Run(time.Second*10, "tree", "--version")
 	if err != nil {
 		log.Printf("tree is not installed: %v", err)
 		return false
@@ -386,7 +389,7 @@ func IsTreeInstalled() bool {
 }
 
 func IsAiderInstalled() bool {
-	out, err := Exec("aider", time.Second*10, "--version")
+	out, err := Run(time.Second*10, "aider", "--version")
 	if err != nil {
 		log.Printf("Aider is not installed: %v", err)
 		return false
@@ -0,0 +1,215 @@
+package exec
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commit resolves ref (sha, branch, HEAD, HEAD~1, ...) to commit object.
+func (g *Git) commit(ref string) (*object.Commit, error) {
+	hash, err := g.repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %q: %v", ref, err)
+	}
+	commit, err := g.repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %q: %v", ref, err)
+	}
+	return commit, nil
+}
+
+// MergeBase returns best common ancestor of two refs.
+func (g *Git) MergeBase(a, b string) (string, error) {
+	commitA, err := g.commit(a)
+	if err != nil {
+		return "", err
+	}
+	commitB, err := g.commit(b)
+	if err != nil {
+		return "", err
+	}
+	bases, err := commitA.MergeBase(commitB)
+	if err != nil {
+		return "", fmt.Errorf("failed to find merge base of %q and %q: %v", a, b, err)
+	}
+	if len(bases) == 0 {
+		return "", fmt.Errorf("%q and %q have no common history", a, b)
+	}
+	return bases[0].Hash.String(), nil
+}
+
+// CommitRange returns commits reachable from "to" but not from "from" (same as `git log from..to`).
+// Oldest commit is first. Empty "from" returns whole history of "to".
+func (g *Git) CommitRange(from, to string) ([]string, error) {
+	toCommit, err := g.commit(to)
+	if err != nil {
+		return nil, err
+	}
+
+	exclude := make(map[plumbing.Hash]bool)
+	if from != "" {
+		fromCommit, err := g.commit(from)
+		if err != nil {
+			return nil, err
+		}
+		err = object.NewCommitPreorderIter(fromCommit, nil, nil).ForEach(func(c *object.Commit) error {
+			exclude[c.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk history of %q: %v", from, err)
+		}
+	}
+
+	hashes := make([]string, 0)
+	iter := object.NewCommitIterCTime(toCommit, exclude, nil)
+	defer iter.Close()
+	for {
+		c, err := iter.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk history of %q: %v", to, err)
+		}
+		hashes = append(hashes, c.Hash.String())
+	}
+
+	slices.Reverse(hashes)
+	return hashes, nil
+}
+
+// Diff returns unified diff between trees of two refs (same as `git diff from to`). Empty "from" diffs against empty tree.
+func (g *Git) Diff(from, to string) (string, error) {
+	toCommit, err := g.commit(to)
+	if err != nil {
+		return "", err
+	}
+	var fromCommit *object.Commit
+	if from != "" {
+		if fromCommit, err = g.commit(from); err != nil {
+			return "", err
+		}
+	}
+	return diffCommits(fromCommit, toCommit)
+}
+
+// CommitDiff returns unified diff of single commit against its first parent.
+func (g *Git) CommitDiff(ref string) (string, error) {
+	commit, err := g.commit(ref)
+	if err != nil {
+		return "", err
+	}
+	var parent *object.Commit
+	if commit.NumParents() > 0 {
+		if parent, err = commit.Parent(0); err != nil {
+			return "", fmt.Errorf("failed to get parent of %q: %v", ref, err)
+		}
+	}
+	return diffCommits(parent, commit)
+}
+
+// diffCommits diffs commit trees. Nil "from" means empty tree.
+func diffCommits(from, to *object.Commit) (string, error) {
+	var fromTree *object.Tree
+	if from != nil {
+		tree, err := from.Tree()
+		if err != nil {
+			return "", fmt.Errorf("failed to get tree of %s: %v", from.Hash, err)
+		}
+		fromTree = tree
+	}
+	toTree, err := to.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get tree of %s: %v", to.Hash, err)
+	}
+
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff trees: %v", err)
+	}
+	patch, err := changes.Patch()
+	if err != nil {
+		return "", fmt.Errorf("failed to build patch: %v", err)
+	}
+	return patch.String(), nil
+}
+
+// TrackedFiles returns paths of all files in ref tree.
+func (g *Git) TrackedFiles(ref string) ([]string, error) {
+	commit, err := g.commit(ref)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree of %q: %v", ref, err)
+	}
+
+	files := make([]string, 0)
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk tree of %q: %v", ref, err)
+		}
+		if entry.Mode.IsFile() {
+			files = append(files, name)
+		}
+	}
+	return files, nil
+}
+
+// ChangedFiles returns files changed in "to" since it forked from "from" (same as `git diff --name-only from...to`).
+func (g *Git) ChangedFiles(from, to string) ([]string, error) {
+	base, err := g.MergeBase(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return g.DiffFiles(base, to)
+}
+
+// DiffFiles returns files that differ between "from" and "to" trees (same as `git diff --name-only from to`).
+func (g *Git) DiffFiles(from, to string) ([]string, error) {
+	fromCommit, err := g.commit(from)
+	if err != nil {
+		return nil, err
+	}
+	toCommit, err := g.commit(to)
+	if err != nil {
+		return nil, err
+	}
+
+	fromTree, err := fromCommit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree of %q: %v", from, err)
+	}
+	toTree, err := toCommit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree of %q: %v", to, err)
+	}
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff %q and %q: %v", from, to, err)
+	}
+
+	files := make([]string, 0, len(changes))
+	for _, change := range changes {
+		for _, name := range []string{change.From.Name, change.To.Name} {
+			if name != "" && !slices.Contains(files, name) {
+				files = append(files, name)
+			}
+		}
+	}
+	slices.Sort(files)
+	return files, nil
+}
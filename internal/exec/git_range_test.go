@@ -0,0 +1,148 @@
+package exec_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/andrejsstepanovs/andai/internal/exec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// commitFile writes file and commits it with committer time (seconds) so history order is stable.
+func commitFile(t *testing.T, at int, file, content string) string {
+	t.Helper()
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	git(t, "add", file)
+	date := fmt.Sprintf("GIT_COMMITTER_DATE=%d +0000", 1700000000+at)
+	out, err := exec.RunWithOptions(exec.Options{Env: []string{date}, Timeout: 10 * time.Second}, "git", "commit", "-q", "-m", file+" "+content)
+	require.NoError(t, err, out.Stderr)
+	return git(t, "rev-parse", "HEAD").Stdout
+}
+
+// rangeRepo creates "main" that moved on after "AI-1" branched off, and "AI-2" merged into "AI-1".
+func rangeRepo(t *testing.T) (*exec.Git, map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	initRepo(t, dir)
+
+	sha := make(map[string]string)
+	sha["base"] = commitFile(t, 1, "base.txt", "base")
+
+	git(t, "checkout", "-q", "-b", "AI-1")
+	sha["a1"] = commitFile(t, 2, "a.txt", "one")
+	git(t, "checkout", "-q", "-b", "AI-2")
+	sha["c1"] = commitFile(t, 3, "child.txt", "child")
+	git(t, "checkout", "-q", "AI-1")
+	sha["a2"] = commitFile(t, 4, "a.txt", "two")
+	git(t, "merge", "-q", "--no-edit", "--no-ff", "AI-2")
+	sha["merge"] = git(t, "rev-parse", "HEAD").Stdout
+
+	git(t, "checkout", "-q", "main")
+	sha["m1"] = commitFile(t, 5, "main.txt", "main")
+	git(t, "checkout", "-q", "AI-1")
+
+	g := exec.NewGit(dir)
+	require.NoError(t, g.Open())
+	return g, sha
+}
+
+func TestGit_MergeBase(t *testing.T) {
+	g, sha := rangeRepo(t)
+
+	base, err := g.MergeBase("main", "AI-1")
+	require.NoError(t, err)
+	assert.Equal(t, sha["base"], base)
+
+	base, err = g.MergeBase("AI-1", "AI-2")
+	require.NoError(t, err)
+	assert.Equal(t, sha["c1"], base)
+
+	_, err = g.MergeBase("main", "missing")
+	assert.Error(t, err)
+}
+
+func TestGit_CommitRange(t *testing.T) {
+	g, sha := rangeRepo(t)
+
+	commits, err := g.CommitRange(sha["base"], "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, []string{sha["a1"], sha["c1"], sha["a2"], sha["merge"]}, commits, "oldest first, merged child included")
+
+	commits, err = g.CommitRange("main", "AI-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{sha["a1"], sha["c1"], sha["a2"], sha["merge"]}, commits, "commits made on main are not included")
+
+	commits, err = g.CommitRange(sha["a2"], sha["merge"])
+	require.NoError(t, err)
+	assert.Equal(t, []string{sha["c1"], sha["merge"]}, commits)
+
+	commits, err = g.CommitRange("AI-1", "AI-1")
+	require.NoError(t, err)
+	assert.Empty(t, commits)
+
+	commits, err = g.CommitRange("", "main")
+	require.NoError(t, err)
+	assert.Equal(t, []string{sha["base"], sha["m1"]}, commits)
+
+	_, err = g.CommitRange("missing", "HEAD")
+	assert.Error(t, err)
+}
+
+func TestGit_ChangedFiles(t *testing.T) {
+	g, sha := rangeRepo(t)
+
+	files, err := g.ChangedFiles("main", "AI-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.txt", "child.txt"}, files, "main.txt changed only on main")
+
+	files, err = g.ChangedFiles(sha["a2"], "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"child.txt"}, files)
+
+	files, err = g.ChangedFiles("HEAD", "HEAD")
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	files, err = g.DiffFiles("main", "AI-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.txt", "child.txt", "main.txt"}, files, "two dot diff includes main changes")
+}
+
+func TestGit_Diff(t *testing.T) {
+	g, sha := rangeRepo(t)
+
+	diff, err := g.Diff(sha["a2"], sha["merge"])
+	require.NoError(t, err)
+	assert.Contains(t, diff, "diff --git a/child.txt b/child.txt")
+	assert.Contains(t, diff, "+child")
+	assert.NotContains(t, diff, "a.txt")
+
+	diff, err = g.Diff("", "main")
+	require.NoError(t, err)
+	assert.Contains(t, diff, "+base")
+	assert.Contains(t, diff, "+main")
+
+	diff, err = g.CommitDiff(sha["a2"])
+	require.NoError(t, err)
+	assert.Contains(t, diff, "-one")
+	assert.Contains(t, diff, "+two")
+
+	diff, err = g.CommitDiff(sha["base"])
+	require.NoError(t, err)
+	assert.Contains(t, diff, "+base", "root commit is diffed against empty tree")
+}
+
+func TestGit_TrackedFiles(t *testing.T) {
+	g, _ := rangeRepo(t)
+
+	files, err := g.TrackedFiles("HEAD")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"base.txt", "a.txt", "child.txt"}, files)
+
+	files, err = g.TrackedFiles("main")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"base.txt", "main.txt"}, files)
+}
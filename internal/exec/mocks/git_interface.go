@@ -9,6 +9,34 @@ type GitInterface struct {
 	mock.Mock
 }
 
+// BranchExists provides a mock function with given fields: branchName
+func (_m *GitInterface) BranchExists(branchName string) (bool, error) {
+	ret := _m.Called(branchName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BranchExists")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (bool, error)); ok {
+		return rf(branchName)
+	}
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(branchName)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(branchName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // BranchName provides a mock function with given fields: issueID
 func (_m *GitInterface) BranchName(issueID int) string {
 	ret := _m.Called(issueID)
@@ -27,6 +55,36 @@ func (_m *GitInterface) BranchName(issueID int) string {
 	return r0
 }
 
+// ChangedFiles provides a mock function with given fields: from, to
+func (_m *GitInterface) ChangedFiles(from string, to string) ([]string, error) {
+	ret := _m.Called(from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChangedFiles")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) ([]string, error)); ok {
+		return rf(from, to)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) []string); ok {
+		r0 = rf(from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CheckoutBranch provides a mock function with given fields: name
 func (_m *GitInterface) CheckoutBranch(name string) error {
 	ret := _m.Called(name)
@@ -45,6 +103,64 @@ func (_m *GitInterface) CheckoutBranch(name string) error {
 	return r0
 }
 
+// CommitDiff provides a mock function with given fields: ref
+func (_m *GitInterface) CommitDiff(ref string) (string, error) {
+	ret := _m.Called(ref)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CommitDiff")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (string, error)); ok {
+		return rf(ref)
+	}
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(ref)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(ref)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CommitRange provides a mock function with given fields: from, to
+func (_m *GitInterface) CommitRange(from string, to string) ([]string, error) {
+	ret := _m.Called(from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CommitRange")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) ([]string, error)); ok {
+		return rf(from, to)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) []string); ok {
+		r0 = rf(from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteBranch provides a mock function with given fields: _a0
 func (_m *GitInterface) DeleteBranch(_a0 string) error {
 	ret := _m.Called(_a0)
@@ -63,6 +179,64 @@ func (_m *GitInterface) DeleteBranch(_a0 string) error {
 	return r0
 }
 
+// Diff provides a mock function with given fields: from, to
+func (_m *GitInterface) Diff(from string, to string) (string, error) {
+	ret := _m.Called(from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Diff")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (string, error)); ok {
+		return rf(from, to)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) string); ok {
+		r0 = rf(from, to)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DiffFiles provides a mock function with given fields: from, to
+func (_m *GitInterface) DiffFiles(from string, to string) ([]string, error) {
+	ret := _m.Called(from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DiffFiles")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) ([]string, error)); ok {
+		return rf(from, to)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) []string); ok {
+		r0 = rf(from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ExecCheckoutBranch provides a mock function with given fields: name
 func (_m *GitInterface) ExecCheckoutBranch(name string) (bool, error) {
 	ret := _m.Called(name)
@@ -197,6 +371,34 @@ func (_m *GitInterface) GetPath() string {
 	return r0
 }
 
+// MergeBase provides a mock function with given fields: a, b
+func (_m *GitInterface) MergeBase(a string, b string) (string, error) {
+	ret := _m.Called(a, b)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MergeBase")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (string, error)); ok {
+		return rf(a, b)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) string); ok {
+		r0 = rf(a, b)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(a, b)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Reload provides a mock function with no fields
 func (_m *GitInterface) Reload() {
 	_m.Called()
@@ -207,6 +409,36 @@ func (_m *GitInterface) SetPath(path string) {
 	_m.Called(path)
 }
 
+// TrackedFiles provides a mock function with given fields: ref
+func (_m *GitInterface) TrackedFiles(ref string) ([]string, error) {
+	ret := _m.Called(ref)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TrackedFiles")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]string, error)); ok {
+		return rf(ref)
+	}
+	if rf, ok := ret.Get(0).(func(string) []string); ok {
+		r0 = rf(ref)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(ref)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewGitInterface creates a new instance of GitInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewGitInterface(t interface {
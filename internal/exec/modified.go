@@ -0,0 +1,23 @@
+package exec
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ModifiedFiles returns tracked files that are modified (staged or not) in current repository.
+// New and deleted files are not included.
+func ModifiedFiles() ([]string, error) {
+	out, err := Run(time.Second*10, "git", "diff", "--name-only", "-z", "--diff-filter=M", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list modified files: %w, stderr: %s", err, out.Stderr)
+	}
+	files := make([]string, 0)
+	for _, file := range strings.Split(out.Stdout, "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,31 @@
+package exec_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/exec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModifiedFiles(t *testing.T) {
+	conflictRepo(t)
+
+	files, err := exec.ModifiedFiles()
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	require.NoError(t, os.WriteFile("file.txt", []byte("changed\n"), 0o600))
+	require.NoError(t, os.WriteFile("with space.txt", []byte("new\n"), 0o600))
+	git(t, "add", "with space.txt")
+	git(t, "commit", "-q", "-m", "file with space")
+	require.NoError(t, os.WriteFile("with space.txt", []byte("changed\n"), 0o600))
+	git(t, "add", "with space.txt")
+	require.NoError(t, os.WriteFile("untracked.txt", []byte("new\n"), 0o600))
+	require.NoError(t, os.Remove("other.txt"))
+
+	files, err = exec.ModifiedFiles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"file.txt", "with space.txt"}, files)
+}
@@ -0,0 +1,137 @@
+package exec
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/andrejsstepanovs/andai/internal/sandbox"
+)
+
+// sandboxStopTimeout is how long stopping sandbox of killed command may take.
+const sandboxStopTimeout = 30 * time.Second
+
+// Options configures how RunWithOptions runs program.
+type Options struct {
+	Dir            string        // working directory. Relative to current directory, empty means current.
+	Env            []string      // KEY=value pairs added on top of inherited environment
+	Timeout        time.Duration // zero means no timeout
+	Limits         Limits
+	MaxOutputBytes int            // cap for captured stdout and stderr (each). Zero means no cap.
+	Sandbox        sandbox.Runner // runs program inside sandbox if set
+	Mounts         []string       // host files program needs when running in sandbox (mounted read-only)
+}
+
+// Limits are resource limits of the program. Container sandbox enforces them itself,
+// otherwise they are set with prlimit before program starts (linux only).
+type Limits = sandbox.Limits
+
+// RunWithOptions executes program directly (no shell) in given directory, environment and limits.
+func RunWithOptions(opts Options, name string, args ...string) (Output, error) {
+	ctx := context.Background()
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	cmdExec := CommandString(name, args...)
+	if opts.Dir != "" {
+		log.Printf("EXEC (in %s): %s", opts.Dir, cmdExec)
+	} else {
+		log.Printf("EXEC: %s", cmdExec)
+	}
+
+	command := sandbox.Command{Name: name, Args: args, Dir: opts.Dir, Env: opts.Env, Mounts: opts.Mounts, Limits: opts.Limits}
+	if opts.Sandbox != nil {
+		root, err := os.Getwd()
+		if err != nil {
+			return Output{Command: cmdExec}, fmt.Errorf("failed to get repository root: %w", err)
+		}
+		command.Root = root
+		command.Mounts = make([]string, 0, len(opts.Mounts))
+		for _, mount := range opts.Mounts {
+			if !filepath.IsAbs(mount) {
+				mount = filepath.Join(root, mount)
+			}
+			command.Mounts = append(command.Mounts, mount)
+		}
+		command, err = opts.Sandbox.Wrap(command)
+		if err != nil {
+			return Output{Command: cmdExec}, fmt.Errorf("failed to wrap command in sandbox: %w", err)
+		}
+		log.Printf("SANDBOX: %s", CommandString(command.Name, command.Args...))
+	}
+	if !command.Limits.IsZero() {
+		var err error
+		command, err = limitCommand(command)
+		if err != nil {
+			return Output{Command: cmdExec}, err
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, command.Name, command.Args...) // nolint:gosec
+	cmd.Dir = command.Dir
+	switch {
+	case command.ClearEnv:
+		cmd.Env = command.Env
+	case len(command.Env) > 0:
+		cmd.Env = append(os.Environ(), command.Env...)
+	}
+
+	output, err := runLimited(ctx, cmd, Output{Command: cmdExec}, opts.MaxOutputBytes)
+	if ctx.Err() != nil && command.Stop != nil {
+		stopSandbox(*command.Stop)
+	}
+	return output, err
+}
+
+// stopSandbox stops sandbox of killed command. Killing runner client (docker run) does not stop container itself.
+func stopSandbox(stop sandbox.Command) {
+	ctx, cancel := context.WithTimeout(context.Background(), sandboxStopTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, stop.Name, stop.Args...) // nolint:gosec
+	cmd.Dir = stop.Dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		log.Printf("Failed to stop sandbox (%s): %v %s", CommandString(stop.Name, stop.Args...), err, strings.TrimSpace(string(out)))
+	}
+}
+
+// capWriter passes through first max bytes and silently drops the rest.
+type capWriter struct {
+	w      io.Writer
+	left   int
+	capped bool
+}
+
+func newCapWriter(w io.Writer, maxBytes int) *capWriter {
+	return &capWriter{w: w, left: maxBytes}
+}
+
+func (c *capWriter) Write(p []byte) (int, error) {
+	if c.left <= 0 {
+		c.capped = c.capped || len(p) > 0
+		return len(p), nil
+	}
+	chunk := p
+	if len(chunk) > c.left {
+		chunk = chunk[:c.left]
+		c.capped = true
+	}
+	c.left -= len(chunk)
+	if _, err := c.w.Write(chunk); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func cappedNote(maxBytes int) string {
+	return fmt.Sprintf("[output capped at %d bytes]", maxBytes)
+}
@@ -0,0 +1,142 @@
+package exec_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrejsstepanovs/andai/internal/exec"
+	"github.com/andrejsstepanovs/andai/internal/sandbox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RunWithOptions(t *testing.T) {
+	t.Run("dir and env", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o755))
+
+		output, err := exec.RunWithOptions(exec.Options{
+			Dir: filepath.Join(dir, "sub"),
+			Env: []string{"ANDAI_TEST_VALUE=hello"},
+		}, "sh", "-c", "basename $(pwd); echo $ANDAI_TEST_VALUE")
+		require.NoError(t, err)
+		assert.Equal(t, "sub\nhello", output.Stdout)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		start := time.Now()
+		_, err := exec.RunWithOptions(exec.Options{Timeout: 50 * time.Millisecond}, "sleep", "5")
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 2*time.Second)
+	})
+
+	t.Run("timeout kills background children", func(t *testing.T) {
+		start := time.Now()
+		_, err := exec.RunWithOptions(exec.Options{Timeout: 200 * time.Millisecond}, "sh", "-c", "sleep 8 & wait")
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 3*time.Second)
+	})
+
+	t.Run("output cap", func(t *testing.T) {
+		output, err := exec.RunWithOptions(exec.Options{MaxOutputBytes: 20}, "sh", "-c", "yes | head -n 1000; yes no | head -n 1000 >&2")
+		require.NoError(t, err)
+		assert.Equal(t, strings.Repeat("y\n", 10)+"[output capped at 20 bytes]", output.Stdout)
+		assert.Equal(t, strings.Repeat("no\n", 6)+"no\n[output capped at 20 bytes]", output.Stderr)
+	})
+
+	t.Run("output cap keeps stream complete", func(t *testing.T) {
+		lines := 0
+		previous := exec.SetStream(func(_, _ string) { lines++ })
+		defer exec.SetStream(previous)
+
+		output, err := exec.RunWithOptions(exec.Options{MaxOutputBytes: 20}, "sh", "-c", "yes | head -n 1000")
+		require.NoError(t, err)
+		assert.Equal(t, strings.Repeat("y\n", 10)+"[output capped at 20 bytes]", output.Stdout)
+		assert.Equal(t, 1000, lines)
+	})
+
+	t.Run("file size limit", func(t *testing.T) {
+		if runtime.GOOS != "linux" {
+			t.Skip("resource limits are supported only on linux")
+		}
+		target := filepath.Join(t.TempDir(), "big")
+		// no delay, limit must be in place before program starts
+		_, err := exec.RunWithOptions(exec.Options{
+			Limits: exec.Limits{FileSizeBytes: 1024},
+		}, "sh", "-c", "head -c 100000 /dev/zero > "+target)
+		require.Error(t, err)
+
+		info, statErr := os.Stat(target)
+		require.NoError(t, statErr)
+		assert.LessOrEqual(t, info.Size(), int64(1024))
+	})
+}
+
+// fakeSandbox runs command through sh, announcing what it wraps.
+type fakeSandbox struct {
+	wrapped []sandbox.Command
+}
+
+func (f *fakeSandbox) Wrap(cmd sandbox.Command) (sandbox.Command, error) {
+	f.wrapped = append(f.wrapped, cmd)
+	args := append([]string{"-c", `echo "sandboxed in $(pwd)"; exec "$@"`, "sh", cmd.Name}, cmd.Args...)
+	return sandbox.Command{Name: "sh", Args: args, Dir: cmd.Root, Env: []string{"ONLY=this"}, ClearEnv: true}, nil
+}
+
+func Test_RunWithOptions_Sandbox(t *testing.T) {
+	fake := &fakeSandbox{}
+	root, err := os.Getwd()
+	require.NoError(t, err)
+
+	output, err := exec.RunWithOptions(exec.Options{
+		Dir:     "testdata",
+		Env:     []string{"EXTRA=1"},
+		Sandbox: fake,
+		Mounts:  []string{"message.txt", "/etc/hosts"},
+	}, "sh", "-c", "echo ${HOME:-no-home} $ONLY")
+	require.NoError(t, err)
+
+	assert.Equal(t, `sh -c "echo ${HOME:-no-home} $ONLY"`, output.Command)
+	assert.Equal(t, "sandboxed in "+root+"\nno-home this", output.Stdout)
+	require.Len(t, fake.wrapped, 1)
+	assert.Equal(t, sandbox.Command{
+		Name:   "sh",
+		Args:   []string{"-c", "echo ${HOME:-no-home} $ONLY"},
+		Root:   root,
+		Dir:    "testdata",
+		Env:    []string{"EXTRA=1"},
+		Mounts: []string{filepath.Join(root, "message.txt"), "/etc/hosts"},
+	}, fake.wrapped[0])
+}
+
+// stoppedSandbox runs command as is and records stop in marker file.
+type stoppedSandbox struct {
+	marker string
+}
+
+func (s stoppedSandbox) Wrap(cmd sandbox.Command) (sandbox.Command, error) {
+	cmd.Dir = cmd.Root
+	cmd.Stop = &sandbox.Command{Name: "touch", Args: []string{s.marker}, Dir: cmd.Root}
+	return cmd, nil
+}
+
+func Test_RunWithOptions_SandboxStop(t *testing.T) {
+	t.Run("finished command", func(t *testing.T) {
+		marker := filepath.Join(t.TempDir(), "stopped")
+		_, err := exec.RunWithOptions(exec.Options{Sandbox: stoppedSandbox{marker: marker}}, "true")
+		require.NoError(t, err)
+		assert.NoFileExists(t, marker)
+	})
+
+	t.Run("timeout stops sandbox", func(t *testing.T) {
+		marker := filepath.Join(t.TempDir(), "stopped")
+		_, err := exec.RunWithOptions(exec.Options{Sandbox: stoppedSandbox{marker: marker}, Timeout: 200 * time.Millisecond}, "sleep", "5")
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.FileExists(t, marker)
+	})
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package exec
+
+import "os/exec"
+
+// killProcessGroup is not supported, only direct child is killed on cancel.
+func killProcessGroup(_ *exec.Cmd) {}
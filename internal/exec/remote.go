@@ -0,0 +1,58 @@
+package exec
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// EnsureRemote makes sure git remote exists. If url is given, remote is added or its url updated.
+func EnsureRemote(name, url string) error {
+	current, err := Run(time.Second*10, "git", "remote", "get-url", name)
+	if err == nil {
+		if url == "" || current.Stdout == url {
+			return nil
+		}
+		out, err := Run(time.Second*10, "git", "remote", "set-url", name, url)
+		if err != nil {
+			return fmt.Errorf("failed to update remote %q url: %w, stderr: %s", name, err, out.Stderr)
+		}
+		log.Printf("Updated remote %q url", name)
+		return nil
+	}
+	if url == "" {
+		return fmt.Errorf("git remote %q does not exist and no url is configured", name)
+	}
+
+	out, err := Run(time.Second*10, "git", "remote", "add", name, url)
+	if err != nil {
+		return fmt.Errorf("failed to add remote %q: %w, stderr: %s", name, err, out.Stderr)
+	}
+	log.Printf("Added remote %q", name)
+	return nil
+}
+
+// Push pushes local branch to remote branch. Force push uses --force-with-lease,
+// so it fails if remote branch moved since it was last fetched.
+func Push(remote, localBranch, remoteBranch string, force bool) (Output, error) {
+	args := []string{"push", "--porcelain"}
+	if force {
+		args = append(args, "--force-with-lease")
+	}
+	args = append(args, remote, fmt.Sprintf("refs/heads/%s:refs/heads/%s", localBranch, remoteBranch))
+
+	out, err := Run(5*time.Minute, "git", args...)
+	if err != nil {
+		return out, fmt.Errorf("failed to push %q to %s/%s: %w", localBranch, remote, remoteBranch, err)
+	}
+	return out, nil
+}
+
+// RevParse returns commit sha of ref (branch, tag or sha). Empty if ref does not exist.
+func RevParse(ref string) string {
+	out, err := Run(time.Second*10, "git", "rev-parse", "-q", "--verify", ref+"^{commit}")
+	if err != nil {
+		return ""
+	}
+	return out.Stdout
+}
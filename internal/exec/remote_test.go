@@ -0,0 +1,86 @@
+package exec_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andrejsstepanovs/andai/internal/exec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pushRepo creates work repository with "main" and "AI-1" branches and bare repository used as remote.
+func pushRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	bare := filepath.Join(dir, "remote.git")
+	git(t, "init", "-q", "--bare", bare)
+
+	initRepo(t, filepath.Join(dir, "work"))
+	require.NoError(t, os.WriteFile("file.txt", []byte("hello\n"), 0o600))
+	git(t, "add", ".")
+	git(t, "commit", "-q", "-m", "initial")
+	git(t, "checkout", "-q", "-b", "AI-1")
+	require.NoError(t, os.WriteFile("file.txt", []byte("hello AI\n"), 0o600))
+	git(t, "commit", "-q", "-am", "issue change")
+
+	return bare
+}
+
+func remoteHead(t *testing.T, bare, branch string) string {
+	t.Helper()
+	out, err := exec.Run(10*time.Second, "git", "--git-dir", bare, "rev-parse", "-q", "--verify", "refs/heads/"+branch)
+	if err != nil {
+		return ""
+	}
+	return out.Stdout
+}
+
+func TestEnsureRemote(t *testing.T) {
+	bare := pushRepo(t)
+
+	require.Error(t, exec.EnsureRemote("origin", ""), "missing remote without url")
+	require.NoError(t, exec.EnsureRemote("origin", bare))
+	require.NoError(t, exec.EnsureRemote("origin", ""), "existing remote")
+
+	other := filepath.Join(filepath.Dir(bare), "other.git")
+	require.NoError(t, exec.EnsureRemote("origin", other))
+	assert.Equal(t, other, git(t, "remote", "get-url", "origin").Stdout)
+}
+
+func TestPush(t *testing.T) {
+	bare := pushRepo(t)
+	require.NoError(t, exec.EnsureRemote("origin", bare))
+
+	_, err := exec.Push("origin", "main", "main", false)
+	require.NoError(t, err)
+	assert.Equal(t, exec.RevParse("main"), remoteHead(t, bare, "main"))
+
+	_, err = exec.Push("origin", "AI-1", "ai/issue-1", false)
+	require.NoError(t, err)
+	assert.Equal(t, exec.RevParse("AI-1"), remoteHead(t, bare, "ai/issue-1"))
+	assert.Empty(t, remoteHead(t, bare, "AI-1"))
+
+	// rewrite issue branch history
+	git(t, "commit", "-q", "--amend", "-m", "issue change rewritten")
+	rewritten := exec.RevParse("AI-1")
+
+	_, err = exec.Push("origin", "AI-1", "ai/issue-1", false)
+	require.Error(t, err, "non fast-forward push must be rejected without force")
+	assert.NotEqual(t, rewritten, remoteHead(t, bare, "ai/issue-1"))
+
+	git(t, "fetch", "-q", "origin")
+	_, err = exec.Push("origin", "AI-1", "ai/issue-1", true)
+	require.NoError(t, err)
+	assert.Equal(t, rewritten, remoteHead(t, bare, "ai/issue-1"))
+}
+
+func TestRevParse(t *testing.T) {
+	pushRepo(t)
+
+	assert.Len(t, exec.RevParse("main"), 40)
+	assert.NotEqual(t, exec.RevParse("main"), exec.RevParse("AI-1"))
+	assert.Empty(t, exec.RevParse("missing-branch"))
+}
@@ -0,0 +1,39 @@
+package exec
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+
+	"github.com/andrejsstepanovs/andai/internal/sandbox"
+)
+
+// limitCommand runs command through prlimit, so limits are in place before program starts.
+func limitCommand(cmd sandbox.Command) (sandbox.Command, error) {
+	if cmd.Limits.Processes > 0 || cmd.Limits.CPUs > 0 {
+		log.Println("Processes and CPUs limits are applied only in container sandbox, ignoring them")
+	}
+
+	args := make([]string, 0)
+	if cmd.Limits.CPUSeconds > 0 {
+		args = append(args, fmt.Sprintf("--cpu=%d", cmd.Limits.CPUSeconds))
+	}
+	if cmd.Limits.MemoryBytes > 0 {
+		args = append(args, fmt.Sprintf("--as=%d", cmd.Limits.MemoryBytes))
+	}
+	if cmd.Limits.FileSizeBytes > 0 {
+		args = append(args, fmt.Sprintf("--fsize=%d", cmd.Limits.FileSizeBytes))
+	}
+	cmd.Limits = Limits{}
+	if len(args) == 0 {
+		return cmd, nil
+	}
+
+	if _, err := exec.LookPath("prlimit"); err != nil {
+		return sandbox.Command{}, fmt.Errorf("resource limits need prlimit (util-linux) err: %w", err)
+	}
+	args = append(args, "--", cmd.Name)
+	cmd.Name = "prlimit"
+	cmd.Args = append(args, cmd.Args...)
+	return cmd, nil
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package exec
+
+import (
+	"log"
+
+	"github.com/andrejsstepanovs/andai/internal/sandbox"
+)
+
+func limitCommand(cmd sandbox.Command) (sandbox.Command, error) {
+	log.Println("Resource limits are supported only on linux, ignoring them")
+	cmd.Limits = Limits{}
+	return cmd, nil
+}
@@ -0,0 +1,79 @@
+package exec
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+)
+
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+// StreamFunc receives command output line by line while command is still running.
+type StreamFunc func(source, line string)
+
+var (
+	streamMu sync.RWMutex
+	stream   StreamFunc
+)
+
+// SetStream sets (or clears with nil) receiver of live command output. Returns previous one so it can be restored.
+func SetStream(fn StreamFunc) StreamFunc {
+	streamMu.Lock()
+	defer streamMu.Unlock()
+	previous := stream
+	stream = fn
+	return previous
+}
+
+func getStream() StreamFunc {
+	streamMu.RLock()
+	defer streamMu.RUnlock()
+	return stream
+}
+
+// lineWriter splits written bytes into lines and passes complete lines to StreamFunc.
+type lineWriter struct {
+	mu     sync.Mutex
+	source string
+	fn     StreamFunc
+	buf    bytes.Buffer
+}
+
+func newLineWriter(source string, fn StreamFunc) *lineWriter {
+	return &lineWriter{source: source, fn: fn}
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err == io.EOF {
+			// not a full line yet, put it back
+			w.buf.Reset()
+			w.buf.WriteString(line)
+			break
+		}
+		w.fn(w.source, trimLineEnd(line))
+	}
+	return len(p), nil
+}
+
+// Flush passes remaining (not new line terminated) output.
+func (w *lineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.buf.Len() > 0 {
+		w.fn(w.source, trimLineEnd(w.buf.String()))
+		w.buf.Reset()
+	}
+}
+
+func trimLineEnd(line string) string {
+	return strings.TrimRight(line, "\r\n")
+}
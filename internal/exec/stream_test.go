@@ -0,0 +1,46 @@
+package exec_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andrejsstepanovs/andai/internal/exec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SetStream(t *testing.T) {
+	var mu sync.Mutex
+	lines := make(map[string][]string)
+	previous := exec.SetStream(func(source, line string) {
+		mu.Lock()
+		defer mu.Unlock()
+		lines[source] = append(lines[source], line)
+	})
+	defer exec.SetStream(previous)
+
+	output, err := exec.Run(10*time.Second, "sh", "-c", "echo one; echo two; echo oops >&2; printf three")
+	require.NoError(t, err)
+
+	assert.Equal(t, "one\ntwo\nthree", output.Stdout, "output is still buffered")
+	assert.Equal(t, "oops", output.Stderr)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"one", "two", "three"}, lines[exec.StreamStdout])
+	assert.Equal(t, []string{"oops"}, lines[exec.StreamStderr])
+}
+
+func Test_SetStreamRestore(t *testing.T) {
+	called := false
+	first := func(_, _ string) { called = true }
+
+	previous := exec.SetStream(first)
+	restored := exec.SetStream(previous)
+	assert.NotNil(t, restored)
+
+	_, err := exec.Run(10*time.Second, "echo", "hello")
+	require.NoError(t, err)
+	assert.False(t, called, "stream was restored to previous one")
+}
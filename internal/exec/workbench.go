@@ -1,28 +1,38 @@
 package exec
 
 import (
-	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/andrejsstepanovs/andai/internal/branchname"
 	model "github.com/andrejsstepanovs/andai/internal/redmine"
 	"github.com/mattn/go-redmine"
 )
 
 type Workbench struct {
-	Git        GitInterface
-	Issue      redmine.Issue
-	WorkingDir string
+	Git            GitInterface
+	Issue          redmine.Issue
+	WorkingDir     string
+	Project        string // project identifier, used in BranchTemplate
+	BranchTemplate string // project branch_template. If empty, Git.BranchName is used
 }
 
 type GitInterface interface {
 	GetAffectedFiles(sha string) ([]string, error)
 	GetLastCommits(count int) ([]string, error)
 	GetLastCommitHash() (string, error)
+	MergeBase(a, b string) (string, error)
+	CommitRange(from, to string) ([]string, error)
+	ChangedFiles(from, to string) ([]string, error)
+	DiffFiles(from, to string) ([]string, error)
+	Diff(from, to string) (string, error)
+	CommitDiff(ref string) (string, error)
+	TrackedFiles(ref string) ([]string, error)
 	BranchName(issueID int) string
+	BranchExists(branchName string) (bool, error)
 	CheckoutBranch(name string) error
 	ExecCheckoutBranch(name string) (bool, error)
 	GetPath() string
@@ -104,20 +114,18 @@ func (i *Workbench) CheckoutBranch(branchName string) (bool, error) {
 
 // GetIssueParentLastSha returns the parent and last git sha from issue custom fields.
 func (i *Workbench) GetIssueParentLastSha(issue redmine.Issue) (string, string) {
-	if issue.CustomFields == nil {
-		return "", ""
-	}
 	parentSha := ""
 	lastSha := ""
 	for _, field := range issue.CustomFields {
-		if field.Name == model.CustomFieldParentSha {
-			parentSha = field.Value.(string)
-		}
-		if field.Name != model.CustomFieldLastSha {
-			lastSha = field.Value.(string)
+		value, ok := field.Value.(string)
+		if !ok {
+			continue
 		}
-		if parentSha != "" && lastSha != "" {
-			break
+		switch field.Name {
+		case model.CustomFieldParentSha:
+			parentSha = strings.TrimSpace(value)
+		case model.CustomFieldLastSha:
+			lastSha = strings.TrimSpace(value)
 		}
 	}
 	return parentSha, lastSha
@@ -165,12 +173,34 @@ func (i *Workbench) GetIssueSkipMergeOverride(issue redmine.Issue) bool {
 	return false
 }
 
+// GetIssueBranchName returns branch override (stored on first use) or branch name from project template.
+// Branch created before template was set (AI-123) keeps being used.
 func (i *Workbench) GetIssueBranchName(issue redmine.Issue) string {
 	overrideBranch := i.GetIssueBranchNameOverride(issue)
 	if overrideBranch != "" {
 		return overrideBranch
 	}
-	return i.Git.BranchName(issue.Id)
+	legacyBranch := i.Git.BranchName(issue.Id)
+	if i.BranchTemplate == "" {
+		return legacyBranch
+	}
+	if exists, err := i.Git.BranchExists(legacyBranch); err != nil || exists {
+		return legacyBranch
+	}
+
+	data := branchname.Data{ID: issue.Id, Project: i.Project, Subject: issue.Subject}
+	if issue.Tracker != nil {
+		data.Tracker = issue.Tracker.Name
+	}
+	if issue.Parent != nil {
+		data.ParentID = issue.Parent.Id
+	}
+	name, err := branchname.Render(i.BranchTemplate, data)
+	if err != nil {
+		log.Printf("Failed to render branch name for issue %d, using %q: %v", issue.Id, legacyBranch, err)
+		return legacyBranch
+	}
+	return name
 }
 
 func (i *Workbench) DeleteBranch(branch string) error {
@@ -186,28 +216,73 @@ func (i *Workbench) GetLastCommit() (string, error) {
 	return i.Git.GetLastCommitHash()
 }
 
+// GetCommitsSinceInReverseOrder returns commits made on current branch after sinceSha. Oldest is first.
 func (i *Workbench) GetCommitsSinceInReverseOrder(sinceSha string) ([]string, error) {
-	// from newest to oldest
-	allCommits, err := i.GetBranchCommits(20)
+	return i.GetCommitRange(sinceSha, "HEAD")
+}
+
+// GetCommitRange returns exact commits between two refs or shas (from..to). Oldest is first.
+func (i *Workbench) GetCommitRange(from, to string) ([]string, error) {
+	i.Git.Reload()
+	commits, err := i.Git.CommitRange(from, to)
 	if err != nil {
-		return nil, errors.New("failed to get last commits")
+		return nil, fmt.Errorf("failed to get commits %s..%s err: %v", from, to, err)
 	}
+	return commits, nil
+}
 
-	commits := make([]string, 0)
-	for _, sha := range allCommits {
-		if sha == sinceSha { // until we find the last commit
-			break
-		}
-		commits = append(commits, sha)
+// GetChangedFiles returns files changed in "to" since it forked from "from" (from...to).
+func (i *Workbench) GetChangedFiles(from, to string) ([]string, error) {
+	i.Git.Reload()
+	files, err := i.Git.ChangedFiles(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changed files %s...%s err: %v", from, to, err)
 	}
+	return files, nil
+}
 
-	// reverse the order of commits
-	for k, j := 0, len(commits)-1; k < j; k, j = k+1, j-1 {
-		commits[k], commits[j] = commits[j], commits[k]
+// GetDiffFiles returns files that differ between "from" and "to" (from..to).
+func (i *Workbench) GetDiffFiles(from, to string) ([]string, error) {
+	i.Git.Reload()
+	files, err := i.Git.DiffFiles(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diff files %s..%s err: %v", from, to, err)
 	}
+	return files, nil
+}
 
-	// returns in order from oldest to newest
-	return commits, nil
+// GetBranchDiff returns unified diff of changes made in "to" since it forked from "from" (from...to).
+func (i *Workbench) GetBranchDiff(from, to string) (string, error) {
+	i.Git.Reload()
+	base, err := i.Git.MergeBase(from, to)
+	if err != nil {
+		return "", err
+	}
+	diff, err := i.Git.Diff(base, to)
+	if err != nil {
+		return "", fmt.Errorf("failed to get diff %s...%s err: %v", from, to, err)
+	}
+	return diff, nil
+}
+
+// GetCommitDiff returns unified diff of single commit.
+func (i *Workbench) GetCommitDiff(ref string) (string, error) {
+	i.Git.Reload()
+	diff, err := i.Git.CommitDiff(ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to get diff of %s err: %v", ref, err)
+	}
+	return diff, nil
+}
+
+// GetTrackedFiles returns all files in current commit.
+func (i *Workbench) GetTrackedFiles() ([]string, error) {
+	i.Git.Reload()
+	files, err := i.Git.TrackedFiles("HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tracked files err: %v", err)
+	}
+	return files, nil
 }
 
 func (i *Workbench) GetAffectedFiles(sha string) ([]string, error) {
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/andrejsstepanovs/andai/internal/exec/mocks"
 	gitlib "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/mattn/go-redmine"
@@ -97,6 +98,60 @@ func TestWorkbench_GetIssueBranchNameOverride(t *testing.T) {
 	}
 }
 
+func TestWorkbench_GetIssueBranchName(t *testing.T) {
+	issue := redmine.Issue{
+		Id:      42,
+		Subject: "Fix login (SSO)",
+		Tracker: &redmine.IdName{Id: 1, Name: "Bug"},
+		Parent:  &redmine.Id{Id: 7},
+	}
+	withOverride := issue
+	withOverride.CustomFields = []*redmine.CustomField{{Id: 1, Name: "Branch", Value: "ai/bug/42-old-subject"}}
+
+	tests := []struct {
+		name         string
+		issue        redmine.Issue
+		template     string
+		legacyExists bool
+		expected     string
+	}{
+		{name: "no template", issue: issue, expected: "AI-42"},
+		{name: "template", issue: issue, template: "ai/{{lower .Tracker}}/{{.ParentID}}/{{.ID}}-{{slug .Subject}}", expected: "ai/bug/7/42-fix-login-sso"},
+		{name: "legacy branch exists", issue: issue, template: "ai/{{.ID}}", legacyExists: true, expected: "AI-42"},
+		{name: "stored name wins", issue: withOverride, template: "ai/{{.ID}}", expected: "ai/bug/42-old-subject"},
+		{name: "invalid result falls back", issue: issue, template: "ai/{{.Subject}}", expected: "AI-42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			git := &mocks.GitInterface{}
+			git.On("BranchName", 42).Return("AI-42").Maybe()
+			git.On("BranchExists", "AI-42").Return(tt.legacyExists, nil).Maybe()
+
+			wb := &Workbench{Git: git, Project: "shop", BranchTemplate: tt.template}
+			assert.Equal(t, tt.expected, wb.GetIssueBranchName(tt.issue))
+		})
+	}
+}
+
+func TestWorkbench_GetIssueParentLastSha(t *testing.T) {
+	wb := &Workbench{}
+	issue := redmine.Issue{CustomFields: []*redmine.CustomField{
+		{Id: 1, Name: "Branch", Value: "AI-1"},
+		{Id: 2, Name: "Parent SHA", Value: "aaa"},
+		{Id: 3, Name: "Skip merge", Value: nil},
+		{Id: 4, Name: "Last SHA", Value: " bbb "},
+	}}
+
+	parentSha, lastSha := wb.GetIssueParentLastSha(issue)
+	assert.Equal(t, "aaa", parentSha)
+	assert.Equal(t, "bbb", lastSha)
+
+	parentSha, lastSha = wb.GetIssueParentLastSha(redmine.Issue{})
+	assert.Empty(t, parentSha)
+	assert.Empty(t, lastSha)
+}
+
 func TestWorkbench_PrepareWorkplace(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "workbench-test-*")
 	require.NoError(t, err)
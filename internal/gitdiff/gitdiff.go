@@ -0,0 +1,127 @@
+// Package gitdiff fits unified git diffs into prompt token budget.
+// Diff is split per file, excluded files are dropped, big files are cut and files that do not fit are listed by name.
+package gitdiff
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/andrejsstepanovs/andai/internal/truncate"
+)
+
+const fileHeader = "diff --git "
+
+// File is diff of single file.
+type File struct {
+	Path string
+	Text string
+}
+
+// Options limit rendered diff. Zero token limits mean no limit.
+type Options struct {
+	MaxTokens     int
+	MaxFileTokens int
+	Exclude       []string
+}
+
+// Split splits unified diff into per file diffs.
+func Split(diff string) []File {
+	files := make([]File, 0)
+	for _, chunk := range strings.SplitAfter(diff, "\n"+fileHeader) {
+		chunk = strings.TrimSuffix(chunk, fileHeader)
+		chunk = strings.TrimPrefix(chunk, fileHeader)
+		chunk = strings.Trim(chunk, "\n")
+		if chunk == "" {
+			continue
+		}
+		text := fileHeader + chunk
+		files = append(files, File{Path: filePath(text), Text: text})
+	}
+	return files
+}
+
+// filePath reads file name from "+++ b/name" line or falls back to "diff --git a/name b/name" header.
+func filePath(text string) string {
+	lines := strings.Split(text, "\n")
+	for _, line := range lines {
+		if strings.HasPrefix(line, "@@") {
+			break
+		}
+		if name, ok := strings.CutPrefix(line, "+++ b/"); ok {
+			return name
+		}
+		if name, ok := strings.CutPrefix(line, "--- a/"); ok {
+			return name // deleted file, "+++ /dev/null" follows
+		}
+	}
+	header := strings.TrimPrefix(lines[0], fileHeader)
+	if idx := strings.Index(header, " b/"); idx >= 0 {
+		return header[idx+len(" b/"):]
+	}
+	return strings.TrimPrefix(header, "a/")
+}
+
+// Excluded tells if file path matches any of glob patterns.
+// Pattern without "/" matches file name in any directory ("*.lock"), pattern ending with "/" matches whole directory ("vendor/").
+func Excluded(filePath string, patterns []string) bool {
+	for _, pattern := range patterns {
+		switch {
+		case strings.HasSuffix(pattern, "/"):
+			if strings.HasPrefix(filePath, pattern) || strings.Contains(filePath, "/"+pattern) {
+				return true
+			}
+		case !strings.Contains(pattern, "/"):
+			if ok, _ := path.Match(pattern, path.Base(filePath)); ok {
+				return true
+			}
+		default:
+			if ok, _ := path.Match(pattern, filePath); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ValidatePattern checks glob pattern syntax.
+func ValidatePattern(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("exclude pattern cannot be empty")
+	}
+	if _, err := path.Match(strings.TrimSuffix(pattern, "/"), ""); err != nil {
+		return fmt.Errorf("exclude pattern %q is not valid: %w", pattern, err)
+	}
+	return nil
+}
+
+// Render applies options to unified diff.
+func Render(diff string, opts Options) string {
+	parts := make([]string, 0)
+	excluded := make([]string, 0)
+	omitted := make([]string, 0)
+	used := 0
+
+	for _, file := range Split(diff) {
+		if Excluded(file.Path, opts.Exclude) {
+			excluded = append(excluded, file.Path)
+			continue
+		}
+		text := truncate.Truncate(file.Text, opts.MaxFileTokens, truncate.ModeHead)
+		tokens := truncate.EstimateTokens(text)
+		if opts.MaxTokens > 0 && used+tokens > opts.MaxTokens {
+			omitted = append(omitted, file.Path)
+			continue
+		}
+		used += tokens
+		parts = append(parts, text)
+	}
+
+	if len(omitted) > 0 {
+		parts = append(parts, fmt.Sprintf("... diff of %d more files omitted (too big): %s ...", len(omitted), strings.Join(omitted, ", ")))
+	}
+	if len(excluded) > 0 {
+		parts = append(parts, fmt.Sprintf("... diff of %d excluded files not shown: %s ...", len(excluded), strings.Join(excluded, ", ")))
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,86 @@
+package gitdiff_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/gitdiff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fileDiff(name string, lines int) string {
+	body := make([]string, 0, lines)
+	for n := 0; n < lines; n++ {
+		body = append(body, fmt.Sprintf("+line %d of %s", n, name))
+	}
+	return fmt.Sprintf("diff --git a/%s b/%s\nindex 1111111..2222222 100644\n--- a/%s\n+++ b/%s\n@@ -0,0 +1,%d @@\n%s\n",
+		name, name, name, name, lines, strings.Join(body, "\n"))
+}
+
+const deletedDiff = "diff --git a/old.txt b/old.txt\ndeleted file mode 100644\nindex 1111111..0000000\n--- a/old.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-old\n"
+
+func TestSplit(t *testing.T) {
+	diff := fileDiff("main.go", 2) + deletedDiff + fileDiff("docs/readme.md", 1)
+
+	files := gitdiff.Split(diff)
+	require.Len(t, files, 3)
+	assert.Equal(t, "main.go", files[0].Path)
+	assert.Equal(t, "old.txt", files[1].Path)
+	assert.Equal(t, "docs/readme.md", files[2].Path)
+	assert.True(t, strings.HasPrefix(files[1].Text, "diff --git a/old.txt"))
+	assert.True(t, strings.HasSuffix(files[1].Text, "-old"))
+
+	assert.Empty(t, gitdiff.Split(""))
+}
+
+func TestExcluded(t *testing.T) {
+	patterns := []string{"*.lock", "go.sum", "vendor/", "web/*.min.js"}
+
+	assert.True(t, gitdiff.Excluded("yarn.lock", patterns))
+	assert.True(t, gitdiff.Excluded("app/Gemfile.lock", patterns))
+	assert.True(t, gitdiff.Excluded("go.sum", patterns))
+	assert.True(t, gitdiff.Excluded("vendor/pkg/a.go", patterns))
+	assert.True(t, gitdiff.Excluded("service/vendor/pkg/a.go", patterns))
+	assert.True(t, gitdiff.Excluded("web/app.min.js", patterns))
+	assert.False(t, gitdiff.Excluded("web/lib/app.min.js", patterns))
+	assert.False(t, gitdiff.Excluded("main.go", patterns))
+	assert.False(t, gitdiff.Excluded("vendors.go", patterns))
+}
+
+func TestValidatePattern(t *testing.T) {
+	assert.NoError(t, gitdiff.ValidatePattern("*.lock"))
+	assert.NoError(t, gitdiff.ValidatePattern("vendor/"))
+	assert.Error(t, gitdiff.ValidatePattern("[a-"))
+	assert.Error(t, gitdiff.ValidatePattern(" "))
+}
+
+func TestRender(t *testing.T) {
+	diff := fileDiff("main.go", 3) + fileDiff("yarn.lock", 3) + fileDiff("big.go", 500) + fileDiff("other.go", 3)
+
+	t.Run("no limits", func(t *testing.T) {
+		assert.Equal(t, strings.TrimSpace(diff), gitdiff.Render(diff, gitdiff.Options{}))
+	})
+
+	t.Run("exclude", func(t *testing.T) {
+		out := gitdiff.Render(diff, gitdiff.Options{Exclude: []string{"*.lock"}})
+		assert.NotContains(t, out, "+line 0 of yarn.lock")
+		assert.Contains(t, out, "... diff of 1 excluded files not shown: yarn.lock ...")
+	})
+
+	t.Run("file limit", func(t *testing.T) {
+		out := gitdiff.Render(diff, gitdiff.Options{MaxFileTokens: 100})
+		assert.Contains(t, out, "+++ b/big.go")
+		assert.Contains(t, out, "lines omitted")
+		assert.NotContains(t, out, "+line 499 of big.go")
+		assert.Contains(t, out, "+line 2 of other.go")
+	})
+
+	t.Run("total limit", func(t *testing.T) {
+		out := gitdiff.Render(diff, gitdiff.Options{MaxTokens: 200})
+		assert.Contains(t, out, "+line 2 of main.go")
+		assert.Contains(t, out, "+line 2 of other.go", "smaller files after big one still fit")
+		assert.Contains(t, out, "... diff of 1 more files omitted (too big): big.go ...")
+	})
+}
@@ -0,0 +1,198 @@
+// Package lessons maintains project knowledge page with durable lessons learned while working on issues.
+// Page is plain markdown list, one lesson per line, so people can edit it in redmine wiki.
+package lessons
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/andrejsstepanovs/andai/internal/codesearch"
+)
+
+const (
+	// PageTitle is redmine wiki page where lessons are kept.
+	PageTitle = "Lessons"
+
+	// duplicateSimilarity is term overlap above which two lessons are considered the same.
+	duplicateSimilarity = 0.6
+
+	header = "# Lessons learned\n\n" +
+		"Lessons learned while working on issues. Maintained by andai, edit freely: " +
+		"one lesson per `- ` line, `(#id)` at the end marks issues lesson came from.\n"
+)
+
+var (
+	itemRe   = regexp.MustCompile(`^\s*[-*]\s+(.+)$`)
+	issuesRe = regexp.MustCompile(`\s*\((#\d+(?:\s*,\s*#\d+)*)\)\s*$`)
+)
+
+// Lesson is single page entry.
+type Lesson struct {
+	Text   string
+	Issues []int // issues lesson was learned from
+	line   int   // line number in page
+}
+
+// String renders lesson as page line.
+func (l Lesson) String() string {
+	if len(l.Issues) == 0 {
+		return "- " + l.Text
+	}
+	refs := make([]string, len(l.Issues))
+	for n, id := range l.Issues {
+		refs[n] = fmt.Sprintf("#%d", id)
+	}
+	return fmt.Sprintf("- %s (%s)", l.Text, strings.Join(refs, ", "))
+}
+
+// Parse returns all lessons in page. Lines that are not list items are ignored.
+func Parse(page string) []Lesson {
+	found := make([]Lesson, 0)
+	for n, line := range strings.Split(page, "\n") {
+		match := itemRe.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		lesson := Lesson{Text: strings.TrimSpace(match[1]), line: n}
+		if refs := issuesRe.FindStringSubmatch(lesson.Text); refs != nil {
+			lesson.Text = strings.TrimSpace(strings.TrimSuffix(lesson.Text, refs[0]))
+			for _, ref := range strings.Split(refs[1], ",") {
+				id, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(ref), "#"))
+				if err == nil {
+					lesson.Issues = append(lesson.Issues, id)
+				}
+			}
+		}
+		if lesson.Text != "" {
+			found = append(found, lesson)
+		}
+	}
+	return found
+}
+
+// Update adds new lessons learned from issue to page. Lessons that are already on page only get issue reference.
+// Rest of page (edited by people) is kept as is. Returns updated page and lessons that were added.
+func Update(page string, learned []string, issueID int) (string, []string) {
+	if strings.TrimSpace(page) == "" {
+		page = header
+	}
+	lines := strings.Split(strings.TrimRight(page, "\n"), "\n")
+	existing := Parse(page)
+
+	added := make([]string, 0)
+	for _, text := range learned {
+		text = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(text), "-* "))
+		if text == "" {
+			continue
+		}
+		if n := findDuplicate(existing, text); n >= 0 {
+			lesson := &existing[n]
+			if issueID > 0 && !slices.Contains(lesson.Issues, issueID) {
+				lesson.Issues = append(lesson.Issues, issueID)
+				if lesson.line >= 0 {
+					lines[lesson.line] = lesson.String()
+				}
+			}
+			continue
+		}
+		lesson := Lesson{Text: text, line: -1}
+		if issueID > 0 {
+			lesson.Issues = []int{issueID}
+		}
+		existing = append(existing, lesson)
+		added = append(added, text)
+	}
+
+	newLines := make([]string, 0, len(added))
+	for _, lesson := range existing {
+		if lesson.line < 0 {
+			newLines = append(newLines, lesson.String())
+		}
+	}
+	if len(newLines) > 0 {
+		if last := lines[len(lines)-1]; strings.TrimSpace(last) != "" && itemRe.FindStringSubmatch(last) == nil {
+			lines = append(lines, "")
+		}
+		lines = append(lines, newLines...)
+	}
+	return strings.Join(lines, "\n") + "\n", added
+}
+
+// Relevant returns up to limit lessons that share most terms with text. Page order is kept.
+// Newer lessons win when score is equal.
+func Relevant(all []Lesson, text string, limit int) []Lesson {
+	if limit <= 0 || len(all) <= limit {
+		return all
+	}
+	words := termSet(text)
+	type scored struct {
+		index int
+		score int
+	}
+	scores := make([]scored, len(all))
+	for n, lesson := range all {
+		score := 0
+		for term := range termSet(lesson.Text) {
+			if words[term] {
+				score++
+			}
+		}
+		scores[n] = scored{index: n, score: score}
+	}
+	slices.SortStableFunc(scores, func(a, b scored) int {
+		if a.score != b.score {
+			return b.score - a.score
+		}
+		return b.index - a.index
+	})
+
+	picked := make([]int, 0, limit)
+	for _, s := range scores[:limit] {
+		picked = append(picked, s.index)
+	}
+	slices.Sort(picked)
+	relevant := make([]Lesson, len(picked))
+	for n, index := range picked {
+		relevant[n] = all[index]
+	}
+	return relevant
+}
+
+// findDuplicate returns index of lesson that says the same as text or -1.
+func findDuplicate(all []Lesson, text string) int {
+	terms := termSet(text)
+	for n, lesson := range all {
+		if strings.EqualFold(lesson.Text, text) {
+			return n
+		}
+		if len(terms) > 0 && similarity(terms, termSet(lesson.Text)) >= duplicateSimilarity {
+			return n
+		}
+	}
+	return -1
+}
+
+func termSet(text string) map[string]bool {
+	terms := make(map[string]bool)
+	for _, term := range codesearch.Tokenize(text) {
+		terms[term] = true
+	}
+	return terms
+}
+
+// similarity is Jaccard index of term sets.
+func similarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for term := range a {
+		if b[term] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
@@ -0,0 +1,106 @@
+package lessons_test
+
+import (
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/lessons"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const page = `# Lessons learned
+
+Team notes.
+
+- Tests need ` + "`make db-up`" + ` before running. (#12)
+* Never touch generated files in /gen (#15, #20)
+- Keep migrations backwards compatible
+not a lesson line
+`
+
+func TestParse(t *testing.T) {
+	found := lessons.Parse(page)
+	require.Len(t, found, 3)
+	assert.Equal(t, "Tests need `make db-up` before running.", found[0].Text)
+	assert.Equal(t, []int{12}, found[0].Issues)
+	assert.Equal(t, "Never touch generated files in /gen", found[1].Text)
+	assert.Equal(t, []int{15, 20}, found[1].Issues)
+	assert.Empty(t, found[2].Issues)
+	assert.Equal(t, "- Never touch generated files in /gen (#15, #20)", found[1].String())
+}
+
+func TestUpdate(t *testing.T) {
+	t.Run("empty page gets header", func(t *testing.T) {
+		updated, added := lessons.Update("", []string{"Run linters before commit"}, 3)
+		assert.Equal(t, []string{"Run linters before commit"}, added)
+		assert.Contains(t, updated, "# Lessons learned")
+		assert.Contains(t, updated, "\n\n- Run linters before commit (#3)\n")
+	})
+
+	t.Run("duplicates get issue reference", func(t *testing.T) {
+		learned := []string{
+			"- never touch generated files in /gen",
+			"Tests need make db-up before running them",
+			"API handlers must validate pagination params",
+			"API handlers must validate pagination params.",
+			"",
+		}
+		updated, added := lessons.Update(page, learned, 31)
+
+		assert.Equal(t, []string{"API handlers must validate pagination params"}, added)
+		assert.Contains(t, updated, "- Never touch generated files in /gen (#15, #20, #31)\n")
+		assert.Contains(t, updated, "- Tests need `make db-up` before running. (#12, #31)\n")
+		assert.Contains(t, updated, "Team notes.\n")
+		assert.Contains(t, updated, "not a lesson line\n\n- API handlers must validate pagination params (#31)\n")
+		assert.Len(t, lessons.Parse(updated), 4)
+	})
+
+	t.Run("same issue is referenced once", func(t *testing.T) {
+		updated, added := lessons.Update(page, []string{"Never touch generated files in /gen"}, 20)
+		assert.Empty(t, added)
+		assert.Equal(t, page, updated)
+	})
+}
+
+func TestRelevant(t *testing.T) {
+	all := lessons.Parse("- Payment client retries must be idempotent\n" +
+		"- Tests need database running\n" +
+		"- Frontend uses pnpm, not npm\n" +
+		"- Payment refunds are async\n")
+
+	tests := []struct {
+		name     string
+		text     string
+		limit    int
+		expected []string
+	}{
+		{
+			name:     "all fit",
+			text:     "anything",
+			limit:    10,
+			expected: []string{"Payment client retries must be idempotent", "Tests need database running", "Frontend uses pnpm, not npm", "Payment refunds are async"},
+		},
+		{
+			name:     "best matches in page order",
+			text:     "Refund payment twice when client retries",
+			limit:    2,
+			expected: []string{"Payment client retries must be idempotent", "Payment refunds are async"},
+		},
+		{
+			name:     "newest when nothing matches",
+			text:     "update readme",
+			limit:    1,
+			expected: []string{"Payment refunds are async"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			texts := make([]string, 0)
+			for _, lesson := range lessons.Relevant(all, tt.text, tt.limit) {
+				texts = append(texts, lesson.Text)
+			}
+			assert.Equal(t, tt.expected, texts)
+		})
+	}
+}
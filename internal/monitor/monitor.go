@@ -0,0 +1,276 @@
+// Package monitor keeps track of what the work loop is doing right now.
+// It is read by status API / dashboard and controls (pause, resume, skip) are written back through it.
+package monitor
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxRecentResults is how many finished steps are kept for display.
+const maxRecentResults = 50
+
+// Default is the tracker used by the work loop.
+var Default = NewTracker()
+
+// Issue is the issue that is currently being worked on.
+type Issue struct {
+	ID        int       `json:"id"`
+	Subject   string    `json:"subject"`
+	Type      string    `json:"type"`
+	State     string    `json:"state"`
+	Project   string    `json:"project"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Step is currently running workflow step.
+type Step struct {
+	Index     int       `json:"index"`
+	Total     int       `json:"total"`
+	Command   string    `json:"command"`
+	Action    string    `json:"action,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// StepResult is a finished workflow step.
+type StepResult struct {
+	IssueID    int       `json:"issue_id"`
+	Command    string    `json:"command"`
+	Action     string    `json:"action,omitempty"`
+	Success    bool      `json:"success"`
+	Error      string    `json:"error,omitempty"`
+	Duration   string    `json:"duration"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// QueueItem is an open issue with a reason why it is (or is not) going to be worked on.
+type QueueItem struct {
+	ID      int    `json:"id"`
+	Subject string `json:"subject"`
+	Type    string `json:"type"`
+	State   string `json:"state"`
+	Project string `json:"project"`
+	Reason  string `json:"reason"`
+}
+
+// Snapshot is a consistent copy of tracker state.
+type Snapshot struct {
+	StartedAt      time.Time    `json:"started_at"`
+	Paused         bool         `json:"paused"`
+	Current        *Issue       `json:"current,omitempty"`
+	CurrentStep    *Step        `json:"current_step,omitempty"`
+	Elapsed        string       `json:"elapsed,omitempty"`
+	StepElapsed    string       `json:"step_elapsed,omitempty"`
+	Queue          []QueueItem  `json:"queue"`
+	QueueUpdatedAt time.Time    `json:"queue_updated_at"`
+	RecentResults  []StepResult `json:"recent_results"`
+	Skipped        []int        `json:"skipped"`
+	LLMCalls       int          `json:"llm_calls"`
+	AiderCalls     int          `json:"aider_calls"`
+}
+
+// Tracker is safe for concurrent use.
+type Tracker struct {
+	mu             sync.RWMutex
+	startedAt      time.Time
+	paused         bool
+	current        *Issue
+	currentStep    *Step
+	queue          []QueueItem
+	queueUpdatedAt time.Time
+	results        []StepResult
+	skipRequested  bool
+	skipped        map[int]bool
+	llmCalls       int
+	aiderCalls     int
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{
+		startedAt: time.Now(),
+		queue:     make([]QueueItem, 0),
+		results:   make([]StepResult, 0),
+		skipped:   make(map[int]bool),
+	}
+}
+
+// StartIssue marks issue as currently worked on.
+func (t *Tracker) StartIssue(issue Issue) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if issue.StartedAt.IsZero() {
+		issue.StartedAt = time.Now()
+	}
+	t.current = &issue
+	t.currentStep = nil
+	t.skipRequested = false
+}
+
+// FinishIssue clears current issue.
+func (t *Tracker) FinishIssue() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.current = nil
+	t.currentStep = nil
+	t.skipRequested = false
+}
+
+// StartStep marks workflow step as running. Index starts from 1.
+func (t *Tracker) StartStep(index, total int, command, action string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.currentStep = &Step{
+		Index:     index,
+		Total:     total,
+		Command:   command,
+		Action:    action,
+		StartedAt: time.Now(),
+	}
+}
+
+// FinishStep records result of currently running step.
+func (t *Tracker) FinishStep(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.currentStep == nil {
+		return
+	}
+
+	result := StepResult{
+		Command:    t.currentStep.Command,
+		Action:     t.currentStep.Action,
+		Success:    err == nil,
+		Duration:   time.Since(t.currentStep.StartedAt).Round(time.Millisecond).String(),
+		FinishedAt: time.Now(),
+	}
+	if t.current != nil {
+		result.IssueID = t.current.ID
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	t.results = append(t.results, result)
+	if len(t.results) > maxRecentResults {
+		t.results = t.results[len(t.results)-maxRecentResults:]
+	}
+	t.currentStep = nil
+}
+
+// SetQueue replaces known issue queue.
+func (t *Tracker) SetQueue(items []QueueItem) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.queue = append(make([]QueueItem, 0, len(items)), items...)
+	t.queueUpdatedAt = time.Now()
+}
+
+func (t *Tracker) CountLLMCall() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.llmCalls++
+}
+
+func (t *Tracker) CountAiderCall() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.aiderCalls++
+}
+
+// Pause stops loop from picking up new issues. Issue that is being worked on is finished.
+func (t *Tracker) Pause() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.paused = true
+}
+
+func (t *Tracker) Resume() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.paused = false
+}
+
+func (t *Tracker) Paused() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.paused
+}
+
+// SkipCurrent asks to stop working on current issue after running step is done.
+// Skipped issue is left in its state and not picked up again until it is unskipped or loop restarts.
+// Returns false if there is nothing to skip.
+func (t *Tracker) SkipCurrent() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.current == nil {
+		return false
+	}
+	t.skipRequested = true
+	t.skipped[t.current.ID] = true
+	return true
+}
+
+// SkipRequested tells if current issue should be abandoned.
+func (t *Tracker) SkipRequested(issueID int) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.skipRequested && t.current != nil && t.current.ID == issueID
+}
+
+// Unskip lets skipped issue be picked up again. Returns false if issue was not skipped.
+func (t *Tracker) Unskip(issueID int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.skipped[issueID] {
+		return false
+	}
+	delete(t.skipped, issueID)
+	if t.current != nil && t.current.ID == issueID {
+		t.skipRequested = false
+	}
+	return true
+}
+
+// IsSkipped tells if issue was skipped before and should not be picked up.
+func (t *Tracker) IsSkipped(issueID int) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.skipped[issueID]
+}
+
+func (t *Tracker) Snapshot() Snapshot {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	snapshot := Snapshot{
+		StartedAt:      t.startedAt,
+		Paused:         t.paused,
+		Queue:          append(make([]QueueItem, 0, len(t.queue)), t.queue...),
+		QueueUpdatedAt: t.queueUpdatedAt,
+		RecentResults:  make([]StepResult, 0, len(t.results)),
+		Skipped:        make([]int, 0, len(t.skipped)),
+		LLMCalls:       t.llmCalls,
+		AiderCalls:     t.aiderCalls,
+	}
+	// newest first
+	for i := len(t.results) - 1; i >= 0; i-- {
+		snapshot.RecentResults = append(snapshot.RecentResults, t.results[i])
+	}
+	for issueID := range t.skipped {
+		snapshot.Skipped = append(snapshot.Skipped, issueID)
+	}
+	sort.Ints(snapshot.Skipped)
+	if t.current != nil {
+		current := *t.current
+		snapshot.Current = &current
+		snapshot.Elapsed = time.Since(current.StartedAt).Round(time.Second).String()
+	}
+	if t.currentStep != nil {
+		step := *t.currentStep
+		snapshot.CurrentStep = &step
+		snapshot.StepElapsed = time.Since(step.StartedAt).Round(time.Second).String()
+	}
+
+	return snapshot
+}
@@ -0,0 +1,120 @@
+package monitor_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/monitor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracker_IssueAndSteps(t *testing.T) {
+	tracker := monitor.NewTracker()
+
+	snapshot := tracker.Snapshot()
+	assert.Nil(t, snapshot.Current)
+	assert.Nil(t, snapshot.CurrentStep)
+	assert.Empty(t, snapshot.RecentResults)
+
+	tracker.StartIssue(monitor.Issue{ID: 10, Subject: "Fix bug"})
+	tracker.StartStep(1, 2, "git", "status")
+
+	snapshot = tracker.Snapshot()
+	assert.Equal(t, 10, snapshot.Current.ID)
+	assert.Equal(t, "git", snapshot.CurrentStep.Command)
+	assert.Equal(t, 2, snapshot.CurrentStep.Total)
+	assert.NotEmpty(t, snapshot.Elapsed)
+
+	tracker.FinishStep(nil)
+	tracker.StartStep(2, 2, "aider", "code")
+	tracker.FinishStep(errors.New("boom"))
+	tracker.FinishIssue()
+
+	snapshot = tracker.Snapshot()
+	assert.Nil(t, snapshot.Current)
+	assert.Nil(t, snapshot.CurrentStep)
+	assert.Len(t, snapshot.RecentResults, 2)
+	// newest first
+	assert.Equal(t, "aider", snapshot.RecentResults[0].Command)
+	assert.False(t, snapshot.RecentResults[0].Success)
+	assert.Equal(t, "boom", snapshot.RecentResults[0].Error)
+	assert.Equal(t, 10, snapshot.RecentResults[0].IssueID)
+	assert.True(t, snapshot.RecentResults[1].Success)
+}
+
+func TestTracker_FinishStepWithoutStep(t *testing.T) {
+	tracker := monitor.NewTracker()
+	tracker.FinishStep(nil)
+	assert.Empty(t, tracker.Snapshot().RecentResults)
+}
+
+func TestTracker_RecentResultsAreLimited(t *testing.T) {
+	tracker := monitor.NewTracker()
+	tracker.StartIssue(monitor.Issue{ID: 1})
+	for i := 0; i < 100; i++ {
+		tracker.StartStep(i+1, 100, "bash", "")
+		tracker.FinishStep(nil)
+	}
+	assert.Len(t, tracker.Snapshot().RecentResults, 50)
+}
+
+func TestTracker_PauseResume(t *testing.T) {
+	tracker := monitor.NewTracker()
+	assert.False(t, tracker.Paused())
+	tracker.Pause()
+	assert.True(t, tracker.Paused())
+	assert.True(t, tracker.Snapshot().Paused)
+	tracker.Resume()
+	assert.False(t, tracker.Paused())
+}
+
+func TestTracker_Skip(t *testing.T) {
+	tracker := monitor.NewTracker()
+	assert.False(t, tracker.SkipCurrent(), "nothing to skip")
+
+	tracker.StartIssue(monitor.Issue{ID: 7})
+	assert.False(t, tracker.SkipRequested(7))
+	assert.True(t, tracker.SkipCurrent())
+	assert.True(t, tracker.SkipRequested(7))
+	assert.False(t, tracker.SkipRequested(8))
+	assert.True(t, tracker.IsSkipped(7))
+
+	tracker.FinishIssue()
+	assert.False(t, tracker.SkipRequested(7))
+	assert.True(t, tracker.IsSkipped(7), "skipped issue is remembered")
+
+	tracker.StartIssue(monitor.Issue{ID: 3})
+	tracker.SkipCurrent()
+	assert.Equal(t, []int{3, 7}, tracker.Snapshot().Skipped)
+
+	assert.True(t, tracker.Unskip(7))
+	assert.False(t, tracker.IsSkipped(7))
+	assert.False(t, tracker.Unskip(7), "not skipped anymore")
+
+	assert.True(t, tracker.Unskip(3), "current issue skip request is withdrawn")
+	assert.False(t, tracker.SkipRequested(3))
+	assert.Empty(t, tracker.Snapshot().Skipped)
+}
+
+func TestTracker_Counters(t *testing.T) {
+	tracker := monitor.NewTracker()
+	tracker.CountLLMCall()
+	tracker.CountLLMCall()
+	tracker.CountAiderCall()
+
+	snapshot := tracker.Snapshot()
+	assert.Equal(t, 2, snapshot.LLMCalls)
+	assert.Equal(t, 1, snapshot.AiderCalls)
+}
+
+func TestTracker_SetQueue(t *testing.T) {
+	tracker := monitor.NewTracker()
+	items := []monitor.QueueItem{{ID: 1, Reason: "ready"}, {ID: 2, Reason: "blocked by #1"}}
+	tracker.SetQueue(items)
+	items[0].Reason = "changed"
+
+	snapshot := tracker.Snapshot()
+	assert.Len(t, snapshot.Queue, 2)
+	assert.Equal(t, "ready", snapshot.Queue[0].Reason)
+	assert.False(t, snapshot.QueueUpdatedAt.IsZero())
+}
@@ -0,0 +1,51 @@
+package redact
+
+import (
+	"log"
+	"os"
+	"sync"
+
+	"github.com/andrejsstepanovs/andai/internal/settings"
+)
+
+var (
+	mu  sync.RWMutex
+	std = New(settings.Redaction{})
+)
+
+// Setup configures redactor used by Text and File.
+func Setup(cfg settings.Redaction) {
+	mu.Lock()
+	defer mu.Unlock()
+	std = New(cfg)
+}
+
+// Text redacts secrets with configured redactor and logs how many were found.
+func Text(text string) string {
+	mu.RLock()
+	r := std
+	mu.RUnlock()
+
+	redacted, counts := r.Redact(text)
+	if total := counts.Total(); total > 0 {
+		log.Printf("Redacted %d secrets (%s)", total, counts)
+	}
+	return redacted
+}
+
+// File redacts secrets in file in place.
+func File(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	redacted := Text(string(content))
+	if redacted == string(content) {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(redacted), info.Mode().Perm())
+}
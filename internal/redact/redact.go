@@ -0,0 +1,239 @@
+// Package redact removes secrets from text before it is sent to LLM provider.
+// Every secret is replaced with placeholder that stays the same for the same value during the run,
+// so LLM can still tell that two places refer to the same secret.
+package redact
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/andrejsstepanovs/andai/internal/settings"
+)
+
+const (
+	// minLiteralLength is shortest env value that is redacted, shorter ones would match ordinary words.
+	minLiteralLength = 6
+	// minEntropyLength is shortest high-entropy string that is redacted.
+	minEntropyLength = 24
+	// minEntropy is bits per character above which mixed case alphanumeric string is treated as secret.
+	minEntropy = 3.8
+
+	placeholderPrefix = "[REDACTED:"
+)
+
+type detector struct {
+	kind  string
+	re    *regexp.Regexp
+	group int // submatch that is redacted, 0 is whole match
+}
+
+// builtin detectors, most specific first.
+var builtin = []detector{
+	{kind: "private-key", re: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{kind: "aws-key", re: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{kind: "github-token", re: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{kind: "gitlab-token", re: regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`)},
+	{kind: "slack-token", re: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{kind: "stripe-key", re: regexp.MustCompile(`\b(?:sk|rk)_(?:live|test)_[A-Za-z0-9]{16,}\b`)},
+	{kind: "api-key", re: regexp.MustCompile(`\bsk-(?:ant-|proj-)?[A-Za-z0-9_-]{20,}`)},
+	{kind: "google-api-key", re: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`)},
+	{kind: "jwt", re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{kind: "url-password", re: regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.-]*://[^/\s:@]+:([^/\s@]+)@`), group: 1},
+	{kind: "bearer", re: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9._~+/=-]{16,})`), group: 1},
+	{kind: "password", re: regexp.MustCompile(`(?i)[A-Za-z0-9_.-]*(?:password|passwd|pwd|secret|token|api_?key|access_?key)[A-Za-z0-9_]*["']?\s*[:=]\s*["']?([^\s"'` + "`" + `,;]{6,})`), group: 1},
+}
+
+// entropyRe finds candidates for high-entropy check.
+var entropyRe = regexp.MustCompile(`[A-Za-z0-9+/_=-]{24,}`)
+
+// codeRefRe matches values that are code references or settings, not secrets (cfg.Password, os.Getenv("X"), ${DB_PASS}, 128000).
+var codeRefRe = regexp.MustCompile(`^(?:[A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)+|[A-Za-z_][A-Za-z0-9_.]*\(.*|\$.*|<.*>|\*+|x+|X+|[0-9]+|true|false|null|none)$`)
+
+// Counts is number of redacted values per kind.
+type Counts map[string]int
+
+// Total returns number of all redacted values.
+func (c Counts) Total() int {
+	total := 0
+	for _, count := range c {
+		total += count
+	}
+	return total
+}
+
+// String formats counts as "aws-key: 1, password: 2".
+func (c Counts) String() string {
+	kinds := make([]string, 0, len(c))
+	for kind := range c {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	parts := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		parts = append(parts, fmt.Sprintf("%s: %d", kind, c[kind]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+type literal struct {
+	kind  string
+	value string
+}
+
+// Redactor replaces secrets with placeholders.
+type Redactor struct {
+	disabled  bool
+	entropy   bool
+	detectors []detector
+	literals  []literal
+	allow     []string
+
+	mu           sync.Mutex
+	placeholders map[string]string // secret -> placeholder
+	numbers      map[string]int    // last placeholder number per kind
+}
+
+// New creates redactor from config. Env values are read once, here.
+func New(cfg settings.Redaction) *Redactor {
+	r := &Redactor{
+		disabled:     cfg.Disabled,
+		entropy:      cfg.GetEntropy(),
+		detectors:    slices.Clone(builtin),
+		allow:        cfg.Allow,
+		placeholders: make(map[string]string),
+		numbers:      make(map[string]int),
+	}
+	for _, pattern := range cfg.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			continue // validated in settings
+		}
+		group := 0
+		if re.NumSubexp() > 0 {
+			group = 1
+		}
+		r.detectors = append(r.detectors, detector{kind: "custom", re: re, group: group})
+	}
+	for _, name := range cfg.Env {
+		if value := os.Getenv(name); len(value) >= minLiteralLength {
+			r.literals = append(r.literals, literal{kind: name, value: value})
+		}
+	}
+	// longest first, so value that contains other value is replaced whole
+	sort.SliceStable(r.literals, func(a, b int) bool { return len(r.literals[a].value) > len(r.literals[b].value) })
+	return r
+}
+
+// Redact returns text with secrets replaced and number of replaced values per kind.
+func (r *Redactor) Redact(text string) (string, Counts) {
+	counts := make(Counts)
+	if r.disabled || text == "" {
+		return text, counts
+	}
+
+	for _, lit := range r.literals {
+		if strings.Contains(text, lit.value) {
+			counts[lit.kind] += strings.Count(text, lit.value)
+			text = strings.ReplaceAll(text, lit.value, r.placeholder(lit.kind, lit.value))
+		}
+	}
+	for _, d := range r.detectors {
+		text = r.replace(text, d, counts)
+	}
+	if r.entropy {
+		text = entropyRe.ReplaceAllStringFunc(text, func(candidate string) string {
+			if !highEntropy(candidate) || r.allowed(candidate) {
+				return candidate
+			}
+			counts["high-entropy"]++
+			return r.placeholder("high-entropy", candidate)
+		})
+	}
+	return text, counts
+}
+
+func (r *Redactor) replace(text string, d detector, counts Counts) string {
+	matches := d.re.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+	var out strings.Builder
+	last := 0
+	for _, match := range matches {
+		start, end := match[2*d.group], match[2*d.group+1]
+		if start < 0 {
+			continue
+		}
+		secret := text[start:end]
+		if strings.Contains(secret, placeholderPrefix) || r.allowed(secret) || (d.kind == "password" && codeRefRe.MatchString(secret)) {
+			continue
+		}
+		out.WriteString(text[last:start])
+		out.WriteString(r.placeholder(d.kind, secret))
+		last = end
+		counts[d.kind]++
+	}
+	out.WriteString(text[last:])
+	return out.String()
+}
+
+func (r *Redactor) allowed(value string) bool {
+	return slices.Contains(r.allow, value)
+}
+
+// placeholder returns stable placeholder for secret, like "[REDACTED:aws-key#1]".
+func (r *Redactor) placeholder(kind, secret string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if placeholder, ok := r.placeholders[secret]; ok {
+		return placeholder
+	}
+	r.numbers[kind]++
+	placeholder := fmt.Sprintf("%s%s#%d]", placeholderPrefix, kind, r.numbers[kind])
+	r.placeholders[secret] = placeholder
+	return placeholder
+}
+
+// highEntropy is true for long mixed case alphanumeric strings that look random.
+func highEntropy(s string) bool {
+	if len(s) < minEntropyLength {
+		return false
+	}
+	var upper, lower, digit bool
+	for _, c := range s {
+		switch {
+		case unicode.IsUpper(c):
+			upper = true
+		case unicode.IsLower(c):
+			lower = true
+		case unicode.IsDigit(c):
+			digit = true
+		}
+	}
+	if !upper || !lower || !digit {
+		return false
+	}
+	return shannon(s) >= minEntropy
+}
+
+// shannon returns entropy in bits per character.
+func shannon(s string) float64 {
+	freq := make(map[rune]int)
+	for _, c := range s {
+		freq[c]++
+	}
+	entropy := 0.0
+	length := float64(len(s))
+	for _, count := range freq {
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
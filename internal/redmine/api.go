@@ -43,17 +43,33 @@ func (c *Model) APIGetProjects() ([]redmine.Project, error) {
 }
 
 func (c *Model) APISaveWiki(project redmine.Project, content string) error {
-	const TITLE = "Wiki"
+	return c.APISaveWikiPage(project.Id, "Wiki", content, "")
+}
+
+// APIGetWikiPage returns wiki page text. Empty if page does not exist.
+func (c *Model) APIGetWikiPage(projectID int, title string) (string, error) {
+	page, err := c.api.WikiPage(projectID, title)
+	if err != nil {
+		if err.Error() == "Not Found" {
+			return "", nil
+		}
+		return "", fmt.Errorf("error redmine wiki page %q: %v", title, err)
+	}
+	return page.Text, nil
+}
+
+// APISaveWikiPage creates or updates wiki page. Every update is new page version, comment describes it.
+func (c *Model) APISaveWikiPage(projectID int, title, content, comment string) error {
 	content = strings.TrimSpace(content)
 
-	page, err := c.api.WikiPage(project.Id, TITLE)
+	page, err := c.api.WikiPage(projectID, title)
 	if err != nil {
 		if err.Error() != "Not Found" {
 			return fmt.Errorf("error redmine wiki page: %v", err)
 		}
 
-		page = &redmine.WikiPage{Title: TITLE, Text: content}
-		_, err = c.api.CreateWikiPage(project.Id, *page)
+		page = &redmine.WikiPage{Title: title, Text: content, Comments: comment}
+		_, err = c.api.CreateWikiPage(projectID, *page)
 		if err != nil {
 			return fmt.Errorf("error redmine wiki page create: %v", err)
 		}
@@ -61,7 +77,8 @@ func (c *Model) APISaveWiki(project redmine.Project, content string) error {
 	}
 
 	page.Text = content
-	err = c.api.UpdateWikiPage(project.Id, *page)
+	page.Comments = comment
+	err = c.api.UpdateWikiPage(projectID, *page)
 	if err != nil && err.Error() != "EOF" {
 		return fmt.Errorf("error redmine wiki page update: %v", err)
 	}
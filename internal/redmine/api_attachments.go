@@ -0,0 +1,177 @@
+package redmine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/andrejsstepanovs/andai/internal/redmine/models"
+	"github.com/spf13/viper"
+)
+
+const attachmentTimeout = time.Minute
+
+// APIGetAttachments returns files attached to the issue.
+func (c *Model) APIGetAttachments(issueID int) (models.Attachments, error) {
+	parsedURL, err := apiURL(fmt.Sprintf("issues/%d.json", issueID))
+	if err != nil {
+		return nil, err
+	}
+	parsedURL.RawQuery = url.Values{"include": []string{"attachments"}}.Encode()
+
+	body, err := apiGet(parsedURL.String(), -1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue %d attachments: %v", issueID, err)
+	}
+
+	var resp struct {
+		Issue struct {
+			Attachments models.Attachments `json:"attachments"`
+		} `json:"issue"`
+	}
+	if err = json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse issue %d attachments: %v", issueID, err)
+	}
+	return resp.Issue.Attachments, nil
+}
+
+// APIDownloadAttachment returns attachment content. Content longer than limit bytes is an error.
+// Download url is built from redmine url and attachment id, so api key is never sent to other hosts.
+func (c *Model) APIDownloadAttachment(attachment models.Attachment, limit int64) ([]byte, error) {
+	if attachment.FileSize > limit {
+		return nil, fmt.Errorf("attachment %q is too big (%d bytes)", attachment.FileName, attachment.FileSize)
+	}
+	downloadURL, err := apiURL(fmt.Sprintf("attachments/download/%d", attachment.ID))
+	if err != nil {
+		return nil, err
+	}
+	content, err := apiGet(downloadURL.String(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download attachment %q: %v", attachment.FileName, err)
+	}
+	return content, nil
+}
+
+// APIAttachFile uploads file content and attaches it to the issue with a comment (single journal entry).
+func (c *Model) APIAttachFile(issueID int, fileName, contentType string, content []byte, comment string) error {
+	uploadURL, err := apiURL("uploads.json")
+	if err != nil {
+		return err
+	}
+	uploadURL.RawQuery = url.Values{"filename": []string{fileName}}.Encode()
+	body, err := apiSend(http.MethodPost, uploadURL.String(), "application/octet-stream", content)
+	if err != nil {
+		return fmt.Errorf("failed to upload %q: %v", fileName, err)
+	}
+	var upload struct {
+		Upload struct {
+			Token string `json:"token"`
+		} `json:"upload"`
+	}
+	if err = json.Unmarshal(body, &upload); err != nil || upload.Upload.Token == "" {
+		return fmt.Errorf("failed to parse %q upload token: %v", fileName, err)
+	}
+
+	issueURL, err := apiURL(fmt.Sprintf("issues/%d.json", issueID))
+	if err != nil {
+		return err
+	}
+	update := map[string]any{
+		"issue": map[string]any{
+			"notes": MarkAIComment(comment),
+			"uploads": []map[string]string{{
+				"token":        upload.Upload.Token,
+				"filename":     fileName,
+				"content_type": contentType,
+			}},
+		},
+	}
+	payload, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+	_, err = apiSend(http.MethodPut, issueURL.String(), "application/json", payload)
+	if err != nil {
+		return fmt.Errorf("failed to attach %q to issue %d: %v", fileName, issueID, err)
+	}
+	return nil
+}
+
+// apiURL returns redmine API url of given path.
+func apiURL(apiPath string) (*url.URL, error) {
+	baseURL := viper.GetString("redmine.url")
+	if baseURL == "" {
+		return nil, fmt.Errorf("redmine url is empty")
+	}
+	parsedURL, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid redmine URL: %v", err)
+	}
+	parsedURL.Path = path.Join(parsedURL.Path, apiPath)
+	return parsedURL, nil
+}
+
+// apiSend sends body to redmine API url with api key and returns response body.
+func apiSend(method, target, contentType string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Redmine-API-Key", viper.GetString("redmine.api_key"))
+	req.Header.Set("Content-Type", contentType)
+
+	client := http.Client{Timeout: attachmentTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("API error: %s", resp.Status)
+	}
+	return respBody, nil
+}
+
+// apiGet requests redmine API url with api key. Negative limit means no limit.
+func apiGet(target string, limit int64) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Redmine-API-Key", viper.GetString("redmine.api_key"))
+
+	client := http.Client{Timeout: attachmentTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error: %s", resp.Status)
+	}
+
+	var reader io.Reader = resp.Body
+	if limit >= 0 {
+		reader = io.LimitReader(resp.Body, limit+1)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if limit >= 0 && int64(len(body)) > limit {
+		return nil, fmt.Errorf("content is bigger than %d bytes", limit)
+	}
+	return body, nil
+}
@@ -0,0 +1,101 @@
+package redmine_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	model "github.com/andrejsstepanovs/andai/internal/redmine"
+	"github.com/andrejsstepanovs/andai/internal/redmine/models"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModel_APIAttachFile(t *testing.T) {
+	var uploaded string
+	var update map[string]map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("X-Redmine-API-Key"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/redmine/uploads.json":
+			assert.Equal(t, "run.log", r.URL.Query().Get("filename"))
+			assert.Equal(t, "application/octet-stream", r.Header.Get("Content-Type"))
+			uploaded = string(body)
+			rw.WriteHeader(http.StatusCreated)
+			_, _ = rw.Write([]byte(`{"upload":{"id":1,"token":"1.abc"}}`))
+		case r.Method == http.MethodPut && r.URL.Path == "/redmine/issues/7.json":
+			require.NoError(t, json.Unmarshal(body, &update))
+			rw.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	viper.Set("redmine.url", server.URL+"/redmine/")
+	viper.Set("redmine.api_key", "secret")
+	t.Cleanup(func() {
+		viper.Set("redmine.url", "")
+		viper.Set("redmine.api_key", "")
+	})
+
+	err := model.NewModel(nil, nil).APIAttachFile(7, "run.log", "text/plain", []byte("log line\n"), "Run log")
+	require.NoError(t, err)
+
+	assert.Equal(t, "log line\n", uploaded)
+	assert.Equal(t, "Run log\n\n"+model.AICommentMarker, update["issue"]["notes"])
+	assert.Equal(t, []any{map[string]any{"token": "1.abc", "filename": "run.log", "content_type": "text/plain"}}, update["issue"]["uploads"])
+}
+
+func TestModel_APIAttachFile_UploadFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusUnprocessableEntity)
+	}))
+	defer server.Close()
+
+	viper.Set("redmine.url", server.URL)
+	t.Cleanup(func() { viper.Set("redmine.url", "") })
+
+	err := model.NewModel(nil, nil).APIAttachFile(7, "run.log", "text/plain", []byte("x"), "Run log")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "422")
+}
+
+func TestModel_APIDownloadAttachment(t *testing.T) {
+	foreign := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		t.Errorf("api key sent to foreign host: %q", r.Header.Get("X-Redmine-API-Key"))
+		rw.WriteHeader(http.StatusNotFound)
+	}))
+	defer foreign.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("X-Redmine-API-Key"))
+		assert.Equal(t, "/redmine/attachments/download/5", r.URL.Path)
+		_, _ = rw.Write([]byte("file content"))
+	}))
+	defer server.Close()
+
+	viper.Set("redmine.url", server.URL+"/redmine/")
+	viper.Set("redmine.api_key", "secret")
+	t.Cleanup(func() {
+		viper.Set("redmine.url", "")
+		viper.Set("redmine.api_key", "")
+	})
+
+	attachment := models.Attachment{
+		ID:         5,
+		FileName:   "spec.md",
+		FileSize:   12,
+		ContentURL: foreign.URL + "/attachments/download/5/spec.md",
+	}
+	content, err := model.NewModel(nil, nil).APIDownloadAttachment(attachment, 100)
+	require.NoError(t, err)
+	assert.Equal(t, "file content", string(content))
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"log"
 	"sort"
+	"strings"
 
+	"github.com/andrejsstepanovs/andai/internal/redmine/models"
 	"github.com/andrejsstepanovs/andai/internal/settings"
 	_ "github.com/go-sql-driver/mysql" // mysql driver
 	"github.com/mattn/go-redmine"
@@ -112,12 +114,19 @@ func (c *Model) GetValidProjects() ([]redmine.Project, error) {
 }
 
 func (c *Model) APIGetWorkableIssues(workflow settings.Workflow, projects []redmine.Project) ([]redmine.Issue, error) {
+	workable, _, err := c.APIGetIssueQueue(workflow, projects)
+	return workable, err
+}
+
+// APIGetIssueQueue returns workable issues (in priority order) and issues that are blocked by other open issues.
+func (c *Model) APIGetIssueQueue(workflow settings.Workflow, projects []redmine.Project) ([]redmine.Issue, []models.BlockedIssue, error) {
 	retIssues := make([]redmine.Issue, 0)
+	blocked := make([]models.BlockedIssue, 0)
 	for _, project := range projects {
 		//log.Printf("Project %q\n", project.Identifier)
 		activeProjectIssues, err := c.APIGetProjectIssues(project)
 		if err != nil {
-			return nil, fmt.Errorf("error redmine issues of project: %v", err)
+			return nil, nil, fmt.Errorf("error redmine issues of project: %v", err)
 		}
 
 		//for _, issue := range activeProjectIssues {
@@ -127,7 +136,7 @@ func (c *Model) APIGetWorkableIssues(workflow settings.Workflow, projects []redm
 		// dependencies can contain closed issue ids
 		dependencies, err := c.issueDependencies(activeProjectIssues)
 		if err != nil {
-			return nil, fmt.Errorf("error redmine issue dependencies: %v", err)
+			return nil, nil, fmt.Errorf("error redmine issue dependencies: %v", err)
 		}
 
 		cleanedDependencies := c.removeClosedDependencies(dependencies, activeProjectIssues)
@@ -160,6 +169,12 @@ func (c *Model) APIGetWorkableIssues(workflow settings.Workflow, projects []redm
 		for issueID, depIDs := range cleanedDependencies {
 			if len(depIDs) == 0 {
 				unblockedIDs = append(unblockedIDs, issueID)
+				continue
+			}
+			for _, issue := range activeProjectIssues {
+				if issue.Id == issueID {
+					blocked = append(blocked, models.BlockedIssue{Issue: issue, BlockedBy: depIDs})
+				}
 			}
 		}
 
@@ -197,7 +212,11 @@ func (c *Model) APIGetWorkableIssues(workflow settings.Workflow, projects []redm
 		}
 	}
 
-	return retIssues, nil
+	sort.Slice(blocked, func(i, j int) bool {
+		return blocked[i].Issue.Id < blocked[j].Issue.Id
+	})
+
+	return retIssues, blocked, nil
 }
 
 func (c *Model) removeClosedDependencies(dependencies map[int][]int, issues []redmine.Issue) map[int][]int {
@@ -286,8 +305,29 @@ func (c *Model) getCorrectIssue(issues []redmine.Issue, priorities settings.Prio
 	return valid
 }
 
+// AICommentMarker is last line of every comment andai writes. It tells andai comments apart from human ones,
+// also when people and andai use same redmine user.
+const AICommentMarker = "_Written by andai_"
+
+// MarkAIComment appends AICommentMarker to comment text.
+func MarkAIComment(text string) string {
+	if strings.TrimSpace(text) == "" {
+		return text
+	}
+	return strings.TrimRight(text, "\n") + "\n\n" + AICommentMarker
+}
+
+// splitAIComment returns comment text without AICommentMarker and tells if comment had it.
+func splitAIComment(text string) (string, bool) {
+	trimmed := strings.TrimRight(text, " \r\n")
+	if !strings.HasSuffix(trimmed, AICommentMarker) {
+		return text, false
+	}
+	return strings.TrimRight(strings.TrimSuffix(trimmed, AICommentMarker), " \r\n"), true
+}
+
 func (c *Model) Comment(issue redmine.Issue, text string) error {
-	issue.Notes = text
+	issue.Notes = MarkAIComment(text)
 	err := c.API().UpdateIssue(issue)
 	if err != nil {
 		return fmt.Errorf("error redmine issue comment: %v", err)
@@ -305,6 +345,12 @@ func (c *Model) Transition(issue redmine.Issue, nextStatus redmine.IssueStatus)
 	return nil
 }
 
+// TransitionWithComment moves issue to next status and leaves a comment within the same journal entry.
+func (c *Model) TransitionWithComment(issue redmine.Issue, nextStatus redmine.IssueStatus, text string) error {
+	issue.Notes = MarkAIComment(text)
+	return c.Transition(issue, nextStatus)
+}
+
 func (c *Model) CreateIssue(issue redmine.Issue) (redmine.Issue, error) {
 	created, err := c.API().CreateIssue(issue)
 	if err != nil {
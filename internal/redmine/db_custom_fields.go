@@ -18,6 +18,7 @@ const (
 	CustomFieldSkipMerge = "Skip merge"
 	CustomFieldParentSha = "Parent SHA"
 	CustomFieldLastSha   = "Last SHA"
+	CustomFieldAttempts  = "Attempts"
 )
 
 func (c *Model) DBSaveCustomFields(customFields []models.CustomField, current []redmine.CustomField) ([]int64, error) {
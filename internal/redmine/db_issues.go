@@ -10,18 +10,19 @@ import (
 )
 
 const (
-	queryGetClosedChildrenIDs   = "SELECT A.id FROM issues A INNER JOIN issue_statuses B ON A.status_id = B.id AND B.is_closed = 1 WHERE A.parent_id = ?" // nolint:gosec
-	queryGetChildren            = "SELECT A.id, A.subject, A.project_id, A.parent_id FROM issues A WHERE A.parent_id = ? ORDER BY A.id DESC"              // nolint:gosec
-	queryInsertCustomFieldValue = "INSERT INTO custom_values (customized_type, customized_id, custom_field_id, value) VALUES ('Issue', ?, ?, ?)"          // nolint:gosec
-	queryUpdateCustomFieldValue = "UPDATE custom_values SET value = ? WHERE id = ?"                                                                       // nolint:gosec
-	querySelectCustomFieldValue = "SELECT id FROM custom_values WHERE customized_type = 'Issue' AND customized_id = ? AND custom_field_id = ?"            // nolint:gosec
+	queryGetClosedChildrenIDs   = "SELECT A.id FROM issues A INNER JOIN issue_statuses B ON A.status_id = B.id AND B.is_closed = 1 WHERE A.parent_id = ?"               // nolint:gosec
+	queryGetIssueAge            = "SELECT TIMESTAMPDIFF(SECOND, created_on, UTC_TIMESTAMP()) FROM issues WHERE id = ?"                                                  // nolint:gosec
+	queryGetChildren            = "SELECT A.id, A.subject, A.project_id, A.parent_id, A.status_id, A.tracker_id FROM issues A WHERE A.parent_id = ? ORDER BY A.id DESC" // nolint:gosec
+	queryInsertCustomFieldValue = "INSERT INTO custom_values (customized_type, customized_id, custom_field_id, value) VALUES ('Issue', ?, ?, ?)"                        // nolint:gosec
+	queryUpdateCustomFieldValue = "UPDATE custom_values SET value = ? WHERE id = ?"                                                                                     // nolint:gosec
+	querySelectCustomFieldValue = "SELECT id FROM custom_values WHERE customized_type = 'Issue' AND customized_id = ? AND custom_field_id = ?"                          // nolint:gosec
 )
 
 func (c *Model) DBGetChildren(parent redmine.Issue) ([]redmine.Issue, error) {
 	var children []redmine.Issue
 	err := c.queryAndScan(queryGetChildren, func(rows *sql.Rows) error {
 		var child redmine.Issue
-		if err := rows.Scan(&child.Id, &child.Subject, &child.ProjectId, &child.ParentId); err != nil {
+		if err := rows.Scan(&child.Id, &child.Subject, &child.ProjectId, &child.ParentId, &child.StatusId, &child.TrackerId); err != nil {
 			return err
 		}
 		child.Project = &redmine.IdName{
@@ -30,6 +31,12 @@ func (c *Model) DBGetChildren(parent redmine.Issue) ([]redmine.Issue, error) {
 		child.Parent = &redmine.Id{
 			Id: child.ParentId,
 		}
+		child.Status = &redmine.IdName{
+			Id: child.StatusId,
+		}
+		child.Tracker = &redmine.IdName{
+			Id: child.TrackerId,
+		}
 
 		children = append(children, child)
 		return nil
@@ -61,6 +68,20 @@ func (c *Model) DBGetClosedChildrenIDs(parentID int) ([]int, error) {
 	return ids, nil
 }
 
+// DBGetIssueAge returns how long ago (in seconds) issue was created.
+func (c *Model) DBGetIssueAge(issueID int) (int, error) {
+	var seconds int
+	err := c.queryAndScan(queryGetIssueAge, func(rows *sql.Rows) error {
+		return rows.Scan(&seconds)
+	}, issueID)
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+
+	return seconds, nil
+}
+
 func (c *Model) DBInsertCustomFieldValue(issueID, customFieldID int, value string) error {
 	_, err := c.execDML(queryInsertCustomFieldValue, issueID, customFieldID, value)
 	if err != nil {
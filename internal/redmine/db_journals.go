@@ -3,31 +3,47 @@ package redmine
 import (
 	"database/sql"
 	"errors"
+	"log"
 
 	"github.com/andrejsstepanovs/andai/internal/redmine/models"
 	_ "github.com/go-sql-driver/mysql" // mysql driver
+	"github.com/spf13/viper"
 )
 
 const (
-	queryGetJournalComments  = "SELECT notes, user_id, created_on FROM journals WHERE journalized_type = ? AND notes != ? AND journalized_id = ? ORDER BY created_on ASC"                          // nolint:gosec
-	queryGetLastStatusChange = "SELECT B.journalized_id, A.old_value, A.value FROM journal_details A INNER JOIN journals B ON A.id = B.id WHERE A.prop_key = ? ORDER BY B.updated_on DESC LIMIT 1" // nolint:gosec
+	queryGetJournalComments    = "SELECT j.notes, j.user_id, COALESCE(NULLIF(TRIM(CONCAT(u.firstname, ' ', u.lastname)), ''), u.login, ''), j.created_on FROM journals j LEFT JOIN users u ON u.id = j.user_id WHERE j.journalized_type = ? AND j.notes != ? AND j.journalized_id = ? ORDER BY j.created_on ASC"                                      // nolint:gosec
+	queryGetAPIUserID          = "SELECT user_id FROM tokens WHERE action = ? AND value = ?"                                                                                                                                                                                                                                                          // nolint:gosec
+	queryGetStatusChanges      = "SELECT B.id, B.journalized_id, A.old_value, A.value, COALESCE(B.notes, ''), TIMESTAMPDIFF(SECOND, B.created_on, UTC_TIMESTAMP()) FROM journal_details A INNER JOIN journals B ON A.journal_id = B.id WHERE A.property = ? AND A.prop_key = ? AND B.journalized_type = ? AND B.id > ? ORDER BY B.id ASC"             // nolint:gosec
+	queryGetIssueStatusChanges = "SELECT B.id, B.journalized_id, A.old_value, A.value, COALESCE(B.notes, ''), TIMESTAMPDIFF(SECOND, B.created_on, UTC_TIMESTAMP()) FROM journal_details A INNER JOIN journals B ON A.journal_id = B.id WHERE A.property = ? AND A.prop_key = ? AND B.journalized_type = ? AND B.journalized_id = ? ORDER BY B.id ASC" // nolint:gosec
+	queryGetLastJournalID      = "SELECT COALESCE(MAX(id), 0) FROM journals"                                                                                                                                                                                                                                                                          // nolint:gosec
+	queryGetChangeMarker       = "SELECT (SELECT COALESCE(MAX(id), 0) FROM journals WHERE user_id != ?), (SELECT COALESCE(MAX(id), 0) FROM issues WHERE author_id != ?)"                                                                                                                                                                              // nolint:gosec
+	queryCountOtherUsers       = "SELECT COUNT(*) FROM users WHERE type = ? AND status = ? AND id != ?"                                                                                                                                                                                                                                               // nolint:gosec
 )
 
+// userStatusActive is redmine users.status value of active user
+const userStatusActive = 1
+
 // JournalIssueType is a constant for the journalized type
 const JournalIssueType = "Issue"
 
 // JournalStatusID is a constant for the status_id
 const JournalStatusID = "status_id"
 
+// JournalPropertyAttr is a constant for journal details that are issue attribute changes
+const JournalPropertyAttr = "attr"
+
+// DBGetComments returns issue comments, oldest first. Comments ending with AICommentMarker are marked as AI,
+// marker is removed from text.
 func (c *Model) DBGetComments(issueID int) (models.Comments, error) {
 	var notes []models.Comment
 	var i = 1
 	err := c.queryAndScan(queryGetJournalComments, func(rows *sql.Rows) error {
 		var row models.Comment
 		row.Number = i
-		if err := rows.Scan(&row.Text, &row.UserID, &row.CreatedAt); err != nil {
+		if err := rows.Scan(&row.Text, &row.UserID, &row.Author, &row.CreatedAt); err != nil {
 			return err
 		}
+		row.Text, row.AI = splitAIComment(row.Text)
 		i++
 		notes = append(notes, row)
 		return nil
@@ -41,17 +57,108 @@ func (c *Model) DBGetComments(issueID int) (models.Comments, error) {
 	return comments, nil
 }
 
-func (c *Model) DBGetLastStatusChange() (issueID int, statusIDFrom int, statusIDTo int, err error) {
-	err = c.queryAndScan(queryGetLastStatusChange, func(rows *sql.Rows) error {
-		if err := rows.Scan(&issueID, &statusIDFrom, &statusIDTo); err != nil {
+// DBGetAPIUserID returns id of user that owns configured api key (the one andai acts as). 0 if not found.
+// Looked up once.
+func (c *Model) DBGetAPIUserID() int {
+	c.apiUserOnce.Do(func() {
+		err := c.queryAndScan(queryGetAPIUserID, func(rows *sql.Rows) error {
+			return rows.Scan(&c.apiUserID)
+		}, TokenActionAPI, viper.GetString("redmine.api_key"))
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Failed to find api key user: %v", err)
+		}
+	})
+	return c.apiUserID
+}
+
+// DBGetStatusChangesSince returns all issue status changes with journal id greater than given one. Oldest first.
+func (c *Model) DBGetStatusChangesSince(journalID int) (models.StatusChanges, error) {
+	var changes models.StatusChanges
+	err := c.queryAndScan(queryGetStatusChanges, func(rows *sql.Rows) error {
+		var row models.StatusChange
+		if err := rows.Scan(&row.JournalID, &row.IssueID, &row.StatusIDFrom, &row.StatusIDTo, &row.Notes, &row.SecondsAgo); err != nil {
 			return err
 		}
+		changes = append(changes, row)
 		return nil
-	}, JournalStatusID)
+	}, JournalPropertyAttr, JournalStatusID, JournalIssueType, journalID)
 
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		return
+		return nil, err
 	}
 
-	return
+	return changes, nil
+}
+
+// DBGetIssueStatusChanges returns full status history of the issue. Oldest first.
+func (c *Model) DBGetIssueStatusChanges(issueID int) (models.StatusChanges, error) {
+	var changes models.StatusChanges
+	err := c.queryAndScan(queryGetIssueStatusChanges, func(rows *sql.Rows) error {
+		var row models.StatusChange
+		if err := rows.Scan(&row.JournalID, &row.IssueID, &row.StatusIDFrom, &row.StatusIDTo, &row.Notes, &row.SecondsAgo); err != nil {
+			return err
+		}
+		changes = append(changes, row)
+		return nil
+	}, JournalPropertyAttr, JournalStatusID, JournalIssueType, issueID)
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+func (c *Model) DBGetLastJournalID() (int, error) {
+	var journalID int
+	err := c.queryAndScan(queryGetLastJournalID, func(rows *sql.Rows) error {
+		return rows.Scan(&journalID)
+	})
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+
+	return journalID, nil
+}
+
+// DBGetChangeMarker returns last journal and issue ids. Used to detect redmine changes without expensive API calls.
+// Changes made by api key user (andai itself) are ignored, otherwise every own comment would wake the loop again.
+// If api key user is the only user (people work as same user), every change counts.
+func (c *Model) DBGetChangeMarker() (models.ChangeMarker, error) {
+	ignoredUserID := c.changeMarkerIgnoredUserID()
+	var marker models.ChangeMarker
+	err := c.queryAndScan(queryGetChangeMarker, func(rows *sql.Rows) error {
+		return rows.Scan(&marker.LastJournalID, &marker.LastIssueID)
+	}, ignoredUserID, ignoredUserID)
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return models.ChangeMarker{}, err
+	}
+
+	return marker, nil
+}
+
+// changeMarkerIgnoredUserID returns user whose changes are not counted as redmine changes. 0 if none.
+// Looked up once.
+func (c *Model) changeMarkerIgnoredUserID() int {
+	c.changeUserOnce.Do(func() {
+		apiUserID := c.DBGetAPIUserID()
+		if apiUserID == 0 {
+			return
+		}
+		others := 0
+		err := c.queryAndScan(queryCountOtherUsers, func(rows *sql.Rows) error {
+			return rows.Scan(&others)
+		}, "User", userStatusActive, apiUserID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Failed to count redmine users: %v", err)
+		}
+		if others == 0 {
+			log.Println("WARNING: api key user is the only redmine user, own changes will also wake the loop. Give people their own redmine users to avoid it.")
+			return
+		}
+		c.changeUserID = apiUserID
+	})
+	return c.changeUserID
 }
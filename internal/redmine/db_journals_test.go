@@ -0,0 +1,36 @@
+package redmine_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	model "github.com/andrejsstepanovs/andai/internal/redmine"
+	"github.com/andrejsstepanovs/andai/internal/redmine/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModel_DBGetComments(t *testing.T) {
+	conn, db, err := sqlmock.New()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// people and andai share same redmine user, only marker tells them apart
+	db.ExpectQuery(regexp.QuoteMeta("SELECT j.notes, j.user_id")).WithArgs(model.JournalIssueType, "", 5).
+		WillReturnRows(sqlmock.NewRows([]string{"notes", "user_id", "author", "created_on"}).
+			AddRow("Please add tests", 1, "Redmine Admin", "2026-01-01").
+			AddRow(model.MarkAIComment("Command: **aider**\n"), 1, "Redmine Admin", "2026-01-02").
+			AddRow("Mention _Written by andai_ in the middle is fine", 1, "Redmine Admin", "2026-01-03"))
+
+	comments, err := model.NewModel(conn, mocks.NewAPIInterface(t)).DBGetComments(5)
+	require.NoError(t, err)
+	require.Len(t, comments, 3)
+	assert.False(t, comments[0].AI)
+	assert.True(t, comments[1].AI)
+	assert.Equal(t, "Command: **aider**", comments[1].Text)
+	assert.Equal(t, 2, comments[1].Number)
+	assert.False(t, comments[2].AI)
+	assert.Len(t, comments.Human(), 2)
+	assert.NoError(t, db.ExpectationsWereMet())
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strconv"
 
 	"github.com/andrejsstepanovs/andai/internal/redmine/models"
 	_ "github.com/go-sql-driver/mysql" // mysql driver
@@ -23,6 +24,8 @@ const (
 	SettingSysAPIEnabled = "sys_api_enabled"
 	// SettingSysAdminKey defines system api key value setting
 	SettingSysAdminKey = "sys_api_key"
+	// SettingTriggersCheckpoint stores last journal id that was processed by workflow triggers
+	SettingTriggersCheckpoint = "andai_triggers_journal_id"
 
 	settingsValueEnabled = "1"
 	autoIncrementDefault = 100
@@ -307,3 +310,46 @@ func (c *Model) DBSettingsAdminMustNotChangePassword() error {
 
 	return nil
 }
+
+// DBGetTriggersCheckpoint returns last journal id that triggers have processed.
+// found is false if checkpoint was never saved, 0 is a valid checkpoint of empty redmine.
+func (c *Model) DBGetTriggersCheckpoint() (journalID int, found bool, err error) {
+	rows, err := c.DBGetSettings(SettingTriggersCheckpoint)
+	if err != nil {
+		return 0, false, fmt.Errorf("get settings db err: %v", err)
+	}
+	if len(rows) == 0 {
+		return 0, false, nil
+	}
+	journalID, err = strconv.Atoi(rows[0].Value)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid %s value %q err: %v", SettingTriggersCheckpoint, rows[0].Value, err)
+	}
+	return journalID, true, nil
+}
+
+// DBSaveTriggersCheckpoint persists last journal id that triggers have processed.
+func (c *Model) DBSaveTriggersCheckpoint(journalID int) error {
+	return c.dbSaveSetting(SettingTriggersCheckpoint, strconv.Itoa(journalID))
+}
+
+func (c *Model) dbSaveSetting(settingName, value string) error {
+	rows, err := c.DBGetSettings(settingName)
+	if err != nil {
+		return fmt.Errorf("get settings db err: %v", err)
+	}
+
+	if len(rows) > 0 {
+		err = c.DBSettingsUpdate(settingName, value)
+		if err != nil {
+			return fmt.Errorf("update settings db err: %v", err)
+		}
+		return nil
+	}
+
+	err = c.DBSettingsInsert(settingName, value)
+	if err != nil {
+		return fmt.Errorf("insert settings db err: %v", err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package redmine
 
 import (
 	"database/sql"
+	"sync"
 
 	_ "github.com/go-sql-driver/mysql" // mysql driver
 	"github.com/mattn/go-redmine"
@@ -35,6 +36,12 @@ type APIInterface interface {
 type Model struct {
 	db  DatabaseInterface
 	api APIInterface
+
+	apiUserOnce sync.Once
+	apiUserID   int
+
+	changeUserOnce sync.Once
+	changeUserID   int
 }
 
 func NewModel(db DatabaseInterface, api APIInterface) *Model {
@@ -0,0 +1,12 @@
+package models
+
+type Attachment struct {
+	ID          int    `json:"id"`
+	FileName    string `json:"filename"`
+	FileSize    int64  `json:"filesize"`
+	ContentType string `json:"content_type"`
+	Description string `json:"description"`
+	ContentURL  string `json:"content_url"`
+}
+
+type Attachments []Attachment
@@ -0,0 +1,9 @@
+package models
+
+import "github.com/mattn/go-redmine"
+
+// BlockedIssue is an open issue that waits for other open issues to be done first.
+type BlockedIssue struct {
+	Issue     redmine.Issue
+	BlockedBy []int
+}
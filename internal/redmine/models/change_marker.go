@@ -0,0 +1,7 @@
+package models
+
+// ChangeMarker is a cheap fingerprint of redmine data. If it changes, something happened (new issue, comment, status change).
+type ChangeMarker struct {
+	LastJournalID int
+	LastIssueID   int
+}
@@ -3,8 +3,30 @@ package models
 type Comment struct {
 	Number    int
 	UserID    int
+	Author    string // user full name or login
+	AI        bool   // written by andai (ends with marker)
 	Text      string
 	CreatedAt string
 }
 
 type Comments []Comment
+
+// Human returns comments written by people.
+func (c Comments) Human() Comments {
+	return c.filter(false)
+}
+
+// AI returns comments written by andai.
+func (c Comments) AI() Comments {
+	return c.filter(true)
+}
+
+func (c Comments) filter(ai bool) Comments {
+	filtered := make(Comments, 0, len(c))
+	for _, comment := range c {
+		if comment.AI == ai {
+			filtered = append(filtered, comment)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,12 @@
+package models
+
+type StatusChange struct {
+	JournalID    int
+	IssueID      int
+	StatusIDFrom int
+	StatusIDTo   int
+	Notes        string
+	SecondsAgo   int // how long ago status was changed
+}
+
+type StatusChanges []StatusChange
@@ -0,0 +1,37 @@
+package repofiles
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// CacheDir returns default directory for cached indexes of given kind.
+func CacheDir(kind string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "andai", kind)
+}
+
+// Load reads cached index of project into v. Error if there is no (valid) cache.
+func Load(dir, project string, v any) error {
+	data, err := os.ReadFile(filepath.Join(dir, project+".json"))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Save stores index of project. Only latest index of project is kept.
+func Save(dir, project string, v any) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, project+".json"), data, 0o600)
+}
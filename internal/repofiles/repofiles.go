@@ -0,0 +1,50 @@
+// Package repofiles decides which repository files are worth indexing, reads them and caches indexes built from them.
+// Shared by repository map (codeindex) and code search (codesearch).
+package repofiles
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// MaxFileSize skips generated and data files.
+const MaxFileSize = 512 * 1024
+
+// skipDirs are never indexed.
+var skipDirs = []string{"vendor", "node_modules", "testdata", "dist", "build", "third_party"}
+
+// Indexable tells if file (slash separated, relative to repository root) is not in skipped or hidden directory
+// and is not minified.
+func Indexable(file string) bool {
+	for _, part := range strings.Split(path.Dir(file), "/") {
+		if slices.Contains(skipDirs, part) || (strings.HasPrefix(part, ".") && part != ".") {
+			return false
+		}
+	}
+	return !strings.Contains(path.Base(file), ".min.")
+}
+
+// Read returns content of file (relative to root). Big, binary and not regular files are skipped with error.
+func Read(root, file string) ([]byte, error) {
+	full := filepath.Join(root, filepath.FromSlash(file))
+	info, err := os.Stat(full)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() || info.Size() > MaxFileSize {
+		return nil, fmt.Errorf("file %q is skipped", file)
+	}
+	content, err := os.ReadFile(full) // nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+	if bytes.IndexByte(content, 0) >= 0 {
+		return nil, fmt.Errorf("file %q is binary", file)
+	}
+	return content, nil
+}
@@ -0,0 +1,74 @@
+package repofiles_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/repofiles"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexable(t *testing.T) {
+	tests := []struct {
+		file     string
+		expected bool
+	}{
+		{file: "main.go", expected: true},
+		{file: "internal/billing/invoice.go", expected: true},
+		{file: ".github/workflows/ci.yml", expected: false},
+		{file: "vendor/lib/lib.go", expected: false},
+		{file: "web/node_modules/x/index.js", expected: false},
+		{file: "internal/parser/testdata/input.go", expected: false},
+		{file: "web/app.min.js", expected: false},
+		{file: ".env", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			assert.Equal(t, tt.expected, repofiles.Indexable(tt.file))
+		})
+	}
+}
+
+func TestRead(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, "pkg"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "pkg", "a.go"), []byte("package pkg\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "image.png"), []byte{0x89, 'P', 'N', 'G', 0, 0}, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "big.txt"), []byte(strings.Repeat("x", repofiles.MaxFileSize+1)), 0o600))
+
+	content, err := repofiles.Read(root, "pkg/a.go")
+	require.NoError(t, err)
+	assert.Equal(t, "package pkg\n", string(content))
+
+	for _, file := range []string{"image.png", "big.txt", "pkg", "missing.go"} {
+		_, err = repofiles.Read(root, file)
+		assert.Error(t, err, file)
+	}
+}
+
+func TestCache(t *testing.T) {
+	type index struct {
+		SHA string `json:"sha"`
+	}
+	dir := filepath.Join(t.TempDir(), "cache")
+
+	var loaded index
+	assert.Error(t, repofiles.Load(dir, "shop", &loaded))
+
+	require.NoError(t, repofiles.Save(dir, "shop", index{SHA: "abc"}))
+	require.NoError(t, repofiles.Save(dir, "shop", index{SHA: "def"}))
+	require.NoError(t, repofiles.Save(dir, "blog", index{SHA: "123"}))
+
+	require.NoError(t, repofiles.Load(dir, "shop", &loaded))
+	assert.Equal(t, index{SHA: "def"}, loaded)
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 2, "one file per project")
+
+	assert.True(t, strings.HasSuffix(repofiles.CacheDir("repo-map"), filepath.Join("andai", "repo-map")))
+}
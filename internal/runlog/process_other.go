@@ -0,0 +1,8 @@
+//go:build !unix
+
+package runlog
+
+// processAlive can not be checked without signals, marker is trusted as is.
+func processAlive(pid int) bool {
+	return pid > 0
+}
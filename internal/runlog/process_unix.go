@@ -0,0 +1,17 @@
+//go:build unix
+
+package runlog
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processAlive tells if process with given PID exists. Signal 0 only checks, EPERM means it belongs to other user.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
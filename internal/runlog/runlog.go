@@ -0,0 +1,219 @@
+// Package runlog writes output of workflow steps into per issue, per run log files.
+package runlog
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// activeFile marks (and points to) run log that is being written right now.
+	// It holds log file name and PID of writing process, so marker left by crashed run is ignored.
+	activeFile = "active"
+	logExt     = ".log"
+	timeFormat = "20060102-150405"
+)
+
+// ErrNoLogs is returned when issue has no run logs.
+var ErrNoLogs = errors.New("no logs found")
+
+// Run is a single work session on issue. Safe for concurrent use.
+type Run struct {
+	mu     sync.Mutex
+	dir    string
+	path   string
+	file   *os.File
+	prefix string
+}
+
+// IssueDir returns directory where logs of given issue are stored.
+func IssueDir(baseDir string, issueID int) string {
+	return filepath.Join(baseDir, fmt.Sprintf("issue-%d", issueID))
+}
+
+// Start creates new log file for issue and marks it as active.
+func Start(baseDir string, issueID int) (*Run, error) {
+	dir := IssueDir(baseDir, issueID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log dir %q err: %w", dir, err)
+	}
+
+	name := time.Now().Format(timeFormat) + logExt
+	path := filepath.Join(dir, name)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to create log file %q err: %w", path, err)
+	}
+
+	marker := fmt.Sprintf("%s\n%d\n", name, os.Getpid())
+	err = os.WriteFile(filepath.Join(dir, activeFile), []byte(marker), 0o644) // nolint:gosec
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("failed to mark log file as active err: %w", err)
+	}
+
+	return &Run{dir: dir, path: path, file: file}, nil
+}
+
+// Path returns log file path.
+func (r *Run) Path() string {
+	return r.path
+}
+
+// Step sets prefix that is printed in front of every following line and writes step header.
+func (r *Run) Step(prefix string) {
+	r.mu.Lock()
+	r.prefix = prefix
+	r.mu.Unlock()
+	r.write(fmt.Sprintf("==> %s", prefix))
+}
+
+// Line writes command output line to log file and console. Matches exec.StreamFunc.
+func (r *Run) Line(source, line string) {
+	r.mu.Lock()
+	prefix := r.prefix
+	r.mu.Unlock()
+
+	if source != "" && source != "stdout" {
+		line = fmt.Sprintf("(%s) %s", source, line)
+	}
+	log.Printf("[%s] %s", prefix, line)
+	r.write(line)
+}
+
+func (r *Run) write(line string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return
+	}
+	_, err := fmt.Fprintf(r.file, "%s %s\n", time.Now().Format(time.TimeOnly), line)
+	if err != nil {
+		log.Printf("Failed to write run log %q err: %v", r.path, err)
+	}
+}
+
+// Close closes log file and removes active mark.
+func (r *Run) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+
+	removeErr := os.Remove(filepath.Join(r.dir, activeFile))
+	if removeErr != nil && !os.IsNotExist(removeErr) && err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// Tail returns log file content. Content longer than maxBytes is cut from the beginning, so the end of the run is kept.
+func Tail(path string, maxBytes int64) ([]byte, error) {
+	file, err := os.Open(path) // nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() <= maxBytes {
+		return io.ReadAll(file)
+	}
+
+	note := fmt.Sprintf("... first %d bytes cut, full log: %s\n", info.Size()-maxBytes, path)
+	if _, err = file.Seek(-maxBytes, io.SeekEnd); err != nil {
+		return nil, err
+	}
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(note), content...), nil
+}
+
+// Latest returns active log file of the issue, or newest one if nothing is running.
+func Latest(baseDir string, issueID int) (path string, active bool, err error) {
+	dir := IssueDir(baseDir, issueID)
+
+	if name, ok := activeName(dir); ok {
+		return filepath.Join(dir, name), true, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"+logExt))
+	if err != nil {
+		return "", false, err
+	}
+	if len(files) == 0 {
+		return "", false, ErrNoLogs
+	}
+	sort.Strings(files) // names are timestamps
+	return files[len(files)-1], false, nil
+}
+
+// IsActive tells if given log file is still being written.
+func IsActive(path string) bool {
+	name, ok := activeName(filepath.Dir(path))
+	return ok && name == filepath.Base(path)
+}
+
+// activeName returns name of active log file in issue dir. Marker of process that is gone is stale and ignored.
+func activeName(dir string) (string, bool) {
+	content, err := os.ReadFile(filepath.Join(dir, activeFile))
+	if err != nil {
+		return "", false
+	}
+	name, pidLine, _ := strings.Cut(strings.TrimSpace(string(content)), "\n")
+	pid, err := strconv.Atoi(strings.TrimSpace(pidLine))
+	if name == "" || err != nil || !processAlive(pid) {
+		return "", false
+	}
+	return name, true
+}
+
+// Follow copies log file into w. If follow is true keeps copying new lines while log is active (like tail -f).
+func Follow(ctx context.Context, path string, w io.Writer, follow bool, poll time.Duration) error {
+	file, err := os.Open(path) // nolint:gosec
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for {
+		_, err = io.Copy(w, reader)
+		if err != nil {
+			return err
+		}
+		if !follow {
+			return nil
+		}
+		if !IsActive(path) {
+			// copy whatever was written right before run finished
+			_, err = io.Copy(w, reader)
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(poll):
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package runlog_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andrejsstepanovs/andai/internal/runlog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun_WriteAndLatest(t *testing.T) {
+	dir := t.TempDir()
+
+	_, _, err := runlog.Latest(dir, 5)
+	assert.ErrorIs(t, err, runlog.ErrNoLogs)
+
+	run, err := runlog.Start(dir, 5)
+	require.NoError(t, err)
+	assert.Equal(t, runlog.IssueDir(dir, 5), filepath.Dir(run.Path()))
+
+	run.Step("#5 1/1 bash echo")
+	run.Line("stdout", "hello")
+	run.Line("stderr", "oops")
+
+	path, active, err := runlog.Latest(dir, 5)
+	require.NoError(t, err)
+	assert.True(t, active)
+	assert.Equal(t, run.Path(), path)
+	assert.True(t, runlog.IsActive(path))
+
+	require.NoError(t, run.Close())
+	require.NoError(t, run.Close(), "closing twice is fine")
+
+	path, active, err = runlog.Latest(dir, 5)
+	require.NoError(t, err)
+	assert.False(t, active)
+	assert.Equal(t, run.Path(), path)
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), "==> #5 1/1 bash echo")
+	assert.Contains(t, string(contents), " hello\n")
+	assert.Contains(t, string(contents), "(stderr) oops")
+}
+
+func TestFollow(t *testing.T) {
+	dir := t.TempDir()
+	run, err := runlog.Start(dir, 1)
+	require.NoError(t, err)
+	run.Line("stdout", "first")
+
+	var out bytes.Buffer
+	done := make(chan error)
+	go func() {
+		done <- runlog.Follow(context.Background(), run.Path(), &out, true, 10*time.Millisecond)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	run.Line("stdout", "second")
+	require.NoError(t, run.Close())
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("follow did not stop after run was closed")
+	}
+
+	assert.Contains(t, out.String(), "first")
+	assert.Contains(t, out.String(), "second")
+}
+
+func TestFollow_CrashedRun(t *testing.T) {
+	dir := t.TempDir()
+	run, err := runlog.Start(dir, 3)
+	require.NoError(t, err)
+	run.Line("stdout", "before crash")
+
+	// run that crashed never removed its marker, process that wrote it is gone
+	finished := osexec.Command("true")
+	require.NoError(t, finished.Run())
+	marker := fmt.Sprintf("%s\n%d\n", filepath.Base(run.Path()), finished.Process.Pid)
+	require.NoError(t, os.WriteFile(filepath.Join(runlog.IssueDir(dir, 3), "active"), []byte(marker), 0o600))
+
+	path, active, err := runlog.Latest(dir, 3)
+	require.NoError(t, err)
+	assert.False(t, active)
+	assert.Equal(t, run.Path(), path)
+
+	var out bytes.Buffer
+	done := make(chan error)
+	go func() {
+		done <- runlog.Follow(context.Background(), run.Path(), &out, true, 10*time.Millisecond)
+	}()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("follow did not stop for crashed run")
+	}
+	assert.Contains(t, out.String(), "before crash")
+}
+
+func TestFollow_NoFollow(t *testing.T) {
+	dir := t.TempDir()
+	run, err := runlog.Start(dir, 2)
+	require.NoError(t, err)
+	defer run.Close()
+	run.Line("stdout", "only")
+
+	var out bytes.Buffer
+	err = runlog.Follow(context.Background(), run.Path(), &out, false, time.Millisecond)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "only")
+}
+
+func TestTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.log")
+	require.NoError(t, os.WriteFile(path, []byte("first line\nlast line\n"), 0o600))
+
+	content, err := runlog.Tail(path, 100)
+	require.NoError(t, err)
+	assert.Equal(t, "first line\nlast line\n", string(content))
+
+	content, err = runlog.Tail(path, 10)
+	require.NoError(t, err)
+	assert.Equal(t, "... first 11 bytes cut, full log: "+path+"\nlast line\n", string(content))
+
+	_, err = runlog.Tail(filepath.Join(t.TempDir(), "missing.log"), 10)
+	assert.Error(t, err)
+}
@@ -0,0 +1,296 @@
+// Package sandbox wraps commands so AI edited code runs isolated from host (container or bubblewrap).
+package sandbox
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andrejsstepanovs/andai/internal/settings"
+)
+
+// Command is program that should run in sandbox.
+type Command struct {
+	Name     string
+	Args     []string
+	Root     string   // repository root (absolute). Mounted read-write.
+	Dir      string   // working directory (absolute or relative to Root)
+	Env      []string // KEY=value pairs on top of host environment (or allowlisted env in sandbox)
+	ClearEnv bool     // Env is complete environment, host environment is not inherited
+	Mounts   []string // extra host files or dirs command needs (mounted read-only)
+	Limits   Limits   // runner applies limits it can and leaves the rest in returned command
+	Stop     *Command // stops sandbox if command is killed before it finished (e.g. container outlives killed client)
+}
+
+// Limits are resource limits for command. Zero means no limit.
+type Limits struct {
+	CPUSeconds    uint64
+	MemoryBytes   uint64
+	FileSizeBytes uint64
+	Processes     uint64  // applied only in container
+	CPUs          float64 // applied only in container
+}
+
+// IsZero tells if no limits are set.
+func (l Limits) IsZero() bool {
+	return l == Limits{}
+}
+
+// WorkDir returns absolute working directory.
+func (c Command) WorkDir() string {
+	if c.Dir == "" {
+		return c.Root
+	}
+	if filepath.IsAbs(c.Dir) {
+		return c.Dir
+	}
+	return filepath.Join(c.Root, c.Dir)
+}
+
+// Runner turns command into command that runs it inside sandbox.
+type Runner interface {
+	Wrap(cmd Command) (Command, error)
+}
+
+// None runs commands as they are.
+type None struct{}
+
+func (None) Wrap(cmd Command) (Command, error) {
+	return cmd, nil
+}
+
+// New builds runner from settings.
+func New(cfg settings.Sandbox) Runner {
+	switch cfg.GetRunner() {
+	case settings.SandboxDocker, settings.SandboxPodman:
+		return Container{Binary: cfg.GetRunner(), Image: cfg.Image, Mounts: cfg.Mounts, Env: cfg.Env, Network: cfg.Network, Args: cfg.Args}
+	case settings.SandboxBwrap:
+		return Bwrap{Mounts: cfg.Mounts, Env: cfg.Env, Network: cfg.Network, Args: cfg.Args}
+	default:
+		return None{}
+	}
+}
+
+var (
+	mu     sync.RWMutex
+	config settings.Sandbox
+)
+
+// Setup configures runner used for sandboxed step commands.
+func Setup(cfg settings.Sandbox) {
+	mu.Lock()
+	defer mu.Unlock()
+	config = cfg
+}
+
+// For returns runner for given step command or nil if command is not sandboxed.
+func For(stepCommand string) Runner {
+	mu.RLock()
+	defer mu.RUnlock()
+	if config.GetRunner() == settings.SandboxNone || !slices.Contains(config.GetCommands(), stepCommand) {
+		return nil
+	}
+	cfg := config
+	cfg.Network = cfg.NetworkFor(stepCommand)
+	return New(cfg)
+}
+
+// BinaryMounts returns host dirs program installed outside system paths (e.g. aider in ~/.local or pipx venv) needs to run.
+// "bin" dir is replaced with its parent, so libraries next to it are included too. Empty if program is not found.
+func BinaryMounts(name string) []string {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return nil
+	}
+	paths := []string{path}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil && resolved != path {
+		paths = append(paths, resolved)
+	}
+
+	home, _ := os.UserHomeDir()
+	mounts := make([]string, 0, len(paths))
+	for _, p := range paths {
+		dir := filepath.Dir(p)
+		if filepath.Base(dir) == "bin" && filepath.Dir(dir) != home && filepath.Dir(dir) != "/" {
+			dir = filepath.Dir(dir)
+		}
+		if isSystemPath(dir) || slices.Contains(mounts, dir) {
+			continue
+		}
+		mounts = append(mounts, dir)
+	}
+	return mounts
+}
+
+func isSystemPath(path string) bool {
+	for _, system := range systemPaths {
+		if path == system || strings.HasPrefix(path, system+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return path == "/"
+}
+
+// mountArg splits "path" or "path:rw" into path and read-only flag.
+func mountArg(mount string) (string, bool) {
+	if path, found := strings.CutSuffix(mount, ":rw"); found {
+		return path, false
+	}
+	return mount, true
+}
+
+func envName(pair string) string {
+	name, _, _ := strings.Cut(pair, "=")
+	return name
+}
+
+func requireRoot(cmd Command) error {
+	if cmd.Root == "" || !filepath.IsAbs(cmd.Root) {
+		return fmt.Errorf("sandbox needs absolute repository root, got %q", cmd.Root)
+	}
+	return nil
+}
+
+// Container runs command with `docker run` or `podman run`. Repository is mounted at the same path.
+type Container struct {
+	Binary  string // docker or podman
+	Image   string
+	Mounts  []string
+	Env     []string
+	Network bool
+	Args    []string
+}
+
+func (c Container) Wrap(cmd Command) (Command, error) {
+	if err := requireRoot(cmd); err != nil {
+		return Command{}, err
+	}
+
+	name, err := containerName()
+	if err != nil {
+		return Command{}, err
+	}
+
+	// --init reaps and forwards signals to command, named container can be killed if run client is killed on timeout
+	args := []string{"run", "--rm", "-i", "--init", "--name", name}
+	if !c.Network {
+		args = append(args, "--network", "none")
+	}
+	if c.Binary == settings.SandboxDocker {
+		// keep files written in mounted repository owned by current user. User has no home in image, tools need writable one.
+		args = append(args, "--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()), "-e", "HOME=/tmp")
+	}
+	args = append(args, "-v", fmt.Sprintf("%s:%s", cmd.Root, cmd.Root))
+	for _, mount := range append(slices.Clone(c.Mounts), cmd.Mounts...) {
+		path, readOnly := mountArg(mount)
+		volume := fmt.Sprintf("%s:%s", path, path)
+		if readOnly {
+			volume += ":ro"
+		}
+		args = append(args, "-v", volume)
+	}
+	args = append(args, "-w", cmd.WorkDir())
+	args = append(args, containerLimits(cmd.Limits)...)
+
+	// values are passed through runner process environment, so they do not show up in arguments and logs
+	for _, name := range c.Env {
+		args = append(args, "-e", name)
+	}
+	for _, pair := range cmd.Env {
+		args = append(args, "-e", envName(pair))
+	}
+
+	args = append(args, c.Args...)
+	args = append(args, c.Image, cmd.Name)
+	args = append(args, cmd.Args...)
+
+	stop := &Command{Name: c.Binary, Args: []string{"kill", name}, Root: cmd.Root, Dir: cmd.Root}
+	return Command{Name: c.Binary, Args: args, Root: cmd.Root, Dir: cmd.Root, Env: cmd.Env, Stop: stop}, nil
+}
+
+func containerName() (string, error) {
+	suffix := make([]byte, 6)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate container name err: %w", err)
+	}
+	return "andai-" + hex.EncodeToString(suffix), nil
+}
+
+// containerLimits turns limits into run arguments. Container enforces them, so process limits of runner client are not needed.
+func containerLimits(limits Limits) []string {
+	args := make([]string, 0)
+	if limits.MemoryBytes > 0 {
+		args = append(args, "--memory", strconv.FormatUint(limits.MemoryBytes, 10))
+	}
+	if limits.CPUs > 0 {
+		args = append(args, "--cpus", strconv.FormatFloat(limits.CPUs, 'f', -1, 64))
+	}
+	if limits.Processes > 0 {
+		args = append(args, "--pids-limit", strconv.FormatUint(limits.Processes, 10))
+	}
+	if limits.CPUSeconds > 0 {
+		args = append(args, "--ulimit", fmt.Sprintf("cpu=%d:%d", limits.CPUSeconds, limits.CPUSeconds))
+	}
+	if limits.FileSizeBytes > 0 {
+		args = append(args, "--ulimit", fmt.Sprintf("fsize=%d:%d", limits.FileSizeBytes, limits.FileSizeBytes))
+	}
+	return args
+}
+
+// systemPaths are mounted read-only into bubblewrap sandbox (if they exist) so programs can run.
+var systemPaths = []string{"/usr", "/bin", "/sbin", "/lib", "/lib64", "/etc", "/opt"}
+
+// Bwrap runs command with bubblewrap. Everything except repository, system paths and mounts is hidden.
+type Bwrap struct {
+	Mounts  []string
+	Env     []string
+	Network bool
+	Args    []string
+}
+
+func (b Bwrap) Wrap(cmd Command) (Command, error) {
+	if err := requireRoot(cmd); err != nil {
+		return Command{}, err
+	}
+
+	args := []string{"--die-with-parent", "--unshare-all"}
+	if b.Network {
+		args = append(args, "--share-net")
+	}
+	for _, path := range systemPaths {
+		args = append(args, "--ro-bind-try", path, path)
+	}
+	args = append(args, "--proc", "/proc", "--dev", "/dev", "--tmpfs", "/tmp")
+	args = append(args, "--bind", cmd.Root, cmd.Root)
+	for _, mount := range append(slices.Clone(b.Mounts), cmd.Mounts...) {
+		path, readOnly := mountArg(mount)
+		if readOnly {
+			args = append(args, "--ro-bind", path, path)
+		} else {
+			args = append(args, "--bind", path, path)
+		}
+	}
+	args = append(args, "--chdir", cmd.WorkDir())
+	args = append(args, b.Args...)
+	args = append(args, "--", cmd.Name)
+	args = append(args, cmd.Args...)
+
+	// only PATH, allowlisted and command env reach sandbox
+	env := []string{"PATH=" + os.Getenv("PATH")}
+	for _, name := range b.Env {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, fmt.Sprintf("%s=%s", name, value))
+		}
+	}
+	env = append(env, cmd.Env...)
+
+	// process limits are inherited by bwrap children, so they stay for caller to apply
+	return Command{Name: "bwrap", Args: args, Root: cmd.Root, Dir: cmd.Root, Env: env, ClearEnv: true, Limits: cmd.Limits}, nil
+}
@@ -0,0 +1,156 @@
+package sandbox_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/sandbox"
+	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNone(t *testing.T) {
+	cmd := sandbox.Command{Name: "make", Args: []string{"test"}, Dir: "api"}
+	wrapped, err := sandbox.None{}.Wrap(cmd)
+	require.NoError(t, err)
+	assert.Equal(t, cmd, wrapped)
+}
+
+func TestContainer(t *testing.T) {
+	runner := sandbox.New(settings.Sandbox{
+		Runner: "podman",
+		Image:  "golang:1.23",
+		Mounts: []string{"/home/me/go/pkg/mod", "/home/me/.cache/go-build:rw"},
+		Env:    []string{"GOPROXY"},
+		Args:   []string{"--memory", "2g"},
+	})
+
+	wrapped, err := runner.Wrap(sandbox.Command{
+		Name:   "go",
+		Args:   []string{"test", "./..."},
+		Root:   "/repo",
+		Dir:    "api",
+		Env:    []string{"CGO_ENABLED=0"},
+		Mounts: []string{"/tmp/prompt.txt"},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, wrapped.Stop)
+	name := wrapped.Stop.Args[1]
+	assert.Regexp(t, "^andai-[0-9a-f]{12}$", name)
+
+	assert.Equal(t, sandbox.Command{
+		Name: "podman",
+		Args: []string{
+			"run", "--rm", "-i", "--init", "--name", name, "--network", "none",
+			"-v", "/repo:/repo",
+			"-v", "/home/me/go/pkg/mod:/home/me/go/pkg/mod:ro",
+			"-v", "/home/me/.cache/go-build:/home/me/.cache/go-build",
+			"-v", "/tmp/prompt.txt:/tmp/prompt.txt:ro",
+			"-w", "/repo/api",
+			"-e", "GOPROXY",
+			"-e", "CGO_ENABLED",
+			"--memory", "2g",
+			"golang:1.23", "go", "test", "./...",
+		},
+		Root: "/repo",
+		Dir:  "/repo",
+		Env:  []string{"CGO_ENABLED=0"},
+		Stop: &sandbox.Command{Name: "podman", Args: []string{"kill", name}, Root: "/repo", Dir: "/repo"},
+	}, wrapped)
+}
+
+func TestContainer_DockerNetwork(t *testing.T) {
+	runner := sandbox.New(settings.Sandbox{Runner: "docker", Image: "alpine", Network: true})
+
+	wrapped, err := runner.Wrap(sandbox.Command{Name: "ls", Root: "/repo"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"run", "--rm", "-i", "--init", "--name", wrapped.Stop.Args[1],
+		"--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()), "-e", "HOME=/tmp",
+		"-v", "/repo:/repo",
+		"-w", "/repo",
+		"alpine", "ls",
+	}, wrapped.Args)
+}
+
+func TestBwrap(t *testing.T) {
+	t.Setenv("ANDAI_SANDBOX_TEST", "visible")
+	t.Setenv("ANDAI_SANDBOX_HIDDEN", "hidden")
+
+	runner := sandbox.New(settings.Sandbox{Runner: "bwrap", Env: []string{"ANDAI_SANDBOX_TEST", "ANDAI_NOT_SET"}})
+	wrapped, err := runner.Wrap(sandbox.Command{Name: "make", Args: []string{"test"}, Root: "/repo", Env: []string{"CI=1"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, "bwrap", wrapped.Name)
+	assert.True(t, wrapped.ClearEnv)
+	assert.Equal(t, []string{"PATH=" + os.Getenv("PATH"), "ANDAI_SANDBOX_TEST=visible", "CI=1"}, wrapped.Env)
+	assert.Contains(t, wrapped.Args, "--unshare-all")
+	assert.NotContains(t, wrapped.Args, "--share-net")
+	assert.Equal(t, []string{"--bind", "/repo", "/repo", "--chdir", "/repo", "--", "make", "test"}, wrapped.Args[len(wrapped.Args)-8:])
+}
+
+func TestWrap_Limits(t *testing.T) {
+	limits := sandbox.Limits{CPUSeconds: 60, MemoryBytes: 1 << 30, FileSizeBytes: 1 << 20, Processes: 100, CPUs: 1.5}
+	cmd := sandbox.Command{Name: "make", Root: "/repo", Limits: limits}
+
+	wrapped, err := sandbox.Container{Binary: "podman", Image: "alpine"}.Wrap(cmd)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"--memory", "1073741824",
+		"--cpus", "1.5",
+		"--pids-limit", "100",
+		"--ulimit", "cpu=60:60",
+		"--ulimit", "fsize=1048576:1048576",
+		"alpine", "make",
+	}, wrapped.Args[len(wrapped.Args)-12:])
+	assert.True(t, wrapped.Limits.IsZero(), "container enforces limits itself")
+
+	wrapped, err = sandbox.Bwrap{}.Wrap(cmd)
+	require.NoError(t, err)
+	assert.Equal(t, limits, wrapped.Limits, "bwrap children inherit process limits set by caller")
+}
+
+func TestWrap_RequiresRoot(t *testing.T) {
+	for _, runner := range []sandbox.Runner{sandbox.Container{Binary: "docker", Image: "alpine"}, sandbox.Bwrap{}} {
+		_, err := runner.Wrap(sandbox.Command{Name: "ls", Root: "relative"})
+		assert.Error(t, err)
+	}
+}
+
+func TestFor(t *testing.T) {
+	t.Cleanup(func() { sandbox.Setup(settings.Sandbox{}) })
+
+	sandbox.Setup(settings.Sandbox{})
+	assert.Nil(t, sandbox.For("aider"))
+
+	sandbox.Setup(settings.Sandbox{Runner: "bwrap", Commands: []string{"project-cmd"}})
+	assert.Nil(t, sandbox.For("aider"))
+	assert.Equal(t, sandbox.Bwrap{}, sandbox.For("project-cmd"))
+
+	sandbox.Setup(settings.Sandbox{Runner: "bwrap"})
+	assert.Equal(t, sandbox.Bwrap{Network: true}, sandbox.For("aider"), "aider needs network to reach LLM")
+	assert.Equal(t, sandbox.Bwrap{}, sandbox.For("project-cmd"))
+}
+
+func TestBinaryMounts(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	local := filepath.Join(home, ".local")
+	venv := filepath.Join(local, "share", "pipx", "venvs", "aider-chat")
+	require.NoError(t, os.MkdirAll(filepath.Join(local, "bin"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(venv, "bin"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(home, "bin"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(venv, "bin", "aider"), []byte("#!/bin/sh\n"), 0o755)) // nolint:gosec
+	require.NoError(t, os.Symlink(filepath.Join(venv, "bin", "aider"), filepath.Join(local, "bin", "aider")))
+	require.NoError(t, os.WriteFile(filepath.Join(home, "bin", "tool"), []byte("#!/bin/sh\n"), 0o755)) // nolint:gosec
+	t.Setenv("PATH", filepath.Join(local, "bin")+string(os.PathListSeparator)+filepath.Join(home, "bin")+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	assert.Equal(t, []string{local, venv}, sandbox.BinaryMounts("aider"))
+	assert.Equal(t, []string{filepath.Join(home, "bin")}, sandbox.BinaryMounts("tool"), "home dir itself is never mounted")
+	assert.Empty(t, sandbox.BinaryMounts("sh"), "system paths are already available")
+	assert.Empty(t, sandbox.BinaryMounts("andai-not-installed"))
+}
@@ -0,0 +1,64 @@
+package settings
+
+import (
+	"fmt"
+
+	"github.com/andrejsstepanovs/andai/internal/gitdiff"
+)
+
+const (
+	DefaultDiffMaxTokens     = 8000
+	DefaultDiffMaxFileTokens = 2000
+)
+
+// DefaultDiffExclude are files that only add noise to diff contexts.
+var DefaultDiffExclude = []string{"*.lock", "go.sum", "package-lock.json", "pnpm-lock.yaml", "*.min.js", "*.min.css"}
+
+// DiffLimit limits diff contexts (branch-diff, children-diff, last-commit-diff).
+type DiffLimit struct {
+	MaxTokens     int      `yaml:"max_tokens"`      // whole diff. Default 8000
+	MaxFileTokens int      `yaml:"max_file_tokens"` // single file diff. Default 2000
+	Exclude       []string `yaml:"exclude"`         // file globs. Default lock and minified files
+}
+
+// Merge returns limit where set override values win.
+func (d DiffLimit) Merge(override DiffLimit) DiffLimit {
+	merged := d
+	if override.MaxTokens != 0 {
+		merged.MaxTokens = override.MaxTokens
+	}
+	if override.MaxFileTokens != 0 {
+		merged.MaxFileTokens = override.MaxFileTokens
+	}
+	if override.Exclude != nil {
+		merged.Exclude = override.Exclude
+	}
+	return merged
+}
+
+// Options returns diff render options with defaults applied.
+func (d DiffLimit) Options() gitdiff.Options {
+	opts := gitdiff.Options{MaxTokens: d.MaxTokens, MaxFileTokens: d.MaxFileTokens, Exclude: d.Exclude}
+	if opts.MaxTokens == 0 {
+		opts.MaxTokens = DefaultDiffMaxTokens
+	}
+	if opts.MaxFileTokens == 0 {
+		opts.MaxFileTokens = DefaultDiffMaxFileTokens
+	}
+	if opts.Exclude == nil {
+		opts.Exclude = DefaultDiffExclude
+	}
+	return opts
+}
+
+func (d DiffLimit) Validate() error {
+	if d.MaxTokens < 0 || d.MaxFileTokens < 0 {
+		return fmt.Errorf("diff max_tokens and max_file_tokens cannot be negative")
+	}
+	for _, pattern := range d.Exclude {
+		if err := gitdiff.ValidatePattern(pattern); err != nil {
+			return fmt.Errorf("diff %w", err)
+		}
+	}
+	return nil
+}
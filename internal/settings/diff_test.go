@@ -0,0 +1,32 @@
+package settings_test
+
+import (
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffLimit_Options(t *testing.T) {
+	opts := settings.DiffLimit{}.Options()
+	assert.Equal(t, settings.DefaultDiffMaxTokens, opts.MaxTokens)
+	assert.Equal(t, settings.DefaultDiffMaxFileTokens, opts.MaxFileTokens)
+	assert.Equal(t, settings.DefaultDiffExclude, opts.Exclude)
+
+	project := settings.DiffLimit{MaxTokens: 4000, Exclude: []string{"*.lock"}}
+	step := settings.DiffLimit{MaxFileTokens: 500}
+	opts = project.Merge(step).Options()
+	assert.Equal(t, 4000, opts.MaxTokens)
+	assert.Equal(t, 500, opts.MaxFileTokens)
+	assert.Equal(t, []string{"*.lock"}, opts.Exclude)
+
+	opts = project.Merge(settings.DiffLimit{Exclude: []string{}}).Options()
+	assert.Empty(t, opts.Exclude, "empty list disables excludes")
+}
+
+func TestDiffLimit_Validate(t *testing.T) {
+	assert.NoError(t, settings.DiffLimit{}.Validate())
+	assert.NoError(t, settings.DiffLimit{MaxTokens: 100, Exclude: []string{"*.lock", "vendor/"}}.Validate())
+	assert.Error(t, settings.DiffLimit{MaxTokens: -1}.Validate())
+	assert.Error(t, settings.DiffLimit{Exclude: []string{"[a-"}}.Validate())
+}
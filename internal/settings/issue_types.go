@@ -29,6 +29,15 @@ const ContextFourComment = "last-4-comments"
 // ContextFifeComment matches the last fife comment
 const ContextFifeComment = "last-5-comments"
 
+// ContextHumanComments matches comments written by people (not andai)
+const ContextHumanComments = "human-comments"
+
+// ContextAIComments matches comments written by andai
+const ContextAIComments = "ai-comments"
+
+// ContextLastHumanComment matches the last comment written by people
+const ContextLastHumanComment = "last-human-comment"
+
 // ContextProject matches the project
 const ContextProject = "project"
 
@@ -53,9 +62,33 @@ const ContextParents = "parents"
 // ContextIssueTypes explains what each issue represents
 const ContextIssueTypes = "issue_types"
 
-// ContextAffectedFiles provides all files that were touched in children and siblings branches.
+// ContextAffectedFiles provides all files changed in issue branch (including merged children branches).
 const ContextAffectedFiles = "affected-files"
 
+// ContextBranchDiff provides unified diff of issue branch against parent branch.
+const ContextBranchDiff = "branch-diff"
+
+// ContextChildrenDiff provides unified diff of each merged (closed) child issue.
+const ContextChildrenDiff = "children-diff"
+
+// ContextRepoMap provides map of repository packages and symbols mentioned in ticket.
+const ContextRepoMap = "repo-map"
+
+// ContextLastCommitDiff provides unified diff of last commit in issue branch.
+const ContextLastCommitDiff = "last-commit-diff"
+
+// ContextAttachments provides files attached to the issue (text inlined, images for vision models).
+const ContextAttachments = "attachments"
+
+// ContextParentAttachments provides files attached to the parent issue.
+const ContextParentAttachments = "parent-attachments"
+
+// ContextPreviousAttempts summarizes earlier attempts made in current issue state (steps, commits, verdict, feedback).
+const ContextPreviousAttempts = "previous-attempts"
+
+// ContextLessons provides project lessons learned (redmine wiki page maintained by `learn` step) relevant to the issue.
+const ContextLessons = "lessons"
+
 type IssueTypeName string
 
 type IssueTypes map[IssueTypeName]IssueType
@@ -79,14 +112,16 @@ type Contexts []string
 type StepPrompt string
 
 type Step struct {
-	Command        string     `yaml:"command"`
-	Action         string     `yaml:"action"`
-	Comment        bool       `yaml:"comment"`
-	Remember       bool       `yaml:"remember"`
-	Context        Contexts   `yaml:"context"`
-	Prompt         StepPrompt `yaml:"prompt"`
-	Summarize      bool       `yaml:"summarize"`
-	CommentSummary bool       `yaml:"comment-summary"`
+	Command        string           `yaml:"command"`
+	Action         string           `yaml:"action"`
+	Comment        bool             `yaml:"comment"`
+	Remember       bool             `yaml:"remember"`
+	Context        Contexts         `yaml:"context"`
+	Prompt         StepPrompt       `yaml:"prompt"`
+	Summarize      bool             `yaml:"summarize"`
+	CommentSummary bool             `yaml:"comment-summary"`
+	OutputLimit    `yaml:",inline"` // only for bash
+	Diff           DiffLimit        `yaml:"diff"` // overrides project diff limits for diff contexts
 	History        []string
 	ContextFiles   []string
 }
@@ -24,6 +24,23 @@ type LlmModel struct {
 	MaxTokens   int       `yaml:"max_tokens"`
 	MaxRetries  int       `yaml:"max_retries"`
 	Commands    []string  `yaml:"commands"`
+	Vision      bool      `yaml:"vision"` // model accepts images (OpenAI compatible providers only)
+}
+
+// llmCommands are step commands that call LLM directly (and can be assigned to llm_models).
+var llmCommands = map[string]bool{
+	"evaluate":          true,
+	"summarize-task":    true,
+	"ai":                true,
+	"create-issues":     true,
+	"merge-into-parent": true,
+	"review":            true,
+	"learn":             true,
+}
+
+// IsLlmCommand returns true if step command calls LLM directly (not through coding agent).
+func IsLlmCommand(command string) bool {
+	return llmCommands[command]
 }
 
 func (e EnvVarStr) String() string {
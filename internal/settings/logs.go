@@ -0,0 +1,20 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Logs configures per issue step output log files.
+type Logs struct {
+	Dir     string `yaml:"dir"`     // where log files are stored. Default is andai-logs in system temp dir.
+	Comment bool   `yaml:"comment"` // attach run log to the issue with a comment when run ends
+}
+
+// GetDir returns configured log dir or default one.
+func (l Logs) GetDir() string {
+	if l.Dir == "" {
+		return filepath.Join(os.TempDir(), "andai-logs")
+	}
+	return l.Dir
+}
@@ -0,0 +1,37 @@
+package settings
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/andrejsstepanovs/andai/internal/truncate"
+)
+
+// OutputLimit keeps huge command output from flooding prompts and history.
+type OutputLimit struct {
+	MaxOutputTokens int    `yaml:"max_output_tokens"` // 0 means no limit
+	Truncate        string `yaml:"truncate"`          // head, tail, head-tail (default), errors
+	SummarizeOutput bool   `yaml:"summarize_output"`  // ask LLM to summarize output if it is still too big after dedup
+}
+
+// GetTruncate returns configured truncation mode or default one.
+func (o OutputLimit) GetTruncate() string {
+	if o.Truncate == "" {
+		return truncate.ModeHeadTail
+	}
+	return o.Truncate
+}
+
+func (o OutputLimit) Validate() error {
+	if o.MaxOutputTokens < 0 {
+		return fmt.Errorf("max_output_tokens cannot be negative")
+	}
+	if o.Truncate != "" && !slices.Contains(truncate.Modes, o.Truncate) {
+		return fmt.Errorf("truncate %q is not supported, use one of: %s", o.Truncate, strings.Join(truncate.Modes, ", "))
+	}
+	if o.MaxOutputTokens == 0 && (o.Truncate != "" || o.SummarizeOutput) {
+		return fmt.Errorf("truncate and summarize_output require max_output_tokens")
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package settings_test
+
+import (
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputLimit(t *testing.T) {
+	tests := []struct {
+		name             string
+		limit            settings.OutputLimit
+		expectedTruncate string
+		expectErr        bool
+	}{
+		{
+			name:             "empty",
+			limit:            settings.OutputLimit{},
+			expectedTruncate: "head-tail",
+		},
+		{
+			name:             "errors mode",
+			limit:            settings.OutputLimit{MaxOutputTokens: 2000, Truncate: "errors", SummarizeOutput: true},
+			expectedTruncate: "errors",
+		},
+		{
+			name:             "unknown mode",
+			limit:            settings.OutputLimit{MaxOutputTokens: 2000, Truncate: "middle"},
+			expectedTruncate: "middle",
+			expectErr:        true,
+		},
+		{
+			name:             "negative",
+			limit:            settings.OutputLimit{MaxOutputTokens: -1},
+			expectedTruncate: "head-tail",
+			expectErr:        true,
+		},
+		{
+			name:             "mode without limit",
+			limit:            settings.OutputLimit{Truncate: "tail"},
+			expectedTruncate: "tail",
+			expectErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedTruncate, tt.limit.GetTruncate())
+			err := tt.limit.Validate()
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
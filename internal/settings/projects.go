@@ -2,6 +2,22 @@ package settings
 
 import "fmt"
 
+const (
+	MergeStrategyMerge  = "merge"   // git merge (creates merge commit if needed)
+	MergeStrategySquash = "squash"  // single commit on parent branch
+	MergeStrategyRebase = "rebase"  // rebase issue branch onto parent, then fast-forward
+	MergeStrategyFFOnly = "ff-only" // fast-forward only, fails if parent moved
+
+	ConflictPolicyAbort   = "abort"   // abort merge, comment conflicts and move issue to fail state
+	ConflictPolicyResolve = "resolve" // let coding agent resolve conflicts (see resolve-conflicts step)
+
+	SquashMessageIssue = "issue" // "#<id> <subject>"
+	SquashMessageLLM   = "llm"   // issue line followed by LLM summary of branch commits
+)
+
+// MergeStrategies lists supported merge_strategy values.
+var MergeStrategies = []string{MergeStrategyMerge, MergeStrategySquash, MergeStrategyRebase, MergeStrategyFFOnly}
+
 type Projects []Project
 
 type ProjectCommand struct {
@@ -10,6 +26,10 @@ type ProjectCommand struct {
 	IgnoreError            bool     `yaml:"ignore_err"`
 	IgnoreStdOutIfNoStdErr bool     `yaml:"ignore_stdout_if_no_stderr"`
 	SuccessIfNoOutput      bool     `yaml:"success_if_no_output"`
+	Parser                 string   `yaml:"parser"`      // go-test-json, junit, tap, golangci-lint, sarif
+	ReportFile             string   `yaml:"report_file"` // parse this file instead of stdout
+	OutputLimit            `yaml:",inline"`
+	Runtime                `yaml:",inline"`
 }
 
 type ProjectCommands []ProjectCommand
@@ -21,9 +41,17 @@ type Project struct {
 	GitPath                string          `yaml:"git_path"`
 	LocalGitPath           string          `yaml:"git_local_dir"`
 	FinalBranch            string          `yaml:"final_branch"`
+	BranchTemplate         string          `yaml:"branch_template"`           // issue branch name template. Default AI-{{.ID}}
 	DeleteBranchAfterMerge bool            `yaml:"delete_branch_after_merge"` // will delete source (child) branch after merge into parent
+	MergeStrategy          string          `yaml:"merge_strategy"`            // merge (default), squash, rebase, ff-only
+	SquashMessage          string          `yaml:"squash_message"`            // issue (default) or llm
+	ConflictPolicy         string          `yaml:"conflict_policy"`           // abort (default) or resolve
+	ConflictCheck          string          `yaml:"conflict_check"`            // project command that must pass after conflicts are resolved
+	Remote                 Remote          `yaml:"remote"`
+	Diff                   DiffLimit       `yaml:"diff"` // limits for diff contexts
 	Wiki                   string          `yaml:"wiki"`
 	Commands               ProjectCommands `yaml:"commands"`
+	Runtime                `yaml:",inline"`
 }
 
 func (p Projects) Find(identifier string) Project {
@@ -35,6 +63,38 @@ func (p Projects) Find(identifier string) Project {
 	return Project{}
 }
 
+// GetMergeStrategy returns merge strategy for merge-into-parent. Step action (if set) overrides project setting.
+func (p Project) GetMergeStrategy(stepAction string) string {
+	if stepAction != "" {
+		return stepAction
+	}
+	if p.MergeStrategy != "" {
+		return p.MergeStrategy
+	}
+	return MergeStrategyMerge
+}
+
+// GetSquashMessage returns how squash commit message is built.
+func (p Project) GetSquashMessage() string {
+	if p.SquashMessage == "" {
+		return SquashMessageIssue
+	}
+	return p.SquashMessage
+}
+
+// GetConflictPolicy returns what happens when merge-into-parent hits conflicts.
+func (p Project) GetConflictPolicy() string {
+	if p.ConflictPolicy == "" {
+		return ConflictPolicyAbort
+	}
+	return p.ConflictPolicy
+}
+
+// CommandRuntime returns project runtime overridden by command runtime.
+func (p Project) CommandRuntime(command ProjectCommand) Runtime {
+	return p.Runtime.Merge(command.Runtime)
+}
+
 func (p ProjectCommands) Find(identifier string) (ProjectCommand, error) {
 	for _, command := range p {
 		if command.Name == identifier {
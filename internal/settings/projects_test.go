@@ -0,0 +1,32 @@
+package settings_test
+
+import (
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProject_GetMergeStrategy(t *testing.T) {
+	tests := []struct {
+		name       string
+		project    settings.Project
+		stepAction string
+		expected   string
+	}{
+		{name: "default", project: settings.Project{}, expected: "merge"},
+		{name: "project", project: settings.Project{MergeStrategy: "squash"}, expected: "squash"},
+		{name: "step overrides project", project: settings.Project{MergeStrategy: "squash"}, stepAction: "ff-only", expected: "ff-only"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.project.GetMergeStrategy(tt.stepAction))
+		})
+	}
+}
+
+func TestProject_GetSquashMessage(t *testing.T) {
+	assert.Equal(t, "issue", settings.Project{}.GetSquashMessage())
+	assert.Equal(t, "llm", settings.Project{SquashMessage: "llm"}.GetSquashMessage())
+}
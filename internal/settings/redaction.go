@@ -0,0 +1,39 @@
+package settings
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Redaction configures removal of secrets from everything sent to LLM (prompts and aider message files).
+// Built-in detectors (common key formats, password assignments, high-entropy strings) are always on unless disabled.
+type Redaction struct {
+	Disabled bool     `yaml:"disabled"` // turn redaction off completely
+	Entropy  *bool    `yaml:"entropy"`  // redact high-entropy strings. Default true.
+	Patterns []string `yaml:"patterns"` // extra regexes. If regex has capture group, only first group is redacted.
+	Env      []string `yaml:"env"`      // env variable names which values are redacted wherever they appear
+	Allow    []string `yaml:"allow"`    // literal values that are never redacted (false positives)
+}
+
+// GetEntropy returns true if high-entropy strings should be redacted.
+func (r Redaction) GetEntropy() bool {
+	return r.Entropy == nil || *r.Entropy
+}
+
+func (r Redaction) Validate() error {
+	for _, pattern := range r.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("redaction pattern %q is not valid: %v", pattern, err)
+		}
+		if re.MatchString("") {
+			return fmt.Errorf("redaction pattern %q matches empty string", pattern)
+		}
+	}
+	for _, name := range r.Env {
+		if !envNameRe.MatchString(name) {
+			return fmt.Errorf("redaction env %q is not a valid variable name", name)
+		}
+	}
+	return nil
+}
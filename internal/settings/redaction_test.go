@@ -0,0 +1,37 @@
+package settings_test
+
+import (
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedaction_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		redaction settings.Redaction
+		expectErr bool
+	}{
+		{name: "default", redaction: settings.Redaction{}},
+		{name: "patterns and env", redaction: settings.Redaction{Patterns: []string{`INTERNAL-[0-9]{6}`, `ticket=(\w+)`}, Env: []string{"DB_PASSWORD"}}},
+		{name: "bad pattern", redaction: settings.Redaction{Patterns: []string{`(`}}, expectErr: true},
+		{name: "empty match pattern", redaction: settings.Redaction{Patterns: []string{`a*`}}, expectErr: true},
+		{name: "bad env", redaction: settings.Redaction{Env: []string{"DB-PASSWORD"}}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.redaction.Validate()
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	off := false
+	assert.True(t, settings.Redaction{}.GetEntropy())
+	assert.False(t, settings.Redaction{Entropy: &off}.GetEntropy())
+}
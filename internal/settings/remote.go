@@ -0,0 +1,74 @@
+package settings
+
+import (
+	"fmt"
+	"slices"
+)
+
+const (
+	PushOnMergeIntoFinal = "merge-into-final" // push final branch after issue is merged into it
+	PushOnEveryCommit    = "every-commit"     // push issue branch after every step that created commits
+	PushOnNever          = "never"
+
+	DefaultRemoteName = "origin"
+)
+
+// Remote configures pushing branches to git remote (so CI can run).
+type Remote struct {
+	Name      string            `yaml:"name"`      // git remote name. Default origin.
+	URL       string            `yaml:"url"`       // optional. If set, remote is added (or its url updated).
+	PushOn    []string          `yaml:"push_on"`   // merge-into-final, every-commit, never (default)
+	Branches  map[string]string `yaml:"branches"`  // local branch -> remote branch name
+	Force     bool              `yaml:"force"`     // allow force push (with lease) of issue branches
+	Protected []string          `yaml:"protected"` // remote branches that are never force pushed. Final branch is always protected.
+}
+
+// GetName returns remote name or default.
+func (r Remote) GetName() string {
+	if r.Name == "" {
+		return DefaultRemoteName
+	}
+	return r.Name
+}
+
+// PushesOn tells if push should happen on given event.
+func (r Remote) PushesOn(event string) bool {
+	return slices.Contains(r.PushOn, event)
+}
+
+// RemoteBranch maps local branch name to remote branch name.
+func (r Remote) RemoteBranch(localBranch string) string {
+	if mapped, ok := r.Branches[localBranch]; ok && mapped != "" {
+		return mapped
+	}
+	return localBranch
+}
+
+// CanForce tells if local branch may be force pushed. Final branch and protected remote branches never are.
+func (r Remote) CanForce(localBranch, finalBranch string) bool {
+	if !r.Force || localBranch == finalBranch {
+		return false
+	}
+	remoteBranch := r.RemoteBranch(localBranch)
+	return remoteBranch != r.RemoteBranch(finalBranch) && !slices.Contains(r.Protected, remoteBranch)
+}
+
+func (r Remote) Validate() error {
+	for _, event := range r.PushOn {
+		switch event {
+		case PushOnMergeIntoFinal, PushOnEveryCommit:
+		case PushOnNever:
+			if len(r.PushOn) > 1 {
+				return fmt.Errorf("remote push_on %q cannot be combined with other values", PushOnNever)
+			}
+		default:
+			return fmt.Errorf("remote push_on %q is not valid, use: %s, %s or %s", event, PushOnMergeIntoFinal, PushOnEveryCommit, PushOnNever)
+		}
+	}
+	for local, remote := range r.Branches {
+		if local == "" || remote == "" {
+			return fmt.Errorf("remote branches mapping %q -> %q cannot have empty names", local, remote)
+		}
+	}
+	return nil
+}
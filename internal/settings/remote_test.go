@@ -0,0 +1,57 @@
+package settings_test
+
+import (
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemote(t *testing.T) {
+	remote := settings.Remote{
+		PushOn:    []string{"merge-into-final"},
+		Branches:  map[string]string{"main": "develop", "AI-1": "ai/1"},
+		Force:     true,
+		Protected: []string{"release"},
+	}
+
+	assert.Equal(t, "origin", remote.GetName())
+	assert.True(t, remote.PushesOn("merge-into-final"))
+	assert.False(t, remote.PushesOn("every-commit"))
+
+	assert.Equal(t, "develop", remote.RemoteBranch("main"))
+	assert.Equal(t, "ai/1", remote.RemoteBranch("AI-1"))
+	assert.Equal(t, "AI-2", remote.RemoteBranch("AI-2"))
+
+	assert.True(t, remote.CanForce("AI-1", "main"))
+	assert.False(t, remote.CanForce("main", "main"), "final branch")
+	assert.False(t, remote.CanForce("develop", "main"), "maps to same remote branch as final")
+	assert.False(t, remote.CanForce("release", "main"), "protected")
+	assert.False(t, settings.Remote{}.CanForce("AI-1", "main"), "force not enabled")
+}
+
+func TestRemote_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		remote    settings.Remote
+		expectErr bool
+	}{
+		{name: "empty", remote: settings.Remote{}},
+		{name: "both events", remote: settings.Remote{PushOn: []string{"merge-into-final", "every-commit"}}},
+		{name: "never", remote: settings.Remote{PushOn: []string{"never"}}},
+		{name: "never with other", remote: settings.Remote{PushOn: []string{"never", "every-commit"}}, expectErr: true},
+		{name: "unknown event", remote: settings.Remote{PushOn: []string{"on-close"}}, expectErr: true},
+		{name: "empty mapping", remote: settings.Remote{Branches: map[string]string{"main": ""}}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.remote.Validate()
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package settings
+
+import "slices"
+
+// Review finding severities, from least to most severe.
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityError    = "error"
+	SeverityCritical = "critical"
+
+	// DefaultReviewThreshold is lowest severity that fails `review` step.
+	DefaultReviewThreshold = SeverityError
+)
+
+// ReviewSeverities lists severities from least to most severe.
+var ReviewSeverities = []string{SeverityInfo, SeverityWarning, SeverityError, SeverityCritical}
+
+// SeverityRank returns severity position in ReviewSeverities. Unknown severity is -1.
+func SeverityRank(severity string) int {
+	return slices.Index(ReviewSeverities, severity)
+}
+
+// GetReviewThreshold returns `review` step action (severity threshold) or default one.
+func (s *Step) GetReviewThreshold() string {
+	if s.Action == "" {
+		return DefaultReviewThreshold
+	}
+	return s.Action
+}
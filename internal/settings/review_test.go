@@ -0,0 +1,23 @@
+package settings_test
+
+import (
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeverityRank(t *testing.T) {
+	assert.Less(t, settings.SeverityRank("info"), settings.SeverityRank("warning"))
+	assert.Less(t, settings.SeverityRank("warning"), settings.SeverityRank("error"))
+	assert.Less(t, settings.SeverityRank("error"), settings.SeverityRank("critical"))
+	assert.Equal(t, -1, settings.SeverityRank("high"))
+}
+
+func TestStep_GetReviewThreshold(t *testing.T) {
+	step := settings.Step{Command: "review"}
+	assert.Equal(t, "error", step.GetReviewThreshold())
+
+	step.Action = "warning"
+	assert.Equal(t, "warning", step.GetReviewThreshold())
+}
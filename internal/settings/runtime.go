@@ -0,0 +1,128 @@
+package settings
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultCommandTimeout is used when project command has no timeout.
+const DefaultCommandTimeout = 30 * time.Minute
+
+var envNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Runtime configures environment, working directory and limits project commands run with.
+// Can be set on project (applies to all its commands) and on command (overrides project).
+type Runtime struct {
+	Env     map[string]EnvVarStr `yaml:"env"`     // values support os.environ/NAME references
+	Workdir string               `yaml:"workdir"` // relative to repository root
+	Timeout string               `yaml:"timeout"` // default 30m
+	Limits  Limits               `yaml:"limits"`
+}
+
+// Limits are process resource limits (linux only). Zero means no limit.
+type Limits struct {
+	CPUSeconds int     `yaml:"cpu_seconds"`
+	MemoryMB   int     `yaml:"memory_mb"`
+	FileSizeMB int     `yaml:"file_size_mb"`
+	Processes  int     `yaml:"processes"` // container sandbox only
+	CPUs       float64 `yaml:"cpus"`      // container sandbox only
+	OutputKB   int     `yaml:"output_kb"` // cap captured stdout and stderr (each)
+}
+
+// Merge returns runtime where values set in other override values in r. Env is merged key by key.
+func (r Runtime) Merge(other Runtime) Runtime {
+	merged := r
+	merged.Env = make(map[string]EnvVarStr, len(r.Env)+len(other.Env))
+	for k, v := range r.Env {
+		merged.Env[k] = v
+	}
+	for k, v := range other.Env {
+		merged.Env[k] = v
+	}
+	if other.Workdir != "" {
+		merged.Workdir = other.Workdir
+	}
+	if other.Timeout != "" {
+		merged.Timeout = other.Timeout
+	}
+	if other.Limits.CPUSeconds > 0 {
+		merged.Limits.CPUSeconds = other.Limits.CPUSeconds
+	}
+	if other.Limits.MemoryMB > 0 {
+		merged.Limits.MemoryMB = other.Limits.MemoryMB
+	}
+	if other.Limits.FileSizeMB > 0 {
+		merged.Limits.FileSizeMB = other.Limits.FileSizeMB
+	}
+	if other.Limits.Processes > 0 {
+		merged.Limits.Processes = other.Limits.Processes
+	}
+	if other.Limits.CPUs > 0 {
+		merged.Limits.CPUs = other.Limits.CPUs
+	}
+	if other.Limits.OutputKB > 0 {
+		merged.Limits.OutputKB = other.Limits.OutputKB
+	}
+	return merged
+}
+
+// GetTimeout returns parsed timeout or DefaultCommandTimeout.
+func (r Runtime) GetTimeout() time.Duration {
+	if r.Timeout == "" {
+		return DefaultCommandTimeout
+	}
+	timeout, err := time.ParseDuration(r.Timeout)
+	if err != nil || timeout <= 0 {
+		return DefaultCommandTimeout
+	}
+	return timeout
+}
+
+// EnvList returns resolved KEY=value pairs sorted by key.
+func (r Runtime) EnvList() []string {
+	keys := make([]string, 0, len(r.Env))
+	for k := range r.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	env := make([]string, 0, len(keys))
+	for _, k := range keys {
+		env = append(env, fmt.Sprintf("%s=%s", k, r.Env[k].String()))
+	}
+	return env
+}
+
+func (r Runtime) Validate() error {
+	for k := range r.Env {
+		if !envNameRe.MatchString(k) {
+			return fmt.Errorf("env %q is not a valid variable name", k)
+		}
+	}
+	if r.Workdir != "" {
+		if filepath.IsAbs(r.Workdir) {
+			return fmt.Errorf("workdir %q must be relative to repository root", r.Workdir)
+		}
+		clean := filepath.Clean(r.Workdir)
+		if clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("workdir %q must be inside repository", r.Workdir)
+		}
+	}
+	if r.Timeout != "" {
+		timeout, err := time.ParseDuration(r.Timeout)
+		if err != nil {
+			return fmt.Errorf("timeout %q is not valid: %w", r.Timeout, err)
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("timeout %q must be positive", r.Timeout)
+		}
+	}
+	if r.Limits.CPUSeconds < 0 || r.Limits.MemoryMB < 0 || r.Limits.FileSizeMB < 0 || r.Limits.Processes < 0 || r.Limits.CPUs < 0 || r.Limits.OutputKB < 0 {
+		return fmt.Errorf("limits cannot be negative")
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package settings_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuntime_Merge(t *testing.T) {
+	t.Setenv("ANDAI_TEST_TOKEN", "secret")
+
+	project := settings.Project{
+		Runtime: settings.Runtime{
+			Env:     map[string]settings.EnvVarStr{"GOFLAGS": "-mod=vendor", "CI": "true"},
+			Workdir: "backend",
+			Timeout: "10m",
+			Limits:  settings.Limits{MemoryMB: 2048, OutputKB: 512},
+		},
+	}
+	command := settings.ProjectCommand{
+		Name: "test",
+		Runtime: settings.Runtime{
+			Env:    map[string]settings.EnvVarStr{"CI": "1", "TOKEN": "os.environ/ANDAI_TEST_TOKEN"},
+			Limits: settings.Limits{CPUSeconds: 60, MemoryMB: 4096, CPUs: 1.5},
+		},
+	}
+
+	runtime := project.CommandRuntime(command)
+
+	assert.Equal(t, []string{"CI=1", "GOFLAGS=-mod=vendor", "TOKEN=secret"}, runtime.EnvList())
+	assert.Equal(t, "backend", runtime.Workdir)
+	assert.Equal(t, 10*time.Minute, runtime.GetTimeout())
+	assert.Equal(t, settings.Limits{CPUSeconds: 60, MemoryMB: 4096, CPUs: 1.5, OutputKB: 512}, runtime.Limits)
+	assert.Len(t, project.Env, 2, "project env must not be modified")
+}
+
+func TestRuntime_GetTimeout(t *testing.T) {
+	assert.Equal(t, settings.DefaultCommandTimeout, settings.Runtime{}.GetTimeout())
+	assert.Equal(t, 90*time.Second, settings.Runtime{Timeout: "90s"}.GetTimeout())
+	assert.Equal(t, settings.DefaultCommandTimeout, settings.Runtime{Timeout: "soon"}.GetTimeout())
+}
+
+func TestRuntime_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		runtime   settings.Runtime
+		expectErr bool
+	}{
+		{name: "empty", runtime: settings.Runtime{}},
+		{name: "valid", runtime: settings.Runtime{Env: map[string]settings.EnvVarStr{"GO_ENV": "test"}, Workdir: "./services/api", Timeout: "5m"}},
+		{name: "bad env name", runtime: settings.Runtime{Env: map[string]settings.EnvVarStr{"1BAD": "x"}}, expectErr: true},
+		{name: "absolute workdir", runtime: settings.Runtime{Workdir: "/tmp"}, expectErr: true},
+		{name: "workdir outside repo", runtime: settings.Runtime{Workdir: "api/../../other"}, expectErr: true},
+		{name: "bad timeout", runtime: settings.Runtime{Timeout: "forever"}, expectErr: true},
+		{name: "zero timeout", runtime: settings.Runtime{Timeout: "0s"}, expectErr: true},
+		{name: "negative limit", runtime: settings.Runtime{Limits: settings.Limits{MemoryMB: -1}}, expectErr: true},
+		{name: "negative cpus", runtime: settings.Runtime{Limits: settings.Limits{CPUs: -0.5}}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.runtime.Validate()
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
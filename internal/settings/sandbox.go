@@ -0,0 +1,83 @@
+package settings
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	SandboxNone   = "none"
+	SandboxDocker = "docker"
+	SandboxPodman = "podman"
+	SandboxBwrap  = "bwrap"
+)
+
+// Sandbox configures runner that wraps AI driven commands (coding agent, project commands) so they
+// do not run directly on host with full user privileges.
+type Sandbox struct {
+	Runner   string   `yaml:"runner"`   // none (default), docker, podman, bwrap
+	Image    string   `yaml:"image"`    // container image (docker, podman)
+	Mounts   []string `yaml:"mounts"`   // extra absolute host paths, read-only unless suffixed with ":rw". Repository is always mounted.
+	Env      []string `yaml:"env"`      // host env variable names passed into sandbox
+	Network  bool     `yaml:"network"`  // allow network access for project commands. Default false. Aider always has network.
+	Args     []string `yaml:"args"`     // extra runner arguments
+	Commands []string `yaml:"commands"` // step commands that are sandboxed. Default aider and project-cmd.
+}
+
+// GetRunner returns configured runner name or none.
+func (s Sandbox) GetRunner() string {
+	if s.Runner == "" {
+		return SandboxNone
+	}
+	return s.Runner
+}
+
+// GetCommands returns step commands that should run in sandbox.
+func (s Sandbox) GetCommands() []string {
+	if len(s.Commands) == 0 {
+		return []string{"aider", "project-cmd"}
+	}
+	return s.Commands
+}
+
+// NetworkFor tells if sandboxed step command gets network access. Aider can not work without reaching LLM.
+func (s Sandbox) NetworkFor(command string) bool {
+	return s.Network || command == "aider"
+}
+
+func (s Sandbox) Validate() error {
+	switch s.GetRunner() {
+	case SandboxNone:
+		return nil
+	case SandboxDocker, SandboxPodman:
+		if s.Image == "" {
+			return fmt.Errorf("sandbox %q image is required", s.Runner)
+		}
+	case SandboxBwrap:
+		if s.Image != "" {
+			return fmt.Errorf("sandbox %q does not use image", s.Runner)
+		}
+	default:
+		return fmt.Errorf("sandbox runner %q is not valid, use one of: none, docker, podman, bwrap", s.Runner)
+	}
+
+	for _, mount := range s.Mounts {
+		path := strings.TrimSuffix(mount, ":rw")
+		if !filepath.IsAbs(path) {
+			return fmt.Errorf("sandbox mount %q must be absolute path", mount)
+		}
+	}
+	for _, name := range s.Env {
+		if !envNameRe.MatchString(name) {
+			return fmt.Errorf("sandbox env %q is not a valid variable name", name)
+		}
+	}
+	for _, command := range s.GetCommands() {
+		if !slices.Contains([]string{"aider", "project-cmd"}, command) {
+			return fmt.Errorf("sandbox command %q is not supported, use aider or project-cmd", command)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package settings_test
+
+import (
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSandbox_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		sandbox   settings.Sandbox
+		expectErr bool
+	}{
+		{name: "default", sandbox: settings.Sandbox{}},
+		{name: "docker", sandbox: settings.Sandbox{Runner: "docker", Image: "golang:1.23", Mounts: []string{"/cache:rw"}, Env: []string{"GOPROXY"}}},
+		{name: "bwrap", sandbox: settings.Sandbox{Runner: "bwrap", Commands: []string{"project-cmd"}}},
+		{name: "unknown runner", sandbox: settings.Sandbox{Runner: "firejail"}, expectErr: true},
+		{name: "docker without image", sandbox: settings.Sandbox{Runner: "docker"}, expectErr: true},
+		{name: "bwrap with image", sandbox: settings.Sandbox{Runner: "bwrap", Image: "alpine"}, expectErr: true},
+		{name: "relative mount", sandbox: settings.Sandbox{Runner: "bwrap", Mounts: []string{"cache"}}, expectErr: true},
+		{name: "bad env", sandbox: settings.Sandbox{Runner: "bwrap", Env: []string{"A=B"}}, expectErr: true},
+		{name: "bad command", sandbox: settings.Sandbox{Runner: "bwrap", Commands: []string{"bash"}}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.sandbox.Validate()
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	assert.Equal(t, []string{"aider", "project-cmd"}, settings.Sandbox{}.GetCommands())
+	assert.Equal(t, "none", settings.Sandbox{}.GetRunner())
+}
+
+func TestSandbox_NetworkFor(t *testing.T) {
+	assert.True(t, settings.Sandbox{}.NetworkFor("aider"))
+	assert.False(t, settings.Sandbox{}.NetworkFor("project-cmd"))
+	assert.True(t, settings.Sandbox{Network: true}.NetworkFor("project-cmd"))
+}
@@ -0,0 +1,15 @@
+package settings
+
+import "strconv"
+
+// DefaultSearchResults is how many files `context-search` step adds to context files.
+const DefaultSearchResults = 5
+
+// GetSearchLimit returns `context-search` step action (number of files) or default one.
+func (s *Step) GetSearchLimit() int {
+	limit, err := strconv.Atoi(s.Action)
+	if err != nil || limit <= 0 {
+		return DefaultSearchResults
+	}
+	return limit
+}
@@ -0,0 +1,16 @@
+package settings_test
+
+import (
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStep_GetSearchLimit(t *testing.T) {
+	step := settings.Step{Command: "context-search"}
+	assert.Equal(t, settings.DefaultSearchResults, step.GetSearchLimit())
+
+	step.Action = "12"
+	assert.Equal(t, 12, step.GetSearchLimit())
+}
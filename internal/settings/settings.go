@@ -2,7 +2,13 @@ package settings
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/andrejsstepanovs/andai/internal/branchname"
+	"github.com/andrejsstepanovs/andai/internal/testresult"
 )
 
 type Settings struct {
@@ -10,6 +16,10 @@ type Settings struct {
 	Projects     Projects     `yaml:"projects"`
 	LlmModels    LlmModels    `yaml:"llm_models"`
 	CodingAgents CodingAgents `yaml:"coding_agents"`
+	Shell        Shell        `yaml:"shell"`
+	Logs         Logs         `yaml:"logs"`
+	Sandbox      Sandbox      `yaml:"sandbox"`
+	Redaction    Redaction    `yaml:"redaction"`
 }
 
 func (s *Settings) getAllIssueTypesAndStates() map[IssueTypeName]map[StateName]State {
@@ -24,6 +34,16 @@ func (s *Settings) getAllIssueTypesAndStates() map[IssueTypeName]map[StateName]S
 	return issueTypesAndStates
 }
 
+// issueTypeNames returns sorted issue type (tracker) names.
+func (s *Settings) issueTypeNames() []string {
+	names := make([]string, 0, len(s.Workflow.IssueTypes))
+	for issueTypeName := range s.Workflow.IssueTypes {
+		names = append(names, string(issueTypeName))
+	}
+	slices.Sort(names)
+	return names
+}
+
 func (s *Settings) validateStates(issueTypeNames map[IssueTypeName]bool) error {
 	if len(s.Workflow.States) == 0 {
 		return fmt.Errorf("workflow states are required")
@@ -100,14 +120,19 @@ func (s *Settings) validateStep(
 	case "next":
 	case "create-issues":
 	case "merge-into-parent":
+	case "git-push":
+	case "resolve-conflicts":
+	case "review":
 	case "project-cmd":
 	case "summarize-task":
 	case "commit": //nolint:goconst
 	case "evaluate":
+	case "learn":
 	case "ai":
 	case "bash":
 	case "context-files":
 	case "context-commits":
+	case "context-search":
 	case "aider":
 	default:
 		return fmt.Errorf("step command %q is not valid", step.Command)
@@ -131,6 +156,18 @@ func (s *Settings) validateStep(
 		}
 	}
 
+	if step.Command == "bash" {
+		if err := step.OutputLimit.Validate(); err != nil {
+			return fmt.Errorf("%q step in %q in %q %w", step.Command, stateName, types.Name, err)
+		}
+	} else if step.OutputLimit != (OutputLimit{}) {
+		return fmt.Errorf("%q step %q in %q cannot have output limits (only `bash`, use project command settings for `project-cmd`)", step.Command, step.Action, stateName)
+	}
+
+	if step.Command == "merge-into-parent" && step.Action != "" && !slices.Contains(MergeStrategies, step.Action) {
+		return fmt.Errorf("%q step action %q is not a valid merge strategy for %q in %q, use one of: %s", step.Command, step.Action, types.Name, stateName, strings.Join(MergeStrategies, ", "))
+	}
+
 	if step.Command == "create-issues" {
 		if _, ok := issueTypeNames[IssueTypeName(step.Action)]; !ok {
 			return fmt.Errorf("%q step action %q is not a valid issue type for %q in %q", step.Command, step.Action, types.Name, stateName)
@@ -143,6 +180,32 @@ func (s *Settings) validateStep(
 		}
 	}
 
+	if step.Command == "git-push" {
+		switch step.Action {
+		case "", "issue", "parent", "final":
+		default:
+			return fmt.Errorf("%q step action %q is not valid for %q in %q, use issue, parent or final", step.Command, step.Action, types.Name, stateName)
+		}
+	}
+
+	if step.Command == "review" && step.Action != "" && SeverityRank(step.Action) < 0 {
+		return fmt.Errorf("%q step action %q is not a valid severity for %q in %q, use one of: %s", step.Command, step.Action, types.Name, stateName, strings.Join(ReviewSeverities, ", "))
+	}
+
+	if step.Command == "context-search" && step.Action != "" {
+		if limit, err := strconv.Atoi(step.Action); err != nil || limit <= 0 {
+			return fmt.Errorf("%q step action %q must be positive number of files for %q in %q", step.Command, step.Action, types.Name, stateName)
+		}
+	}
+
+	if step.Command == "resolve-conflicts" && step.Action != "" {
+		for _, projectCfg := range s.Projects {
+			if _, err := projectCfg.Commands.Find(step.Action); err != nil {
+				return fmt.Errorf("%q step action %q missing for %q in %q in project %q", step.Command, step.Action, types.Name, stateName, projectCfg.Name)
+			}
+		}
+	}
+
 	if step.Command == "project-cmd" {
 		if step.Action == "" {
 			return fmt.Errorf("%q step action is required for %q in %q", step.Command, types.Name, stateName)
@@ -177,6 +240,10 @@ func (s *Settings) validateStep(
 		}
 	}
 
+	if step.Command == "learn" && len(step.Context) == 0 {
+		return fmt.Errorf("%q step must have at least one context for %q in %q", step.Command, types.Name, stateName)
+	}
+
 	if step.Command == "evaluate" {
 		if len(step.Context) == 0 {
 			return fmt.Errorf("%q step %q must have at least one context", step.Command, step.Action)
@@ -239,6 +306,34 @@ func (s *Settings) validateProjects() error {
 		if project.GitPath == "" {
 			return fmt.Errorf("project %q git_path is required. Try using: '/project/.git'", project.Identifier)
 		}
+		if project.MergeStrategy != "" && !slices.Contains(MergeStrategies, project.MergeStrategy) {
+			return fmt.Errorf("project %q merge_strategy %q is not valid, use one of: %s", project.Identifier, project.MergeStrategy, strings.Join(MergeStrategies, ", "))
+		}
+		if project.SquashMessage != "" && project.SquashMessage != SquashMessageIssue && project.SquashMessage != SquashMessageLLM {
+			return fmt.Errorf("project %q squash_message %q is not valid, use %s or %s", project.Identifier, project.SquashMessage, SquashMessageIssue, SquashMessageLLM)
+		}
+		if project.ConflictPolicy != "" && project.ConflictPolicy != ConflictPolicyAbort && project.ConflictPolicy != ConflictPolicyResolve {
+			return fmt.Errorf("project %q conflict_policy %q is not valid, use %s or %s", project.Identifier, project.ConflictPolicy, ConflictPolicyAbort, ConflictPolicyResolve)
+		}
+		if project.ConflictCheck != "" {
+			if _, err := project.Commands.Find(project.ConflictCheck); err != nil {
+				return fmt.Errorf("project %q conflict_check: %w", project.Identifier, err)
+			}
+		}
+		if project.BranchTemplate != "" {
+			if err := branchname.Check(project.BranchTemplate, project.Identifier, s.issueTypeNames()); err != nil {
+				return fmt.Errorf("project %q %w", project.Identifier, err)
+			}
+		}
+		if err := project.Diff.Validate(); err != nil {
+			return fmt.Errorf("project %q %w", project.Identifier, err)
+		}
+		if err := project.Remote.Validate(); err != nil {
+			return fmt.Errorf("project %q %w", project.Identifier, err)
+		}
+		if err := project.Runtime.Validate(); err != nil {
+			return fmt.Errorf("project %q %w", project.Identifier, err)
+		}
 		projCommands := make(map[string]bool)
 		for _, cmd := range project.Commands {
 			projCommands[cmd.Name] = true
@@ -248,6 +343,18 @@ func (s *Settings) validateProjects() error {
 			if len(cmd.Command) == 0 {
 				return fmt.Errorf("project %q command %q is missing command", cmd.Name, project.Identifier)
 			}
+			if cmd.Parser != "" && !slices.Contains(testresult.Parsers, cmd.Parser) {
+				return fmt.Errorf("project %q command %q parser %q is not supported, use one of: %s", project.Identifier, cmd.Name, cmd.Parser, strings.Join(testresult.Parsers, ", "))
+			}
+			if cmd.ReportFile != "" && cmd.Parser == "" {
+				return fmt.Errorf("project %q command %q report_file requires parser", project.Identifier, cmd.Name)
+			}
+			if err := cmd.OutputLimit.Validate(); err != nil {
+				return fmt.Errorf("project %q command %q %w", project.Identifier, cmd.Name, err)
+			}
+			if err := cmd.Runtime.Validate(); err != nil {
+				return fmt.Errorf("project %q command %q %w", project.Identifier, cmd.Name, err)
+			}
 		}
 		if len(projCommands) != len(project.Commands) {
 			return fmt.Errorf("project %q has duplicate commands", project.Identifier)
@@ -300,14 +407,6 @@ func (s *Settings) validateLlmModels() error {
 		}
 	}
 
-	// Define the set of commands allowed to be specified in the llm_models section
-	allowedLlmCommands := map[string]bool{
-		"evaluate":       true,
-		"summarize-task": true,
-		"ai":             true,
-		"create-issues":  true,
-	}
-
 	commandModelMap := make(map[string]string)
 	primaryModelExists := false
 
@@ -334,10 +433,10 @@ func (s *Settings) validateLlmModels() error {
 		// Validate that only specific commands are used if Commands list is defined
 		if len(model.Commands) > 0 {
 			for _, command := range model.Commands {
-				if _, ok := allowedLlmCommands[command]; !ok {
+				if _, ok := llmCommands[command]; !ok {
 					// Collect allowed command names for the error message
-					allowedKeys := make([]string, 0, len(allowedLlmCommands))
-					for k := range allowedLlmCommands {
+					allowedKeys := make([]string, 0, len(llmCommands))
+					for k := range llmCommands {
 						allowedKeys = append(allowedKeys, k)
 					}
 					return fmt.Errorf("llm model %q has invalid command %q in its commands list. Allowed commands are: %s", model.Name, command, strings.Join(allowedKeys, ", "))
@@ -420,7 +519,11 @@ func (s *Settings) validatePriorities(issueTypeNames map[IssueTypeName]bool, sta
 	return nil
 }
 
+// nolint: cyclop
 func (s *Settings) validateTriggers(issueTypeNames map[IssueTypeName]bool, stateNames map[StateName]bool) error {
+	if s.Workflow.TriggerMaxDepth < 0 {
+		return fmt.Errorf("trigger_max_depth can not be negative")
+	}
 	for _, trigger := range s.Workflow.Triggers {
 		if _, ok := issueTypeNames[trigger.IssueType]; !ok {
 			return fmt.Errorf("trigger type %s does not exist", trigger.IssueType)
@@ -441,6 +544,32 @@ func (s *Settings) validateTriggers(issueTypeNames map[IssueTypeName]bool, state
 				}
 			}
 
+			if triggerIf.AnySiblingStatus != "" {
+				if _, ok := stateNames[triggerIf.AnySiblingStatus]; !ok {
+					return fmt.Errorf("trigger any sibling status %s does not exist", triggerIf.AnySiblingStatus)
+				}
+			}
+
+			if triggerIf.AllChildrenStatus != "" {
+				if _, ok := stateNames[triggerIf.AllChildrenStatus]; !ok {
+					return fmt.Errorf("trigger all children status %s does not exist", triggerIf.AllChildrenStatus)
+				}
+			}
+
+			if triggerIf.ChildrenCount != nil && *triggerIf.ChildrenCount < 0 {
+				return fmt.Errorf("trigger children count %d can not be negative", *triggerIf.ChildrenCount)
+			}
+
+			if triggerIf.CustomField != nil && triggerIf.CustomField.Name == "" {
+				return fmt.Errorf("trigger custom_field name is required")
+			}
+
+			if triggerIf.TriggerTransition.IssueType != "" {
+				if _, ok := issueTypeNames[triggerIf.TriggerTransition.IssueType]; !ok {
+					return fmt.Errorf("trigger transition issue type %s does not exist", triggerIf.TriggerTransition.IssueType)
+				}
+			}
+
 			switch triggerIf.TriggerTransition.Who {
 			case TriggerTransitionWhoParent:
 			case TriggerTransitionWhoChildren:
@@ -453,6 +582,43 @@ func (s *Settings) validateTriggers(issueTypeNames map[IssueTypeName]bool, state
 	return nil
 }
 
+func (s *Settings) validateWatchdog(stateNames map[StateName]bool) error {
+	limitsExist := false
+	for _, state := range s.Workflow.States {
+		if state.MaxDuration != "" {
+			duration, err := time.ParseDuration(state.MaxDuration)
+			if err != nil {
+				return fmt.Errorf("%q state max_duration %q is not valid duration (e.g. 30m, 2h) err: %v", state.Name, state.MaxDuration, err)
+			}
+			if duration <= 0 {
+				return fmt.Errorf("%q state max_duration %q must be positive", state.Name, state.MaxDuration)
+			}
+		}
+		if state.MaxVisits < 0 {
+			return fmt.Errorf("%q state max_visits %d must be positive", state.Name, state.MaxVisits)
+		}
+		if state.HasLimits() {
+			limitsExist = true
+		}
+	}
+
+	if s.Workflow.EscalationState == "" {
+		if limitsExist {
+			return fmt.Errorf("workflow escalation_state is required when states have max_duration or max_visits")
+		}
+		return nil
+	}
+
+	if _, ok := stateNames[s.Workflow.EscalationState]; !ok {
+		return fmt.Errorf("workflow escalation_state %q is not a valid state", s.Workflow.EscalationState)
+	}
+	if s.Workflow.States.Get(s.Workflow.EscalationState).HasLimits() {
+		return fmt.Errorf("workflow escalation_state %q cannot have max_duration or max_visits", s.Workflow.EscalationState)
+	}
+
+	return nil
+}
+
 func (s *Settings) validateTransitions(stateNames map[StateName]bool) error {
 	// validate transitions existence
 	for _, transition := range s.Workflow.Transitions {
@@ -543,6 +709,9 @@ func (s *Settings) validateStepContexts() error {
 					case ContextFourComment:
 					case ContextFifeComment:
 					case ContextComments:
+					case ContextHumanComments:
+					case ContextAIComments:
+					case ContextLastHumanComment:
 					case ContextProject:
 					case ContextProjectWiki:
 					case ContextChildren:
@@ -553,10 +722,21 @@ func (s *Settings) validateStepContexts() error {
 					case ContextParents:
 					case ContextIssueTypes:
 					case ContextAffectedFiles:
+					case ContextBranchDiff:
+					case ContextChildrenDiff:
+					case ContextLastCommitDiff:
+					case ContextRepoMap:
+					case ContextAttachments:
+					case ContextParentAttachments:
+					case ContextPreviousAttempts:
+					case ContextLessons:
 					default:
 						return fmt.Errorf("issue %q state %q job (%d) does not have valid context: %q", issueTypeName, stateName, k, context)
 					}
 				}
+				if err := step.Diff.Validate(); err != nil {
+					return fmt.Errorf("issue %q state %q job (%d) %w", issueTypeName, stateName, k, err)
+				}
 			}
 		}
 	}
@@ -612,6 +792,10 @@ func (s *Settings) Validate() error {
 		return err
 	}
 
+	if err := s.validateWatchdog(stateNames); err != nil {
+		return err
+	}
+
 	if err := s.validatePriorities(issueTypeNames, stateNames); err != nil {
 		return err
 	}
@@ -628,6 +812,16 @@ func (s *Settings) Validate() error {
 		return err
 	}
 
+	if err := s.Shell.Validate(); err != nil {
+		return err
+	}
+	if err := s.Sandbox.Validate(); err != nil {
+		return err
+	}
+	if err := s.Redaction.Validate(); err != nil {
+		return err
+	}
+
 	if err := s.validateProjects(); err != nil {
 		return err
 	}
@@ -0,0 +1,40 @@
+package settings
+
+import "fmt"
+
+const (
+	// DefaultShell is used for commands that need shell features (pipes, globs, redirects).
+	DefaultShell = "sh"
+)
+
+// Shell configures shell that runs string commands (bash step, git step, etc.).
+// Git, project commands and aider are executed directly (without shell).
+type Shell struct {
+	Name        string `yaml:"name"`        // bash, sh or zsh. Default sh.
+	Interactive bool   `yaml:"interactive"` // loads user rc files (aliases, PATH changes). Slow, use only if you need it.
+}
+
+// GetName returns configured shell name or default.
+func (s Shell) GetName() string {
+	if s.Name == "" {
+		return DefaultShell
+	}
+	return s.Name
+}
+
+// Args returns shell arguments that go before command string.
+func (s Shell) Args() []string {
+	if s.Interactive {
+		return []string{"-ic"}
+	}
+	return []string{"-c"}
+}
+
+func (s Shell) Validate() error {
+	switch s.GetName() {
+	case "bash", "sh", "zsh":
+		return nil
+	default:
+		return fmt.Errorf("shell name %q is not valid, use one of: bash, sh, zsh", s.Name)
+	}
+}
@@ -0,0 +1,56 @@
+package settings_test
+
+import (
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShell(t *testing.T) {
+	tests := []struct {
+		name         string
+		shell        settings.Shell
+		expectedName string
+		expectedArgs []string
+		expectErr    bool
+	}{
+		{
+			name:         "default",
+			shell:        settings.Shell{},
+			expectedName: "sh",
+			expectedArgs: []string{"-c"},
+		},
+		{
+			name:         "interactive zsh",
+			shell:        settings.Shell{Name: "zsh", Interactive: true},
+			expectedName: "zsh",
+			expectedArgs: []string{"-ic"},
+		},
+		{
+			name:         "bash",
+			shell:        settings.Shell{Name: "bash"},
+			expectedName: "bash",
+			expectedArgs: []string{"-c"},
+		},
+		{
+			name:         "not supported",
+			shell:        settings.Shell{Name: "fish"},
+			expectedName: "fish",
+			expectedArgs: []string{"-c"},
+			expectErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedName, tt.shell.GetName())
+			assert.Equal(t, tt.expectedArgs, tt.shell.Args())
+			if tt.expectErr {
+				assert.Error(t, tt.shell.Validate())
+			} else {
+				assert.NoError(t, tt.shell.Validate())
+			}
+		})
+	}
+}
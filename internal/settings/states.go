@@ -1,5 +1,7 @@
 package settings
 
+import "time"
+
 // StateName : Initial, Backlog, In Progress, etc
 type StateName string
 
@@ -34,6 +36,8 @@ type State struct {
 	IsDefault   bool      `yaml:"is_default"`
 	IsFirst     bool      `yaml:"is_first"`
 	IsClosed    bool      `yaml:"is_closed"`
+	MaxDuration string    `yaml:"max_duration,omitempty"` // how long issue can stay in this state (e.g. 2h). Watched by watchdog.
+	MaxVisits   int       `yaml:"max_visits,omitempty"`   // how many times issue can enter this state. Watched by watchdog.
 }
 
 func (s *States) Get(name StateName) State {
@@ -48,3 +52,20 @@ func (a *UseAI) Yes(name IssueTypeName) bool {
 	}
 	return false
 }
+
+// GetMaxDuration returns parsed max_duration. Zero means no limit.
+func (s State) GetMaxDuration() time.Duration {
+	if s.MaxDuration == "" {
+		return 0
+	}
+	duration, err := time.ParseDuration(s.MaxDuration)
+	if err != nil {
+		return 0
+	}
+	return duration
+}
+
+// HasLimits tells if watchdog should keep an eye on issues in this state.
+func (s State) HasLimits() bool {
+	return s.GetMaxDuration() > 0 || s.MaxVisits > 0
+}
@@ -0,0 +1,50 @@
+package settings_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrejsstepanovs/andai/internal/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestState_GetMaxDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		state    settings.State
+		expected time.Duration
+	}{
+		{
+			name:     "empty",
+			state:    settings.State{},
+			expected: 0,
+		},
+		{
+			name:     "hours",
+			state:    settings.State{MaxDuration: "2h"},
+			expected: 2 * time.Hour,
+		},
+		{
+			name:     "mixed",
+			state:    settings.State{MaxDuration: "1h30m"},
+			expected: 90 * time.Minute,
+		},
+		{
+			name:     "invalid",
+			state:    settings.State{MaxDuration: "two hours"},
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.state.GetMaxDuration())
+		})
+	}
+}
+
+func TestState_HasLimits(t *testing.T) {
+	assert.False(t, settings.State{}.HasLimits())
+	assert.True(t, settings.State{MaxVisits: 3}.HasLimits())
+	assert.True(t, settings.State{MaxDuration: "30m"}.HasLimits())
+}
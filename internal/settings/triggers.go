@@ -1,10 +1,17 @@
 package settings
 
+import (
+	"fmt"
+	"strings"
+)
+
 const (
 	// TriggerTransitionWhoParent is used to indicate that the transition should be applied to the parent issue.
 	TriggerTransitionWhoParent = "parent"
 	// TriggerTransitionWhoChildren is used to indicate that the transition should be applied to children issues.
 	TriggerTransitionWhoChildren = "children"
+	// DefaultTriggerMaxDepth is how many times trigger caused transitions can cascade into other triggers.
+	DefaultTriggerMaxDepth = 5
 )
 
 type Triggers []Trigger
@@ -15,14 +22,25 @@ type Trigger struct {
 }
 
 type TriggerIf struct {
-	MovedTo           StateName         `yaml:"moved_to"`
-	AllSiblingsStatus StateName         `yaml:"all_siblings_status"`
-	TriggerTransition TriggerTransition `yaml:"transition"`
+	MovedTo           StateName           `yaml:"moved_to"`
+	AllSiblingsStatus StateName           `yaml:"all_siblings_status"`
+	AnySiblingStatus  StateName           `yaml:"any_sibling_status"`
+	AllChildrenStatus StateName           `yaml:"all_children_status"`
+	ChildrenCount     *int                `yaml:"children_count"`
+	CustomField       *TriggerCustomField `yaml:"custom_field"`
+	TriggerTransition TriggerTransition   `yaml:"transition"`
+}
+
+// TriggerCustomField matches moved issue custom field value.
+type TriggerCustomField struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
 }
 
 type TriggerTransition struct {
-	Who string    `yaml:"who"`
-	To  StateName `yaml:"to"`
+	Who       string        `yaml:"who"`
+	To        StateName     `yaml:"to"`
+	IssueType IssueTypeName `yaml:"issue_type"` // optional. Only issues of this type will be moved.
 }
 
 func (t Triggers) GetTriggers(issueType IssueTypeName) []Trigger {
@@ -44,6 +62,17 @@ func (t Trigger) GetTriggerIf(movedTo StateName) *TriggerIf {
 	return nil
 }
 
+// GetTriggerIfs returns all conditions that are defined for given state. Order is preserved.
+func (t Trigger) GetTriggerIfs(movedTo StateName) []TriggerIf {
+	triggerIfs := make([]TriggerIf, 0)
+	for _, triggerIf := range t.TriggerIf {
+		if triggerIf.MovedTo == movedTo {
+			triggerIfs = append(triggerIfs, triggerIf)
+		}
+	}
+	return triggerIfs
+}
+
 func (t TriggerIf) AllSiblingsCheck(siblingStatuses []StateName) bool {
 	if t.AllSiblingsStatus == "" {
 		return true
@@ -58,3 +87,75 @@ func (t TriggerIf) AllSiblingsCheck(siblingStatuses []StateName) bool {
 	}
 	return true
 }
+
+func (t TriggerIf) AnySiblingCheck(siblingStatuses []StateName) bool {
+	if t.AnySiblingStatus == "" {
+		return true
+	}
+	for _, status := range siblingStatuses {
+		if status == t.AnySiblingStatus {
+			return true
+		}
+	}
+	return false
+}
+
+func (t TriggerIf) AllChildrenCheck(childrenStatuses []StateName) bool {
+	if t.AllChildrenStatus == "" {
+		return true
+	}
+	for _, status := range childrenStatuses {
+		if status != t.AllChildrenStatus {
+			return false
+		}
+	}
+	return true
+}
+
+func (t TriggerIf) ChildrenCountCheck(count int) bool {
+	if t.ChildrenCount == nil {
+		return true
+	}
+	return *t.ChildrenCount == count
+}
+
+// CustomFieldCheck compares custom field value. Values are keyed by custom field name.
+func (t TriggerIf) CustomFieldCheck(values map[string]string) bool {
+	if t.CustomField == nil {
+		return true
+	}
+	return strings.TrimSpace(values[t.CustomField.Name]) == t.CustomField.Value
+}
+
+// TargetIssueTypeCheck tells if issue (that is about to be moved) matches transition issue type filter.
+func (t TriggerIf) TargetIssueTypeCheck(issueType IssueTypeName) bool {
+	if t.TriggerTransition.IssueType == "" {
+		return true
+	}
+	return t.TriggerTransition.IssueType == issueType
+}
+
+// String describes the rule. Used in audit comments.
+func (t TriggerIf) String() string {
+	parts := []string{fmt.Sprintf("moved_to: %s", t.MovedTo)}
+	if t.AllSiblingsStatus != "" {
+		parts = append(parts, fmt.Sprintf("all_siblings_status: %s", t.AllSiblingsStatus))
+	}
+	if t.AnySiblingStatus != "" {
+		parts = append(parts, fmt.Sprintf("any_sibling_status: %s", t.AnySiblingStatus))
+	}
+	if t.AllChildrenStatus != "" {
+		parts = append(parts, fmt.Sprintf("all_children_status: %s", t.AllChildrenStatus))
+	}
+	if t.ChildrenCount != nil {
+		parts = append(parts, fmt.Sprintf("children_count: %d", *t.ChildrenCount))
+	}
+	if t.CustomField != nil {
+		parts = append(parts, fmt.Sprintf("custom_field: %s = %q", t.CustomField.Name, t.CustomField.Value))
+	}
+	target := fmt.Sprintf("%s -> %s", t.TriggerTransition.Who, t.TriggerTransition.To)
+	if t.TriggerTransition.IssueType != "" {
+		target = fmt.Sprintf("%s (%s) -> %s", t.TriggerTransition.Who, t.TriggerTransition.IssueType, t.TriggerTransition.To)
+	}
+	return fmt.Sprintf("if %s then %s", strings.Join(parts, ", "), target)
+}
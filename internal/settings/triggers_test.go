@@ -173,3 +173,135 @@ func TestTriggerIf_AllSiblingsCheck(t *testing.T) {
 		})
 	}
 }
+
+func TestTrigger_GetTriggerIfs(t *testing.T) {
+	trigger := settings.Trigger{
+		TriggerIf: []settings.TriggerIf{
+			{MovedTo: "Done", TriggerTransition: settings.TriggerTransition{Who: "parent", To: "Review"}},
+			{MovedTo: "QA", TriggerTransition: settings.TriggerTransition{Who: "parent", To: "Testing"}},
+			{MovedTo: "Done", TriggerTransition: settings.TriggerTransition{Who: "children", To: "Done"}},
+		},
+	}
+
+	triggerIfs := trigger.GetTriggerIfs("Done")
+	assert.Len(t, triggerIfs, 2)
+	assert.Equal(t, "parent", triggerIfs[0].TriggerTransition.Who)
+	assert.Equal(t, "children", triggerIfs[1].TriggerTransition.Who)
+
+	assert.Empty(t, trigger.GetTriggerIfs("Backlog"))
+}
+
+func TestTriggerIf_AnySiblingCheck(t *testing.T) {
+	tests := []struct {
+		name            string
+		triggerIf       settings.TriggerIf
+		siblingStatuses []settings.StateName
+		expected        bool
+	}{
+		{
+			name:            "empty any sibling status",
+			triggerIf:       settings.TriggerIf{},
+			siblingStatuses: []settings.StateName{"Done"},
+			expected:        true,
+		},
+		{
+			name:            "one sibling matches",
+			triggerIf:       settings.TriggerIf{AnySiblingStatus: "Blocked"},
+			siblingStatuses: []settings.StateName{"Done", "Blocked", "Open"},
+			expected:        true,
+		},
+		{
+			name:            "no sibling matches",
+			triggerIf:       settings.TriggerIf{AnySiblingStatus: "Blocked"},
+			siblingStatuses: []settings.StateName{"Done", "Open"},
+			expected:        false,
+		},
+		{
+			name:            "no siblings",
+			triggerIf:       settings.TriggerIf{AnySiblingStatus: "Blocked"},
+			siblingStatuses: []settings.StateName{},
+			expected:        false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.triggerIf.AnySiblingCheck(tt.siblingStatuses))
+		})
+	}
+}
+
+func TestTriggerIf_AllChildrenCheck(t *testing.T) {
+	tests := []struct {
+		name             string
+		triggerIf        settings.TriggerIf
+		childrenStatuses []settings.StateName
+		expected         bool
+	}{
+		{
+			name:             "empty all children status",
+			triggerIf:        settings.TriggerIf{},
+			childrenStatuses: []settings.StateName{"Open"},
+			expected:         true,
+		},
+		{
+			name:             "all children match",
+			triggerIf:        settings.TriggerIf{AllChildrenStatus: "Done"},
+			childrenStatuses: []settings.StateName{"Done", "Done"},
+			expected:         true,
+		},
+		{
+			name:             "one child does not match",
+			triggerIf:        settings.TriggerIf{AllChildrenStatus: "Done"},
+			childrenStatuses: []settings.StateName{"Done", "Open"},
+			expected:         false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.triggerIf.AllChildrenCheck(tt.childrenStatuses))
+		})
+	}
+}
+
+func TestTriggerIf_ChildrenCountCheck(t *testing.T) {
+	zero := 0
+	two := 2
+
+	assert.True(t, settings.TriggerIf{}.ChildrenCountCheck(3))
+	assert.True(t, settings.TriggerIf{ChildrenCount: &zero}.ChildrenCountCheck(0))
+	assert.False(t, settings.TriggerIf{ChildrenCount: &zero}.ChildrenCountCheck(1))
+	assert.True(t, settings.TriggerIf{ChildrenCount: &two}.ChildrenCountCheck(2))
+	assert.False(t, settings.TriggerIf{ChildrenCount: &two}.ChildrenCountCheck(3))
+}
+
+func TestTriggerIf_CustomFieldCheck(t *testing.T) {
+	triggerIf := settings.TriggerIf{CustomField: &settings.TriggerCustomField{Name: "Branch", Value: "main"}}
+
+	assert.True(t, settings.TriggerIf{}.CustomFieldCheck(nil))
+	assert.True(t, triggerIf.CustomFieldCheck(map[string]string{"Branch": "main"}))
+	assert.True(t, triggerIf.CustomFieldCheck(map[string]string{"Branch": " main\n"}))
+	assert.False(t, triggerIf.CustomFieldCheck(map[string]string{"Branch": "feature"}))
+	assert.False(t, triggerIf.CustomFieldCheck(map[string]string{}))
+}
+
+func TestTriggerIf_TargetIssueTypeCheck(t *testing.T) {
+	triggerIf := settings.TriggerIf{TriggerTransition: settings.TriggerTransition{Who: "children", To: "Done", IssueType: "Task"}}
+
+	assert.True(t, settings.TriggerIf{}.TargetIssueTypeCheck("Story"))
+	assert.True(t, triggerIf.TargetIssueTypeCheck("Task"))
+	assert.False(t, triggerIf.TargetIssueTypeCheck("Story"))
+}
+
+func TestTriggerIf_String(t *testing.T) {
+	count := 1
+	triggerIf := settings.TriggerIf{
+		MovedTo:           "Done",
+		AllSiblingsStatus: "Done",
+		ChildrenCount:     &count,
+		TriggerTransition: settings.TriggerTransition{Who: "parent", To: "Review", IssueType: "Story"},
+	}
+
+	assert.Equal(t, "if moved_to: Done, all_siblings_status: Done, children_count: 1 then parent (Story) -> Review", triggerIf.String())
+}
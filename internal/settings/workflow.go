@@ -9,6 +9,9 @@ type Workflow struct {
 	Triggers    Triggers    `yaml:"triggers"`
 	LlmModels   LlmModels   `yaml:"llm_models"`
 	Aider       Aider       `yaml:"aider"`
+
+	TriggerMaxDepth int       `yaml:"trigger_max_depth"`
+	EscalationState StateName `yaml:"escalation_state"`
 }
 
 // UnmarshalYAML implements custom unmarshalling for the Workflow struct.
@@ -21,6 +24,9 @@ func (w *Workflow) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		Triggers    Triggers                    `yaml:"triggers"`
 		LlmModels   LlmModels                   `yaml:"llm_models"`
 		Aider       Aider                       `yaml:"aider"`
+
+		TriggerMaxDepth int       `yaml:"trigger_max_depth"`
+		EscalationState StateName `yaml:"escalation_state"`
 	}
 
 	var raw rawWorkflow
@@ -33,6 +39,8 @@ func (w *Workflow) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	w.Triggers = raw.Triggers
 	w.LlmModels = raw.LlmModels
 	w.Aider = raw.Aider
+	w.TriggerMaxDepth = raw.TriggerMaxDepth
+	w.EscalationState = raw.EscalationState
 
 	// map States
 	cleanStates := make(map[StateName]State)
@@ -58,3 +66,11 @@ func (w *Workflow) UnmarshalYAML(unmarshal func(interface{}) error) error {
 
 	return nil
 }
+
+// GetTriggerMaxDepth returns how deep trigger transitions are allowed to cascade.
+func (w *Workflow) GetTriggerMaxDepth() int {
+	if w.TriggerMaxDepth > 0 {
+		return w.TriggerMaxDepth
+	}
+	return DefaultTriggerMaxDepth
+}
@@ -0,0 +1,117 @@
+package testresult
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// goTestEvent is a single line of `go test -json` output.
+type goTestEvent struct {
+	Action  string `json:"Action"`
+	Package string `json:"Package"`
+	Test    string `json:"Test"`
+	Output  string `json:"Output"`
+}
+
+func parseGoTestJSON(data []byte) (Report, error) {
+	report := Report{}
+	outputs := make(map[string]*strings.Builder)
+	failedTests := make(map[string]bool)
+	order := make([]string, 0)
+
+	key := func(e goTestEvent) string {
+		return e.Package + "\x00" + e.Test
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || line[0] != '{' {
+			continue // build errors and other noise are printed as plain text
+		}
+		var event goTestEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			continue
+		}
+
+		k := key(event)
+		switch event.Action {
+		case "output":
+			if outputs[k] == nil {
+				outputs[k] = &strings.Builder{}
+			}
+			outputs[k].WriteString(event.Output)
+		case "pass":
+			if event.Test != "" {
+				report.Passed++
+			}
+		case "skip":
+			if event.Test != "" {
+				report.Skipped++
+			}
+		case "fail":
+			if event.Test != "" {
+				report.Failed++
+			}
+			failedTests[k] = true
+			order = append(order, k)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Report{}, err
+	}
+
+	for _, k := range order {
+		parts := strings.SplitN(k, "\x00", 2)
+		pkg, test := parts[0], parts[1]
+		if test == "" && hasFailedChild(failedTests, pkg+"\x00") {
+			continue // package fail is already explained by its tests
+		}
+		if test != "" && hasFailedChild(failedTests, k+"/") {
+			continue // parent test fails because subtest failed
+		}
+
+		message := ""
+		if outputs[k] != nil {
+			message = cleanGoTestOutput(outputs[k].String())
+		}
+		file, line := findFileLine(message)
+		name := test
+		if name == "" {
+			name = pkg
+		}
+		report.Failures = append(report.Failures, Failure{Test: name, File: file, Line: line, Message: message})
+	}
+
+	return report, nil
+}
+
+// hasFailedChild tells if there is failed test under given key prefix (package or parent test).
+func hasFailedChild(failed map[string]bool, prefix string) bool {
+	for k := range failed {
+		if k != prefix && strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanGoTestOutput removes === RUN / --- FAIL markers, leaving only useful lines.
+func cleanGoTestOutput(output string) string {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" ||
+			strings.HasPrefix(trimmed, "=== ") ||
+			strings.HasPrefix(trimmed, "--- ") ||
+			trimmed == "FAIL" || trimmed == "PASS" ||
+			strings.HasPrefix(trimmed, "FAIL\t") || strings.HasPrefix(trimmed, "ok  \t") {
+			continue
+		}
+		lines = append(lines, trimmed)
+	}
+	return strings.Join(lines, "\n")
+}
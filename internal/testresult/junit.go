@@ -0,0 +1,71 @@
+package testresult
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+)
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr"`
+	Line      int           `xml:"line,attr"`
+	Failure   *junitFailure `xml:"failure"`
+	Error     *junitFailure `xml:"error"`
+	Skipped   *struct{}     `xml:"skipped"`
+}
+
+type junitTestSuite struct {
+	TestCases  []junitTestCase  `xml:"testcase"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+func parseJUnit(data []byte) (Report, error) {
+	data = bytes.TrimSpace(data)
+	// root can be <testsuites> or single <testsuite>. Both have same shape for our needs.
+	var root junitTestSuite
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return Report{}, err
+	}
+
+	report := Report{}
+	collectJUnit(root, &report)
+	return report, nil
+}
+
+func collectJUnit(suite junitTestSuite, report *Report) {
+	for _, tc := range suite.TestCases {
+		failure := tc.Failure
+		if failure == nil {
+			failure = tc.Error
+		}
+		switch {
+		case failure != nil:
+			report.Failed++
+			name := tc.Name
+			if tc.ClassName != "" {
+				name = tc.ClassName + "." + tc.Name
+			}
+			message := strings.TrimSpace(failure.Message + "\n" + failure.Text)
+			f := Failure{Test: name, File: tc.File, Line: tc.Line, Message: message}
+			if f.File == "" {
+				f.File, f.Line = findFileLine(message)
+			}
+			report.Failures = append(report.Failures, f)
+		case tc.Skipped != nil:
+			report.Skipped++
+		default:
+			report.Passed++
+		}
+	}
+	for _, child := range suite.TestSuites {
+		collectJUnit(child, report)
+	}
+}
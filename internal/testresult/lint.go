@@ -0,0 +1,105 @@
+package testresult
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// golangci-lint --out-format json
+type golangciReport struct {
+	Issues []struct {
+		FromLinter string `json:"FromLinter"`
+		Text       string `json:"Text"`
+		Pos        struct {
+			Filename string `json:"Filename"`
+			Line     int    `json:"Line"`
+		} `json:"Pos"`
+	} `json:"Issues"`
+}
+
+func parseGolangciLint(data []byte) (Report, error) {
+	var lint golangciReport
+	if err := json.Unmarshal(jsonObject(data), &lint); err != nil {
+		return Report{}, err
+	}
+
+	report := Report{Failed: len(lint.Issues)}
+	for _, issue := range lint.Issues {
+		report.Failures = append(report.Failures, Failure{
+			Test:    issue.FromLinter,
+			File:    issue.Pos.Filename,
+			Line:    issue.Pos.Line,
+			Message: issue.Text,
+		})
+	}
+	return report, nil
+}
+
+// sarifReport is a minimal subset of SARIF 2.1.0.
+type sarifReport struct {
+	Runs []struct {
+		Tool struct {
+			Driver struct {
+				Name string `json:"name"`
+			} `json:"driver"`
+		} `json:"tool"`
+		Results []struct {
+			RuleID  string `json:"ruleId"`
+			Level   string `json:"level"`
+			Message struct {
+				Text string `json:"text"`
+			} `json:"message"`
+			Locations []struct {
+				PhysicalLocation struct {
+					ArtifactLocation struct {
+						URI string `json:"uri"`
+					} `json:"artifactLocation"`
+					Region struct {
+						StartLine int `json:"startLine"`
+					} `json:"region"`
+				} `json:"physicalLocation"`
+			} `json:"locations"`
+		} `json:"results"`
+	} `json:"runs"`
+}
+
+func parseSARIF(data []byte) (Report, error) {
+	var sarif sarifReport
+	if err := json.Unmarshal(jsonObject(data), &sarif); err != nil {
+		return Report{}, err
+	}
+
+	report := Report{}
+	for _, run := range sarif.Runs {
+		for _, result := range run.Results {
+			if result.Level == "note" || result.Level == "none" {
+				report.Skipped++
+				continue
+			}
+			report.Failed++
+			failure := Failure{Test: result.RuleID, Message: result.Message.Text}
+			if run.Tool.Driver.Name != "" && result.RuleID != "" {
+				failure.Test = fmt.Sprintf("%s/%s", run.Tool.Driver.Name, result.RuleID)
+			}
+			if len(result.Locations) > 0 {
+				location := result.Locations[0].PhysicalLocation
+				failure.File = strings.TrimPrefix(location.ArtifactLocation.URI, "file://")
+				failure.Line = location.Region.StartLine
+			}
+			report.Failures = append(report.Failures, failure)
+		}
+	}
+	return report, nil
+}
+
+// jsonObject cuts away noise around JSON object (linters like to print warnings before it).
+func jsonObject(data []byte) []byte {
+	text := string(data)
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return data
+	}
+	return []byte(text[start : end+1])
+}
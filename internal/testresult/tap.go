@@ -0,0 +1,100 @@
+package testresult
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var tapResultRe = regexp.MustCompile(`^(not ok|ok)\b\s*\d*\s*(?:-\s*)?(.*)$`)
+
+// parseTAP understands Test Anything Protocol, including YAML diagnostics blocks (message, file/line or at).
+func parseTAP(data []byte) (Report, error) {
+	report := Report{}
+	var current *Failure
+	inYAML := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+
+		if inYAML {
+			if line == "..." {
+				inYAML = false
+				continue
+			}
+			if current != nil {
+				applyTAPDiagnostic(current, line)
+			}
+			continue
+		}
+		if line == "---" {
+			inYAML = true
+			continue
+		}
+		if strings.HasPrefix(line, "#") && current != nil {
+			current.Message = strings.TrimSpace(current.Message + "\n" + strings.TrimSpace(strings.TrimPrefix(line, "#")))
+			continue
+		}
+
+		match := tapResultRe.FindStringSubmatch(line)
+		if match == nil || strings.HasPrefix(raw, " ") {
+			continue // nested subtests are summarized by parent line
+		}
+
+		description := match[2]
+		directive := ""
+		if idx := strings.Index(description, "#"); idx >= 0 {
+			directive = strings.ToUpper(strings.TrimSpace(description[idx+1:]))
+			description = strings.TrimSpace(description[:idx])
+		}
+
+		switch {
+		case strings.HasPrefix(directive, "SKIP") || strings.HasPrefix(directive, "TODO"):
+			report.Skipped++
+			current = nil
+		case match[1] == "ok":
+			report.Passed++
+			current = nil
+		default:
+			report.Failed++
+			report.Failures = append(report.Failures, Failure{Test: description})
+			current = &report.Failures[len(report.Failures)-1]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Report{}, err
+	}
+
+	for n, failure := range report.Failures {
+		if failure.File == "" {
+			report.Failures[n].File, report.Failures[n].Line = findFileLine(failure.Message)
+		}
+	}
+	return report, nil
+}
+
+func applyTAPDiagnostic(failure *Failure, line string) {
+	key, value, found := strings.Cut(line, ":")
+	if !found {
+		failure.Message = strings.TrimSpace(failure.Message + "\n" + line)
+		return
+	}
+	value = strings.Trim(strings.TrimSpace(value), `"'`)
+	switch strings.TrimSpace(key) {
+	case "message":
+		failure.Message = strings.TrimSpace(value + "\n" + failure.Message)
+	case "file":
+		failure.File = value
+	case "line":
+		failure.Line, _ = strconv.Atoi(value)
+	case "at":
+		failure.File, failure.Line = findFileLine(value)
+	default:
+		failure.Message = strings.TrimSpace(failure.Message + "\n" + line)
+	}
+}
@@ -0,0 +1,160 @@
+// Package testresult parses test runner and linter reports into structured failures.
+package testresult
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	ParserGoTestJSON   = "go-test-json"
+	ParserJUnit        = "junit"
+	ParserTAP          = "tap"
+	ParserGolangciLint = "golangci-lint"
+	ParserSARIF        = "sarif"
+
+	// maxMessageLength keeps single failure message short. Full output is in run log.
+	maxMessageLength = 1000
+)
+
+// Parsers lists supported parser names.
+var Parsers = []string{ParserGoTestJSON, ParserJUnit, ParserTAP, ParserGolangciLint, ParserSARIF}
+
+// fileLineRe finds "path/to/file.ext:123" in free text.
+var fileLineRe = regexp.MustCompile(`([\w./\-]+\.\w+):(\d+)`)
+
+// Failure is a single failed test or linter finding.
+type Failure struct {
+	Test    string
+	File    string
+	Line    int
+	Message string
+}
+
+// Location returns file:line (or just file) if known.
+func (f Failure) Location() string {
+	if f.File == "" {
+		return ""
+	}
+	if f.Line > 0 {
+		return fmt.Sprintf("%s:%d", f.File, f.Line)
+	}
+	return f.File
+}
+
+// Report is parsed command output.
+type Report struct {
+	Parser   string
+	Passed   int
+	Failed   int
+	Skipped  int
+	Failures []Failure
+}
+
+// Parse parses report with given parser.
+func Parse(parser string, data []byte) (Report, error) {
+	var (
+		report Report
+		err    error
+	)
+	switch parser {
+	case ParserGoTestJSON:
+		report, err = parseGoTestJSON(data)
+	case ParserJUnit:
+		report, err = parseJUnit(data)
+	case ParserTAP:
+		report, err = parseTAP(data)
+	case ParserGolangciLint:
+		report, err = parseGolangciLint(data)
+	case ParserSARIF:
+		report, err = parseSARIF(data)
+	default:
+		return Report{}, fmt.Errorf("unknown parser %q, use one of: %s", parser, strings.Join(Parsers, ", "))
+	}
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to parse %s report err: %w", parser, err)
+	}
+	report.Parser = parser
+	for n := range report.Failures {
+		report.Failures[n].Message = shorten(strings.TrimSpace(report.Failures[n].Message))
+	}
+	return report, nil
+}
+
+// HasFailures tells if anything failed.
+func (r Report) HasFailures() bool {
+	return len(r.Failures) > 0
+}
+
+// Files returns unique files mentioned in failures. Sorted.
+func (r Report) Files() []string {
+	unique := make(map[string]bool)
+	for _, failure := range r.Failures {
+		if failure.File != "" {
+			unique[failure.File] = true
+		}
+	}
+	files := make([]string, 0, len(unique))
+	for f := range unique {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Summary renders compact markdown summary. Shows at most limit failures (0 means all).
+func (r Report) Summary(limit int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d passed, %d failed, %d skipped", r.Parser, r.Passed, r.Failed, r.Skipped)
+	if !r.HasFailures() {
+		return b.String()
+	}
+
+	b.WriteString("\n\nFailures:\n")
+	for n, failure := range r.Failures {
+		if limit > 0 && n >= limit {
+			fmt.Fprintf(&b, "- ... and %d more\n", len(r.Failures)-limit)
+			break
+		}
+		b.WriteString("- ")
+		if failure.Test != "" {
+			fmt.Fprintf(&b, "`%s`", failure.Test)
+		}
+		if location := failure.Location(); location != "" {
+			if failure.Test != "" {
+				b.WriteString(" ")
+			}
+			fmt.Fprintf(&b, "at `%s`", location)
+		}
+		if failure.Message != "" {
+			fmt.Fprintf(&b, "\n  ```\n  %s\n  ```", strings.ReplaceAll(failure.Message, "\n", "\n  "))
+		}
+		b.WriteString("\n")
+	}
+
+	if files := r.Files(); len(files) > 0 {
+		fmt.Fprintf(&b, "\nFiles: %s\n", strings.Join(files, ", "))
+	}
+	return b.String()
+}
+
+// findFileLine returns first file:line found in text.
+func findFileLine(text string) (string, int) {
+	match := fileLineRe.FindStringSubmatch(text)
+	if match == nil {
+		return "", 0
+	}
+	line, _ := strconv.Atoi(match[2])
+	return match[1], line
+}
+
+func shorten(text string) string {
+	runes := []rune(text)
+	if len(runes) <= maxMessageLength {
+		return text
+	}
+	return string(runes[:maxMessageLength]) + "\n..."
+}
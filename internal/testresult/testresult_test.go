@@ -0,0 +1,168 @@
+package testresult_test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/andrejsstepanovs/andai/internal/testresult"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_GoTestJSON(t *testing.T) {
+	data := `{"Action":"run","Package":"example.com/calc","Test":"TestAdd"}
+{"Action":"output","Package":"example.com/calc","Test":"TestAdd","Output":"=== RUN   TestAdd\n"}
+{"Action":"pass","Package":"example.com/calc","Test":"TestAdd"}
+{"Action":"run","Package":"example.com/calc","Test":"TestSub"}
+{"Action":"output","Package":"example.com/calc","Test":"TestSub/negative","Output":"    calc_test.go:21: expected -1, got 1\n"}
+{"Action":"output","Package":"example.com/calc","Test":"TestSub/negative","Output":"--- FAIL: TestSub/negative (0.00s)\n"}
+{"Action":"fail","Package":"example.com/calc","Test":"TestSub/negative"}
+{"Action":"fail","Package":"example.com/calc","Test":"TestSub"}
+{"Action":"skip","Package":"example.com/calc","Test":"TestDiv"}
+{"Action":"output","Package":"example.com/calc","Output":"FAIL\n"}
+{"Action":"fail","Package":"example.com/calc"}
+`
+	report, err := testresult.Parse(testresult.ParserGoTestJSON, []byte(data))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Passed)
+	assert.Equal(t, 2, report.Failed)
+	assert.Equal(t, 1, report.Skipped)
+	require.Len(t, report.Failures, 1)
+	assert.Equal(t, testresult.Failure{
+		Test:    "TestSub/negative",
+		File:    "calc_test.go",
+		Line:    21,
+		Message: "calc_test.go:21: expected -1, got 1",
+	}, report.Failures[0])
+}
+
+func TestParse_GoTestJSON_BuildFailure(t *testing.T) {
+	data := `{"Action":"output","Package":"example.com/calc","Output":"# example.com/calc\n"}
+{"Action":"output","Package":"example.com/calc","Output":"calc.go:5:2: undefined: foo\n"}
+{"Action":"fail","Package":"example.com/calc"}
+`
+	report, err := testresult.Parse(testresult.ParserGoTestJSON, []byte(data))
+	require.NoError(t, err)
+
+	require.Len(t, report.Failures, 1)
+	assert.Equal(t, "example.com/calc", report.Failures[0].Test)
+	assert.Equal(t, "calc.go", report.Failures[0].File)
+	assert.Equal(t, 5, report.Failures[0].Line)
+}
+
+func TestParse_JUnit(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="calc" tests="3">
+    <testcase classname="calc" name="add"/>
+    <testcase classname="calc" name="sub" file="src/calc.py" line="12">
+      <failure message="assert 1 == -1">Traceback</failure>
+    </testcase>
+    <testcase classname="calc" name="div"><skipped/></testcase>
+  </testsuite>
+  <testsuite name="io">
+    <testcase classname="io" name="read"><error message="boom at src/io.py:7"/></testcase>
+  </testsuite>
+</testsuites>`
+	report, err := testresult.Parse(testresult.ParserJUnit, []byte(data))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Passed)
+	assert.Equal(t, 2, report.Failed)
+	assert.Equal(t, 1, report.Skipped)
+	require.Len(t, report.Failures, 2)
+	assert.Equal(t, testresult.Failure{Test: "calc.sub", File: "src/calc.py", Line: 12, Message: "assert 1 == -1\nTraceback"}, report.Failures[0])
+	assert.Equal(t, "src/io.py", report.Failures[1].File)
+	assert.Equal(t, 7, report.Failures[1].Line)
+	assert.Equal(t, []string{"src/calc.py", "src/io.py"}, report.Files())
+}
+
+func TestParse_TAP(t *testing.T) {
+	data := `TAP version 13
+1..4
+ok 1 - adds numbers
+not ok 2 - subtracts numbers
+  ---
+  message: "expected -1"
+  at: test/calc.test.js:14:5
+  ...
+ok 3 - divides # SKIP not ready
+not ok 4 - multiplies
+# got 6 at lib/calc.js:3
+`
+	report, err := testresult.Parse(testresult.ParserTAP, []byte(data))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Passed)
+	assert.Equal(t, 2, report.Failed)
+	assert.Equal(t, 1, report.Skipped)
+	require.Len(t, report.Failures, 2)
+	assert.Equal(t, testresult.Failure{Test: "subtracts numbers", File: "test/calc.test.js", Line: 14, Message: "expected -1"}, report.Failures[0])
+	assert.Equal(t, testresult.Failure{Test: "multiplies", File: "lib/calc.js", Line: 3, Message: "got 6 at lib/calc.js:3"}, report.Failures[1])
+}
+
+func TestParse_LongMessage(t *testing.T) {
+	message := strings.Repeat("ä", 1500)
+	data := "1..1\nnot ok 1 - unicode\n# " + message + "\n"
+	report, err := testresult.Parse(testresult.ParserTAP, []byte(data))
+	require.NoError(t, err)
+
+	require.Len(t, report.Failures, 1)
+	got := report.Failures[0].Message
+	assert.True(t, utf8.ValidString(got))
+	assert.Equal(t, strings.Repeat("ä", 1000)+"\n...", got)
+}
+
+func TestParse_GolangciLint(t *testing.T) {
+	data := `level=warning msg="deprecated linter"
+{"Issues":[{"FromLinter":"errcheck","Text":"Error return value is not checked","Pos":{"Filename":"main.go","Line":10}}],"Report":{}}`
+	report, err := testresult.Parse(testresult.ParserGolangciLint, []byte(data))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, []testresult.Failure{{Test: "errcheck", File: "main.go", Line: 10, Message: "Error return value is not checked"}}, report.Failures)
+}
+
+func TestParse_SARIF(t *testing.T) {
+	data := `{"version":"2.1.0","runs":[{"tool":{"driver":{"name":"eslint"}},"results":[
+{"ruleId":"no-unused-vars","level":"error","message":{"text":"'x' is unused"},"locations":[{"physicalLocation":{"artifactLocation":{"uri":"file://src/a.js"},"region":{"startLine":4}}}]},
+{"ruleId":"style","level":"note","message":{"text":"nit"}}
+]}]}`
+	report, err := testresult.Parse(testresult.ParserSARIF, []byte(data))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, []testresult.Failure{{Test: "eslint/no-unused-vars", File: "src/a.js", Line: 4, Message: "'x' is unused"}}, report.Failures)
+}
+
+func TestParse_Errors(t *testing.T) {
+	_, err := testresult.Parse("unknown", []byte(""))
+	assert.Error(t, err)
+
+	_, err = testresult.Parse(testresult.ParserJUnit, []byte("not xml"))
+	assert.Error(t, err)
+
+	_, err = testresult.Parse(testresult.ParserSARIF, []byte("nope"))
+	assert.Error(t, err)
+}
+
+func TestReport_Summary(t *testing.T) {
+	report := testresult.Report{
+		Parser: testresult.ParserGoTestJSON,
+		Passed: 3,
+		Failed: 2,
+		Failures: []testresult.Failure{
+			{Test: "TestA", File: "a_test.go", Line: 5, Message: "boom"},
+			{Test: "TestB", File: "b_test.go"},
+		},
+	}
+
+	assert.Equal(t, "go-test-json: 3 passed, 2 failed, 0 skipped\n\nFailures:\n"+
+		"- `TestA` at `a_test.go:5`\n  ```\n  boom\n  ```\n"+
+		"- ... and 1 more\n\nFiles: a_test.go, b_test.go\n", report.Summary(1))
+
+	assert.Equal(t, "go-test-json: 1 passed, 0 failed, 0 skipped", testresult.Report{Parser: testresult.ParserGoTestJSON, Passed: 1}.Summary(10))
+}
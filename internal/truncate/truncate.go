@@ -0,0 +1,224 @@
+// Package truncate shrinks huge command output so it fits into prompt token budget.
+package truncate
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	ModeHead     = "head"
+	ModeTail     = "tail"
+	ModeHeadTail = "head-tail"
+	ModeErrors   = "errors"
+
+	// errorContextLines is how many lines around error line are kept in errors mode.
+	errorContextLines = 3
+)
+
+// Modes lists supported truncation modes.
+var Modes = []string{ModeHead, ModeTail, ModeHeadTail, ModeErrors}
+
+// errorLineRe matches lines that usually explain why command failed.
+var errorLineRe = regexp.MustCompile(`(?i)(\bFAIL\b|panic:|\berror\b|\berr:|exception|fatal|traceback)`)
+
+// EstimateTokens roughly estimates how many tokens text uses. Takes worst of char and word based estimation.
+func EstimateTokens(text string) int {
+	charEstimate := int(math.Ceil(float64(len(text)) / 4))
+
+	words := strings.Fields(text)
+	wordEstimate := int(math.Ceil(float64(len(words)) * 1.33))
+
+	return max(charEstimate, wordEstimate)
+}
+
+// Fits tells if text fits into token budget. Zero budget means no limit.
+func Fits(text string, maxTokens int) bool {
+	return maxTokens <= 0 || EstimateTokens(text) <= maxTokens
+}
+
+// Dedup collapses consecutive repeated lines into single line with repeat count.
+func Dedup(text string) string {
+	lines := strings.Split(text, "\n")
+	result := make([]string, 0, len(lines))
+	for n := 0; n < len(lines); {
+		repeat := 1
+		for n+repeat < len(lines) && lines[n+repeat] == lines[n] {
+			repeat++
+		}
+		if repeat > 1 && strings.TrimSpace(lines[n]) != "" {
+			result = append(result, fmt.Sprintf("%s [repeated %d times]", lines[n], repeat))
+		} else {
+			for r := 0; r < repeat; r++ {
+				result = append(result, lines[n])
+			}
+		}
+		n += repeat
+	}
+	return strings.Join(result, "\n")
+}
+
+// Truncate deduplicates text and, if still too big, cuts it down to maxTokens using given mode.
+// Omitted parts are replaced with "... N lines omitted ..." marker, line that alone does not fit is cut by characters.
+func Truncate(text string, maxTokens int, mode string) string {
+	if Fits(text, maxTokens) {
+		return text
+	}
+	text = Dedup(text)
+	if Fits(text, maxTokens) {
+		return text
+	}
+
+	lines := strings.Split(text, "\n")
+	switch mode {
+	case ModeHead:
+		return headTail(lines, maxTokens, 0)
+	case ModeTail:
+		return headTail(lines, 0, maxTokens)
+	case ModeErrors:
+		return errorsOnly(lines, maxTokens)
+	default:
+		return headTail(lines, maxTokens/2, maxTokens-maxTokens/2)
+	}
+}
+
+// headTail keeps lines from beginning and end of output, each side within its own token budget.
+// If side can not take even one whole line, that line is cut by characters, so single huge line is not lost.
+func headTail(lines []string, headTokens, tailTokens int) string {
+	head := takeLines(lines, headTokens, false)
+	tail := takeLines(lines[len(head):], tailTokens, true)
+
+	omitted := len(lines) - len(head) - len(tail)
+	if omitted <= 0 {
+		return strings.Join(lines, "\n")
+	}
+	middle := lines[len(head) : len(lines)-len(tail)]
+
+	result := make([]string, 0, len(head)+len(tail)+3)
+	result = append(result, head...)
+	switch {
+	case omitted == 1:
+		result = append(result, cutLine(middle[0], headTokens-linesTokens(head), tailTokens-linesTokens(tail)))
+	default:
+		if len(head) == 0 {
+			if part := takeRunes(middle[0], headTokens, false); part != "" {
+				result = append(result, part+" ...")
+			}
+		}
+		result = append(result, omittedMarker(omitted))
+		if len(tail) == 0 {
+			if part := takeRunes(middle[len(middle)-1], tailTokens, true); part != "" {
+				result = append(result, "... "+part)
+			}
+		}
+	}
+	result = append(result, tail...)
+	return strings.Join(result, "\n")
+}
+
+// cutLine keeps beginning and end of too long line within token budgets.
+func cutLine(line string, headTokens, tailTokens int) string {
+	head := takeRunes(line, headTokens, false)
+	tail := takeRunes(line[len(head):], tailTokens, true)
+	omitted := utf8.RuneCountInString(line) - utf8.RuneCountInString(head) - utf8.RuneCountInString(tail)
+	return head + fmt.Sprintf("... %d characters omitted ...", omitted) + tail
+}
+
+// takeRunes takes characters from start (or end if fromEnd) of line until token budget is used.
+func takeRunes(line string, maxTokens int, fromEnd bool) string {
+	runes := []rune(line)
+	part := func(count int) string {
+		if fromEnd {
+			return string(runes[len(runes)-count:])
+		}
+		return string(runes[:count])
+	}
+	// biggest count that fits, estimation grows with count
+	count := sort.Search(len(runes)+1, func(n int) bool {
+		return EstimateTokens(part(n))+1 > maxTokens
+	}) - 1
+	if count <= 0 {
+		return ""
+	}
+	return part(count)
+}
+
+func linesTokens(lines []string) int {
+	tokens := 0
+	for _, line := range lines {
+		tokens += EstimateTokens(line) + 1
+	}
+	return tokens
+}
+
+// takeLines takes lines from start (or end if fromEnd) until token budget is used.
+func takeLines(lines []string, maxTokens int, fromEnd bool) []string {
+	used := 0
+	count := 0
+	for count < len(lines) {
+		line := lines[count]
+		if fromEnd {
+			line = lines[len(lines)-1-count]
+		}
+		tokens := EstimateTokens(line) + 1 // +1 for new line
+		if used+tokens > maxTokens {
+			break
+		}
+		used += tokens
+		count++
+	}
+	if fromEnd {
+		return lines[len(lines)-count:]
+	}
+	return lines[:count]
+}
+
+// errorsOnly keeps lines near error markers. Falls back to mostly tail (most recent output) if nothing matches.
+func errorsOnly(lines []string, maxTokens int) string {
+	keep := make([]bool, len(lines))
+	found := false
+	for n, line := range lines {
+		if !errorLineRe.MatchString(line) {
+			continue
+		}
+		found = true
+		for k := max(0, n-errorContextLines); k <= min(len(lines)-1, n+errorContextLines); k++ {
+			keep[k] = true
+		}
+	}
+	if !found {
+		return headTail(lines, maxTokens/4, maxTokens-maxTokens/4)
+	}
+
+	result := make([]string, 0)
+	omitted := 0
+	for n, line := range lines {
+		if !keep[n] {
+			omitted++
+			continue
+		}
+		if omitted > 0 {
+			result = append(result, omittedMarker(omitted))
+			omitted = 0
+		}
+		result = append(result, line)
+	}
+	if omitted > 0 {
+		result = append(result, omittedMarker(omitted))
+	}
+
+	text := strings.Join(result, "\n")
+	if Fits(text, maxTokens) {
+		return text
+	}
+	// too many errors, keep first ones (usually root cause) and last ones (summary)
+	return headTail(result, maxTokens/2, maxTokens-maxTokens/2)
+}
+
+func omittedMarker(count int) string {
+	return fmt.Sprintf("... %d lines omitted ...", count)
+}
@@ -0,0 +1,128 @@
+package truncate_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/andrejsstepanovs/andai/internal/truncate"
+	"github.com/stretchr/testify/assert"
+)
+
+func numberedLines(count int) []string {
+	lines := make([]string, 0, count)
+	for n := 1; n <= count; n++ {
+		lines = append(lines, fmt.Sprintf("line %d", n))
+	}
+	return lines
+}
+
+func TestEstimateTokens(t *testing.T) {
+	assert.Equal(t, 0, truncate.EstimateTokens(""))
+	assert.Equal(t, 3, truncate.EstimateTokens("hello world"))
+	assert.Equal(t, 25, truncate.EstimateTokens(strings.Repeat("a", 100)))
+}
+
+func TestDedup(t *testing.T) {
+	text := "start\nretry\nretry\nretry\n\n\nend"
+	assert.Equal(t, "start\nretry [repeated 3 times]\n\n\nend", truncate.Dedup(text))
+	assert.Equal(t, "a\nb\na", truncate.Dedup("a\nb\na"))
+}
+
+func TestTruncate(t *testing.T) {
+	text := strings.Join(numberedLines(100), "\n")
+
+	tests := []struct {
+		name      string
+		text      string
+		maxTokens int
+		mode      string
+		expected  string
+	}{
+		{
+			name:      "no limit",
+			text:      text,
+			maxTokens: 0,
+			mode:      truncate.ModeHead,
+			expected:  text,
+		},
+		{
+			name:      "fits",
+			text:      "short",
+			maxTokens: 10,
+			mode:      truncate.ModeHead,
+			expected:  "short",
+		},
+		{
+			name:      "dedup is enough",
+			text:      strings.Repeat("same line\n", 50) + "done",
+			maxTokens: 20,
+			mode:      truncate.ModeHead,
+			expected:  "same line [repeated 50 times]\ndone",
+		},
+		{
+			name:      "head",
+			text:      text,
+			maxTokens: 16,
+			mode:      truncate.ModeHead,
+			expected:  "line 1\nline 2\nline 3\nline 4\n... 96 lines omitted ...",
+		},
+		{
+			name:      "tail",
+			text:      text,
+			maxTokens: 16,
+			mode:      truncate.ModeTail,
+			expected:  "... 96 lines omitted ...\nline 97\nline 98\nline 99\nline 100",
+		},
+		{
+			name:      "head-tail",
+			text:      text,
+			maxTokens: 16,
+			mode:      truncate.ModeHeadTail,
+			expected:  "line 1\nline 2\n... 96 lines omitted ...\nline 99\nline 100",
+		},
+		{
+			name:      "default is head-tail",
+			text:      text,
+			maxTokens: 16,
+			mode:      "",
+			expected:  "line 1\nline 2\n... 96 lines omitted ...\nline 99\nline 100",
+		},
+		{
+			name:      "single line over budget",
+			text:      `{"a":"` + strings.Repeat("ž", 200) + `","z":1}`,
+			maxTokens: 8,
+			mode:      truncate.ModeHeadTail,
+			expected:  `{"a":"žžž... 195 characters omitted ...žž","z":1}`,
+		},
+		{
+			name:      "head of long line",
+			text:      strings.Repeat("a", 400) + "\n" + strings.Repeat("b", 400),
+			maxTokens: 4,
+			mode:      truncate.ModeHead,
+			expected:  "aaaaaaaaaaaa ...\n... 2 lines omitted ...",
+		},
+		{
+			name: "errors",
+			text: strings.Join(append(append(numberedLines(20),
+				"--- FAIL: TestSum", "    sum_test.go:10: wrong"), numberedLines(20)...), "\n"),
+			maxTokens: 60,
+			mode:      truncate.ModeErrors,
+			expected: "... 17 lines omitted ...\nline 18\nline 19\nline 20\n--- FAIL: TestSum\n    sum_test.go:10: wrong\n" +
+				"line 1\nline 2\n... 18 lines omitted ...",
+		},
+		{
+			name:      "errors without matches keeps mostly tail",
+			text:      text,
+			maxTokens: 16,
+			mode:      truncate.ModeErrors,
+			expected:  "line 1\n... 96 lines omitted ...\nline 98\nline 99\nline 100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, truncate.Truncate(tt.text, tt.maxTokens, tt.mode))
+		})
+	}
+}